@@ -1,9 +1,11 @@
 package estimator
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/big"
+	"math/rand"
 
 	"github.com/tuneinsight/ckks-noise-estimator"
 	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
@@ -24,18 +26,64 @@ type Evaluator struct {
 	Mod1Parameters mod1.Parameters
 
 	EphemeralSecret []*bignum.Complex
+
+	// EvkN1ToN2 and EvkN2ToN1 switch between the residual
+	// and the bootstrapping rings if their degree differ.
+	EvkN1ToN2 *estimator.EvaluationKey
+	EvkN2ToN1 *estimator.EvaluationKey
+
+	// EvkRealToCmplx and EvkCmplxToReal switch between the residual
+	// ring.ConjugateInvariant ring and the bootstrapping ring.Standard ring.
+	EvkRealToCmplx *estimator.EvaluationKey
+	EvkCmplxToReal *estimator.EvaluationKey
+
+	// EvkDenseToSparse and EvkSparseToDense switch between the
+	// bootstrapping secret and the ephemeral secret around ModUp.
+	EvkDenseToSparse *estimator.EvaluationKey
+	EvkSparseToDense *estimator.EvaluationKey
 }
 
 func NewEvaluator(btpParams bootstrapping.Parameters) Evaluator {
+	return newEvaluator(btpParams,
+		estimator.NewEstimator(btpParams.ResidualParameters),
+		estimator.NewEstimator(btpParams.BootstrappingParameters),
+		nil)
+}
+
+// NewEvaluatorFromSeed is as NewEvaluator, but samples the secrets of the estimators, including
+// the ephemeral secret, from sources seeded with seeds derived from seed, so that the evaluators
+// with the same parameters and seed have the same secrets (see estimator.NewEstimatorFromSeed).
+func NewEvaluatorFromSeed(btpParams bootstrapping.Parameters, seed int64) Evaluator {
+	return newEvaluator(btpParams,
+		estimator.NewEstimatorFromSeed(btpParams.ResidualParameters, seed),
+		estimator.NewEstimatorFromSeed(btpParams.BootstrappingParameters, estimator.TrialSeed(seed, 0)),
+		rand.New(rand.NewSource(estimator.TrialSeed(seed, 1))))
+}
+
+// newEvaluator returns a new evaluator with the given estimators, whose ephemeral
+// secret is sampled from r, or from the time if r is nil.
+func newEvaluator(btpParams bootstrapping.Parameters, estN1, estN2 estimator.Estimator, r *rand.Rand) Evaluator {
 
 	eval := Evaluator{
 		Parameters:              btpParams,
-		ResidualParameters:      estimator.NewEstimator(btpParams.ResidualParameters),
-		BootstrappingParameters: estimator.NewEstimator(btpParams.BootstrappingParameters),
+		ResidualParameters:      estN1,
+		BootstrappingParameters: estN2,
 	}
 
-	if btpParams.EphemeralSecretWeight != 0 {
-		eval.EphemeralSecret = eval.BootstrappingParameters.SampleSecretKey(btpParams.EphemeralSecretWeight)
+	// If both rings have the same degree, the bootstrapping secret is the
+	// residual secret extended to the bootstrapping modulus.
+	if btpParams.ResidualParameters.N() == btpParams.BootstrappingParameters.N() {
+		eval.BootstrappingParameters.Sk = eval.ResidualParameters.Sk
+		eval.BootstrappingParameters.SecretSeed = nil
+	}
+
+	eval.BootstrappingParameters.Rand = r
+	eval.genEvaluationKeys(btpParams)
+	eval.BootstrappingParameters.Rand = nil
+
+	// The switch from ring.Standard to ring.ConjugateInvariant multiplies the scale by 2
+	if eval.ResidualParameters.IsConjugateInvariant() {
+		btpParams.SlotsToCoeffsParameters.Scaling = new(big.Float).SetFloat64(0.5)
 	}
 
 	eval.initialize(btpParams)
@@ -43,6 +91,83 @@ func NewEvaluator(btpParams bootstrapping.Parameters) Evaluator {
 	return eval
 }
 
+// ShallowCopy returns a copy of the evaluator whose estimators have their own
+// Encoder buffers, so that the copy and the original can be used concurrently.
+func (eval Evaluator) ShallowCopy() Evaluator {
+	eval.ResidualParameters = eval.ResidualParameters.ShallowCopy()
+	eval.BootstrappingParameters = eval.BootstrappingParameters.ShallowCopy()
+	return eval
+}
+
+// countBootstrap counts a bootstrapping of an element at the given level with the Counter of the residual
+// estimator, if not nil, and returns a copy of the evaluator whose estimators do not count its operations.
+func (eval Evaluator) countBootstrap(level int) Evaluator {
+
+	if c := eval.ResidualParameters.Counter; c != nil {
+		c.Count(estimator.OpBootstrap, level)
+	}
+
+	eval.ResidualParameters.Counter = nil
+	eval.BootstrappingParameters.Counter = nil
+
+	return eval
+}
+
+// WithContext returns a copy of the evaluator whose estimators stop at their
+// next checkpoint if ctx is cancelled (see estimator.Estimator.WithContext).
+func (eval Evaluator) WithContext(ctx context.Context) Evaluator {
+	eval.ResidualParameters = eval.ResidualParameters.WithContext(ctx)
+	eval.BootstrappingParameters = eval.BootstrappingParameters.WithContext(ctx)
+	return eval
+}
+
+// genEvaluationKeys instantiates the evaluation keys of the bootstrapping
+// with the levels, auxiliary primes and secrets used by Lattigo.
+func (eval *Evaluator) genEvaluationKeys(btpParams bootstrapping.Parameters) {
+
+	estN1 := eval.ResidualParameters
+	estN2 := eval.BootstrappingParameters
+
+	if estN1.IsConjugateInvariant() {
+
+		// The N1 real slots of skN1 are the N2/2 slots of its unfolding in the ring of degree N2.
+		evkRealToCmplx := estN2.NewEvaluationKey(estN1.Sk[0], estN2.MaxLevel(), estN2.LevelP, estN2.Sigma)
+		evkCmplxToReal := estN2.NewEvaluationKey(estN2.Sk[0], estN2.MaxLevel(), estN2.LevelP, estN2.Sigma)
+
+		eval.EvkRealToCmplx = &evkRealToCmplx
+		eval.EvkCmplxToReal = &evkCmplxToReal
+
+	} else if estN1.N() != estN2.N() {
+
+		// skN1 embedded in the ring of degree N2
+		mask := estN1.MaxSlots() - 1
+		skN1 := make([]*bignum.Complex, estN2.MaxSlots())
+		for i := range skN1 {
+			skN1[i] = estN1.Sk[0][i&mask]
+		}
+
+		evkN1ToN2 := estN2.NewEvaluationKey(skN1, estN2.MaxLevel(), estN2.LevelP, estN2.Sigma)
+		evkN2ToN1 := estN2.NewEvaluationKey(estN2.Sk[0], estN2.MaxLevel(), estN2.LevelP, estN2.Sigma)
+
+		eval.EvkN1ToN2 = &evkN1ToN2
+		eval.EvkN2ToN1 = &evkN2ToN1
+	}
+
+	if btpParams.EphemeralSecretWeight != 0 {
+
+		eval.EphemeralSecret = estN2.SampleSecretKey(btpParams.EphemeralSecretWeight)
+
+		// Generated with the parameters (Q[:1], P[:1]) and their default error distribution.
+		evkDenseToSparse := estN2.NewEvaluationKey(estN2.Sk[0], 0, 0, rlwe.DefaultXe.Sigma)
+
+		// Generated with the bootstrapping parameters.
+		evkSparseToDense := estN2.NewEvaluationKey(eval.EphemeralSecret, estN2.MaxLevel(), estN2.LevelP, estN2.Sigma)
+
+		eval.EvkDenseToSparse = &evkDenseToSparse
+		eval.EvkSparseToDense = &evkSparseToDense
+	}
+}
+
 func (eval *Evaluator) initialize(btpParams bootstrapping.Parameters) (err error) {
 
 	params := btpParams.BootstrappingParameters
@@ -106,22 +231,92 @@ func checkMessageRatio(el *estimator.Element, msgRatio float64, r *ring.Ring) bo
 	return currentMessageRatio.Cmp(rlwe.NewScale(r.SubRings[level].Modulus).Mul(rlwe.NewScale(msgRatio))) > -1
 }
 
+// Bootstrap switches the element from the residual ring to the bootstrapping ring,
+// bootstraps it and switches it back to the residual ring.
 func (eval Evaluator) Bootstrap(elIn *estimator.Element) (elOut *estimator.Element, err error) {
 
+	if !eval.ResidualParameters.IsConjugateInvariant() {
+		eval = eval.countBootstrap(elIn.Level)
+	}
+
+	if eval.ResidualParameters.IsConjugateInvariant() {
+		if elOut, _, err = eval.EvaluateConjugateInvariant(elIn, nil); err != nil {
+			return nil, fmt.Errorf("eval.EvaluateConjugateInvariant: %w", err)
+		}
+		return
+	}
+
+	if elIn, err = eval.SwitchRingDegreeN1ToN2New(elIn); err != nil {
+		return nil, fmt.Errorf("eval.SwitchRingDegreeN1ToN2New: %w", err)
+	}
+
+	if elOut, err = eval.Evaluate(elIn); err != nil {
+		return nil, fmt.Errorf("eval.Evaluate: %w", err)
+	}
+
+	if elOut, err = eval.SwitchRingDegreeN2ToN1New(elOut); err != nil {
+		return nil, fmt.Errorf("eval.SwitchRingDegreeN2ToN1New: %w", err)
+	}
+
+	elOut.Scale = eval.ResidualParameters.DefaultScale()
+
+	// The range of the output is unknown, as the error of the bootstrapping is not modeled.
+	elOut.Range = nil
+
+	return
+}
+
+// BootstrapContext is as Bootstrap, but stops if ctx is cancelled.
+func (eval Evaluator) BootstrapContext(ctx context.Context, elIn *estimator.Element) (elOut *estimator.Element, err error) {
+	return eval.WithContext(ctx).Bootstrap(elIn)
+}
+
+// bootstrapSteps is the number of steps of Evaluate reported to
+// the Progress of the bootstrapping estimator: ScaleDown, ModUp,
+// CoeffsToSlots, EvalMod and SlotsToCoeffs.
+const bootstrapSteps = 5
+
+// Evaluate bootstraps an element of the bootstrapping ring.
+func (eval Evaluator) Evaluate(elIn *estimator.Element) (elOut *estimator.Element, err error) {
+
+	est := eval.BootstrappingParameters
+
+	rIn := elIn.Range
+
+	if err = est.Checkpoint("bootstrap", 0, bootstrapSteps); err != nil {
+		return
+	}
+
 	if _, err = eval.ScaleDown(elIn); err != nil {
 		return nil, fmt.Errorf("eval.ScaleDown: %w", err)
 	}
 
+	if err = est.Checkpoint("bootstrap", 1, bootstrapSteps); err != nil {
+		return
+	}
+
 	if err = eval.ModUp(elIn); err != nil {
 		return nil, fmt.Errorf("eval.ModUp: %w", err)
 	}
 
+	if err = est.Checkpoint("bootstrap", 2, bootstrapSteps); err != nil {
+		return
+	}
+
 	elReal, elImag, err := eval.CoeffsToSlotsNew(elIn)
 
 	if err != nil {
 		return nil, fmt.Errorf("eval.CoeffsToSlotsNew: %w", err)
 	}
 
+	if err = est.Checkpoint("bootstrap", 3, bootstrapSteps); err != nil {
+		return
+	}
+
+	if eval.BootstrappingParameters.Ranges != nil && rIn != nil {
+		eval.setModUpRange(rIn, elReal, elImag)
+	}
+
 	if elReal, err = eval.EvalModNew(elReal); err != nil {
 		return nil, fmt.Errorf("eval.EvalModNew: %w", err)
 	}
@@ -132,12 +327,22 @@ func (eval Evaluator) Bootstrap(elIn *estimator.Element) (elOut *estimator.Eleme
 		}
 	}
 
-	return eval.SlotsToCoeffsNew(elReal, elImag)
+	if err = est.Checkpoint("bootstrap", 4, bootstrapSteps); err != nil {
+		return
+	}
+
+	if elOut, err = eval.SlotsToCoeffsNew(elReal, elImag); err != nil {
+		return
+	}
+
+	return elOut, est.Checkpoint("bootstrap", bootstrapSteps, bootstrapSteps)
 }
 
 func (eval Evaluator) ScaleDown(el *estimator.Element) (*rlwe.Scale, error) {
 
+	// The scaling does not change the range of the element.
 	est := eval.BootstrappingParameters
+	est.Ranges = nil
 
 	params := &eval.BootstrappingParameters.Parameters
 
@@ -186,14 +391,22 @@ func (eval Evaluator) ScaleDown(el *estimator.Element) (*rlwe.Scale, error) {
 	return &errScale, nil
 }
 
+// ModUp raises the modulus from q to Q. If an ephemeral secret is used,
+// the element is switched to the sparse secret before and back to the
+// dense secret after, with a hoisted gadget product whose digits are
+// the element lifted from q to QP.
 func (eval Evaluator) ModUp(el *estimator.Element) (err error) {
 
 	est := eval.BootstrappingParameters
 
+	// The range of the message plus q0 * I is not tracked (see setModUpRange).
+	el.Range = nil
+
 	var H int
-	if eval.EphemeralSecret != nil {
-		if err = est.KeySwitch(el, eval.BootstrappingParameters.Sk[0]); err != nil {
-			return fmt.Errorf("est.KeySwitch: %w", err)
+	if eval.EvkDenseToSparse != nil {
+		eval.requireSwitchingKey(estimator.EvkDenseToSparse, el.Level, *eval.EvkDenseToSparse)
+		if err = est.ApplyEvaluationKey(el, *eval.EvkDenseToSparse); err != nil {
+			return fmt.Errorf("est.ApplyEvaluationKey: %w", err)
 		}
 		H = eval.EphemeralSecretWeight
 	} else {
@@ -204,7 +417,10 @@ func (eval Evaluator) ModUp(el *estimator.Element) (err error) {
 
 	Q := eval.BootstrappingParameters.Q[0]
 
-	source := estimator.NewTestRand()
+	source := estimator.TestRand{Rand: est.Rand}
+	if source.Rand == nil {
+		source = estimator.NewTestRand()
+	}
 	irwinHall := func() *big.Float {
 		var d float64
 		for i := 0; i < H+1; i++ {
@@ -231,22 +447,75 @@ func (eval Evaluator) ModUp(el *estimator.Element) (err error) {
 
 	el.Level = est.MaxLevel()
 
-	if eval.EphemeralSecret != nil {
-		if err = est.KeySwitch(el, eval.EphemeralSecret); err != nil {
-			return fmt.Errorf("est.KeySwitch: %w", err)
-		}
-	}
-
 	// Scale the message from Q0/|m| to QL/|m|, where QL is the largest modulus used during the bootstrapping.
+	scalar := new(big.Float).SetInt64(1)
 	if scale := (eval.Mod1Parameters.ScalingFactor().Float64() / eval.Mod1Parameters.MessageRatio()) / el.Scale.Float64(); scale > 1 {
 		if err = est.ScaleUp(el, rlwe.NewScale(scale)); err != nil {
 			return fmt.Errorf("est.ScaleUp: %w", err)
 		}
+		scalar.SetFloat64(math.Round(scale))
+	}
+
+	if eval.EvkSparseToDense != nil {
+
+		evk := *eval.EvkSparseToDense
+
+		// Each digit is el[1] mod q lifted to QP and
+		// multiplied by the scaling factor.
+		digit := new(big.Float).Mul(&Q, scalar)
+
+		digits := make([]*big.Float, estimator.DecompRNS(el.Level, evk.LevelP))
+		for i := range digits {
+			digits[i] = digit
+		}
+
+		eval.requireSwitchingKey(estimator.EvkSparseToDense, el.Level, evk)
+
+		if err = est.ApplyEvaluationKeyWithDigits(el, evk, digits); err != nil {
+			return fmt.Errorf("est.ApplyEvaluationKeyWithDigits: %w", err)
+		}
+	}
+
+	// The Trace of a sparsely packed element, which is not simulated,
+	// uses the keys of the rotations by 2^i for i in [LogSlots, LogN-1).
+	if est.Keys != nil {
+		for i := eval.LogMaxDimensions().Cols; i < est.Parameters.LogN()-1; i++ {
+			est.Keys.AddGaloisElement(est.Parameters.GaloisElement(1<<i), el.Level)
+		}
 	}
 
 	return
 }
 
+// setModUpRange sets the range of the outputs of the CoeffsToSlots, whose slots are the coefficients
+// of I + m / q0 normalized by 1/K, where the coefficients of the overflow I are bounded with high
+// probability by Tail * sqrt((H+1)/12) and those of m / q0 by the magnitude of the input r divided
+// by the message ratio.
+func (eval Evaluator) setModUpRange(r *estimator.Range, elReal, elImag *estimator.Element) {
+
+	est := eval.BootstrappingParameters
+
+	H := est.H
+	if eval.EvkDenseToSparse != nil {
+		H = eval.EphemeralSecretWeight
+	}
+
+	tail := est.Ranges.Tail
+	if tail == 0 {
+		tail = estimator.DefaultTail
+	}
+
+	bound := (tail*math.Sqrt(float64(H+1)/12) + r.Magnitude()/eval.Mod1Parameters.MessageRatio()) / eval.Mod1Parameters.K
+
+	for _, el := range []*estimator.Element{elReal, elImag} {
+		if el != nil {
+			// Slots in units of the scale of the EvalMod
+			b := bound * eval.Mod1Parameters.ScalingFactor().Float64() / el.Scale.Float64()
+			est.SetRange(el, estimator.Interval{Min: -b, Max: b}, estimator.Interval{})
+		}
+	}
+}
+
 func (eval Evaluator) CoeffsToSlotsNew(el *estimator.Element) (elReal, elImag *estimator.Element, err error) {
 	return eval.BootstrappingParameters.CoeffsToSlotsNew(el, eval.C2SDFTMatrix)
 }
@@ -259,6 +528,323 @@ func (eval Evaluator) EvalModNew(elIn *estimator.Element) (elOut *estimator.Elem
 	if elOut, err = eval.BootstrappingParameters.EvaluateMod1New(elIn, eval.Mod1Parameters); err != nil {
 		return nil, fmt.Errorf("eval.EvaluateMod1New: %w", err)
 	}
-	elOut.Scale = eval.BootstrappingParameters.DefaultScale()
+	eval.BootstrappingParameters.ReplaceScale(elOut, eval.BootstrappingParameters.DefaultScale())
+	return
+}
+
+// requireSwitchingKey records the switching key, used at the given level, if the
+// estimator of the bootstrapping parameters records its keys.
+func (eval Evaluator) requireSwitchingKey(key estimator.SwitchingKey, level int, evk estimator.EvaluationKey) {
+	if keys := eval.BootstrappingParameters.Keys; keys != nil {
+		keys.AddSwitchingKey(key, level, evk.LevelP)
+	}
+}
+
+// SwitchRingDegreeN1ToN2New switches an element from the ring of the residual parameters
+// to the ring of the bootstrapping parameters. The embedding X -> Y^{N2/N1} replicates the
+// N1/2 slots N2/N1 times and the evaluation key EvkN1ToN2 adds its key-switching noise in
+// the ring of degree N2. Returns the input element if both rings have the same degree.
+func (eval Evaluator) SwitchRingDegreeN1ToN2New(elN1 *estimator.Element) (elN2 *estimator.Element, err error) {
+
+	estN1 := eval.ResidualParameters
+	estN2 := eval.BootstrappingParameters
+
+	if estN1.N() == estN2.N() {
+		return elN1, nil
+	}
+
+	if elN1.Degree != 1 {
+		return nil, fmt.Errorf("degree != 1")
+	}
+
+	elN2 = estN2.NewElement(nil, 1, elN1.Level, elN1.Scale)
+	elN2.Range = elN1.Range
+
+	mask := estN1.MaxSlots() - 1
+
+	for i := 0; i < 2; i++ {
+		m0, m1 := elN1.Value[i], elN2.Value[i]
+		for j := range m1 {
+			m1[j].Set(m0[j&mask])
+		}
+	}
+
+	eval.requireSwitchingKey(estimator.EvkN1ToN2, elN2.Level, *eval.EvkN1ToN2)
+
+	if err = estN2.ApplyEvaluationKey(elN2, *eval.EvkN1ToN2); err != nil {
+		return nil, fmt.Errorf("estN2.ApplyEvaluationKey: %w", err)
+	}
+
+	return
+}
+
+// SwitchRingDegreeN2ToN1New switches an element from the ring of the bootstrapping parameters
+// to the ring of the residual parameters. The evaluation key EvkN2ToN1 adds its key-switching
+// noise in the ring of degree N2 and the extraction of the coefficients multiple of N2/N1
+// averages the N2/N1 replicas of each of the N1/2 slots.
+// Returns the input element if both rings have the same degree.
+func (eval Evaluator) SwitchRingDegreeN2ToN1New(elN2 *estimator.Element) (elN1 *estimator.Element, err error) {
+
+	estN1 := eval.ResidualParameters
+	estN2 := eval.BootstrappingParameters
+
+	if estN1.N() == estN2.N() {
+		return elN2, nil
+	}
+
+	if elN2.Degree != 1 {
+		return nil, fmt.Errorf("degree != 1")
+	}
+
+	tmp := elN2.CopyNew()
+
+	// (el[0], el[1]) -> (el[0] + el[1] * skN2 + eKey, round(1/2))
+	// where round(1/2) is now multiplied by skN1.
+	eval.requireSwitchingKey(estimator.EvkN2ToN1, tmp.Level, *eval.EvkN2ToN1)
+
+	if err = estN2.ApplyEvaluationKey(tmp, *eval.EvkN2ToN1); err != nil {
+		return nil, fmt.Errorf("estN2.ApplyEvaluationKey: %w", err)
+	}
+
+	elN1 = estN1.NewElement(nil, 1, min(tmp.Level, estN1.MaxLevel()), tmp.Scale)
+
+	slots := estN1.MaxSlots()
+	gap := estimator.NewFloat(estN2.MaxSlots() / slots)
+
+	for i := 0; i < 2; i++ {
+		m0, m1 := tmp.Value[i], elN1.Value[i]
+		for j := range m1 {
+			for k := j; k < len(m0); k += slots {
+				m1[j].Add(m1[j], m0[k])
+			}
+			m1[j][0].Quo(m1[j][0], gap)
+			m1[j][1].Quo(m1[j][1], gap)
+		}
+	}
+
+	return
+}
+
+// BootstrapMany bootstraps the elements two by two: each pair of elements with real
+// messages is packed as el0 + i * el1, bootstrapped as a single element and split back
+// into its real and imaginary parts. Both outputs of a pair thus share the same
+// bootstrapping noise. If the number of elements is odd, the last one is bootstrapped alone.
+func (eval Evaluator) BootstrapMany(els []*estimator.Element) (out []*estimator.Element, err error) {
+
+	out = make([]*estimator.Element, len(els))
+
+	if eval.ResidualParameters.IsConjugateInvariant() {
+		for i := 0; i < len(els); i += 2 {
+
+			var elRight *estimator.Element
+			if i+1 < len(els) {
+				elRight = els[i+1]
+			}
+
+			if out[i], elRight, err = eval.EvaluateConjugateInvariant(els[i], elRight); err != nil {
+				return nil, fmt.Errorf("eval.EvaluateConjugateInvariant: %w", err)
+			}
+
+			if i+1 < len(els) {
+				out[i+1] = elRight
+			}
+		}
+		return
+	}
+
+	for i := 0; i < len(els); i += 2 {
+
+		if i+1 == len(els) {
+			if out[i], err = eval.Bootstrap(els[i]); err != nil {
+				return nil, fmt.Errorf("eval.Bootstrap: %w", err)
+			}
+			break
+		}
+
+		var el *estimator.Element
+		if el, err = eval.PackRealNew(els[i], els[i+1]); err != nil {
+			return nil, fmt.Errorf("eval.PackRealNew: %w", err)
+		}
+
+		if el, err = eval.Bootstrap(el); err != nil {
+			return nil, fmt.Errorf("eval.Bootstrap: %w", err)
+		}
+
+		if out[i], out[i+1], err = eval.UnpackRealNew(el); err != nil {
+			return nil, fmt.Errorf("eval.UnpackRealNew: %w", err)
+		}
+	}
+
+	return
+}
+
+// PackRealNew packs two elements with real messages into the real and
+// imaginary parts of a new element, which is el0 + i * el1.
+// The multiplication by i (the monomial X^{N/2}) adds no noise.
+func (eval Evaluator) PackRealNew(el0, el1 *estimator.Element) (el *estimator.Element, err error) {
+
+	est := eval.ResidualParameters
+
+	if el, err = est.MulNew(el1, 1i); err != nil {
+		return nil, fmt.Errorf("est.MulNew: %w", err)
+	}
+
+	if err = est.Add(el0, el, el); err != nil {
+		return nil, fmt.Errorf("est.Add: %w", err)
+	}
+
+	return
+}
+
+// UnpackRealNew splits an element packed with PackRealNew into its real part
+// (el + conj(el))/2 and its imaginary part (el - conj(el))/2i. The conjugation
+// adds its key-switching noise and the division by two is done by doubling the scale.
+func (eval Evaluator) UnpackRealNew(el *estimator.Element) (el0, el1 *estimator.Element, err error) {
+
+	est := eval.ResidualParameters
+
+	var elConj *estimator.Element
+	if elConj, err = est.ConjugateNew(el); err != nil {
+		return nil, nil, fmt.Errorf("est.ConjugateNew: %w", err)
+	}
+
+	if el0, err = est.AddNew(el, elConj); err != nil {
+		return nil, nil, fmt.Errorf("est.AddNew: %w", err)
+	}
+
+	if el1, err = est.SubNew(el, elConj); err != nil {
+		return nil, nil, fmt.Errorf("est.SubNew: %w", err)
+	}
+
+	if err = est.Mul(el1, -1i, el1); err != nil {
+		return nil, nil, fmt.Errorf("est.Mul: %w", err)
+	}
+
+	el0.Scale = el0.Scale.Mul(rlwe.NewScale(2))
+	el1.Scale = el1.Scale.Mul(rlwe.NewScale(2))
+
+	return
+}
+
+// EvaluateConjugateInvariant bootstraps two elements of the ring.ConjugateInvariant residual ring by
+// packing them as elLeft + i * elRight in the ring.Standard bootstrapping ring. elRight can be nil.
+func (eval Evaluator) EvaluateConjugateInvariant(elLeftN1, elRightN1 *estimator.Element) (elLeftOut, elRightOut *estimator.Element, err error) {
+
+	if elLeftN1 == nil {
+		return nil, nil, fmt.Errorf("elLeftN1 cannot be nil")
+	}
+
+	eval = eval.countBootstrap(elLeftN1.Level)
+
+	estN2 := eval.BootstrappingParameters
+
+	var elN2 *estimator.Element
+	if elN2, err = eval.RealToComplexNew(elLeftN1); err != nil {
+		return nil, nil, fmt.Errorf("eval.RealToComplexNew: %w", err)
+	}
+
+	// Repacks elRightN1 into the imaginary part of elLeftN1
+	if elRightN1 != nil {
+
+		var elRightN2 *estimator.Element
+		if elRightN2, err = eval.RealToComplexNew(elRightN1); err != nil {
+			return nil, nil, fmt.Errorf("eval.RealToComplexNew: %w", err)
+		}
+
+		if err = estN2.Mul(elRightN2, 1i, elRightN2); err != nil {
+			return nil, nil, fmt.Errorf("estN2.Mul: %w", err)
+		}
+
+		if err = estN2.Add(elN2, elRightN2, elN2); err != nil {
+			return nil, nil, fmt.Errorf("estN2.Add: %w", err)
+		}
+	}
+
+	if elN2, err = eval.Evaluate(elN2); err != nil {
+		return nil, nil, fmt.Errorf("eval.Evaluate: %w", err)
+	}
+
+	// The SlotsToCoeffs transformation scales the element by 0.5
+	// to compensate the factor 2 introduced by ComplexToRealNew.
+	elN2.Scale = elN2.Scale.Mul(rlwe.NewScale(1 / 2.0))
+
+	if elLeftOut, err = eval.ComplexToRealNew(elN2); err != nil {
+		return nil, nil, fmt.Errorf("eval.ComplexToRealNew: %w", err)
+	}
+
+	// Extracts the imaginary part
+	if elRightN1 != nil {
+
+		if err = estN2.Mul(elN2, -1i, elN2); err != nil {
+			return nil, nil, fmt.Errorf("estN2.Mul: %w", err)
+		}
+
+		if elRightOut, err = eval.ComplexToRealNew(elN2); err != nil {
+			return nil, nil, fmt.Errorf("eval.ComplexToRealNew: %w", err)
+		}
+	}
+
+	return
+}
+
+// RealToComplexNew switches an element from the ring.ConjugateInvariant residual ring of degree N1
+// to the ring.Standard bootstrapping ring of degree N2 = 2N1. The unfolding preserves the N1 real
+// slots and the evaluation key EvkRealToCmplx adds its key-switching noise.
+func (eval Evaluator) RealToComplexNew(elReal *estimator.Element) (elCmplx *estimator.Element, err error) {
+
+	estN2 := eval.BootstrappingParameters
+
+	if elReal.Degree != 1 {
+		return nil, fmt.Errorf("degree != 1")
+	}
+
+	elCmplx = estN2.NewElement(nil, 1, elReal.Level, elReal.Scale)
+
+	for i := 0; i < 2; i++ {
+		for j := range elCmplx.Value[i] {
+			elCmplx.Value[i][j].Set(elReal.Value[i][j])
+		}
+	}
+
+	eval.requireSwitchingKey(estimator.EvkRealToCmplx, elCmplx.Level, *eval.EvkRealToCmplx)
+
+	if err = estN2.ApplyEvaluationKey(elCmplx, *eval.EvkRealToCmplx); err != nil {
+		return nil, fmt.Errorf("estN2.ApplyEvaluationKey: %w", err)
+	}
+
+	return
+}
+
+// ComplexToRealNew switches an element from the ring.Standard bootstrapping ring of degree N2
+// to the ring.ConjugateInvariant residual ring of degree N1 = N2/2. The evaluation key EvkCmplxToReal
+// adds its key-switching noise and the folding p(Y) + p(Y^-1) maps each slot to twice its real part,
+// which is compensated by doubling the scale.
+func (eval Evaluator) ComplexToRealNew(elCmplx *estimator.Element) (elReal *estimator.Element, err error) {
+
+	estN1 := eval.ResidualParameters
+	estN2 := eval.BootstrappingParameters
+
+	if elCmplx.Degree != 1 {
+		return nil, fmt.Errorf("degree != 1")
+	}
+
+	tmp := elCmplx.CopyNew()
+
+	eval.requireSwitchingKey(estimator.EvkCmplxToReal, tmp.Level, *eval.EvkCmplxToReal)
+
+	if err = estN2.ApplyEvaluationKey(tmp, *eval.EvkCmplxToReal); err != nil {
+		return nil, fmt.Errorf("estN2.ApplyEvaluationKey: %w", err)
+	}
+
+	elReal = estN1.NewElement(nil, 1, min(tmp.Level, estN1.MaxLevel()), tmp.Scale.Mul(rlwe.NewScale(2)))
+
+	two := estimator.NewFloat(2)
+
+	for i := 0; i < 2; i++ {
+		for j := range elReal.Value[i] {
+			elReal.Value[i][j][0].Mul(tmp.Value[i][j][0], two)
+		}
+	}
+
 	return
 }
@@ -0,0 +1,300 @@
+package estimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// testParameters returns residual parameters of degree 2^logN whose
+// primes are NTT-friendly in the bootstrapping ring of degree 2^11.
+func testParameters(t *testing.T, logN int, ringType ring.Type) ckks.Parameters {
+
+	t.Helper()
+
+	primes, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            11,
+		LogQ:            []int{60, 40},
+		LogP:            []int{61},
+		LogDefaultScale: 40,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            logN,
+		Q:               primes.Q(),
+		P:               primes.P(),
+		LogDefaultScale: 40,
+		RingType:        ringType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return params
+}
+
+// testEvaluators returns the evaluator of the estimator and the evaluator, keys and key pair of Lattigo.
+func testEvaluators(t *testing.T, params ckks.Parameters, btpLit bootstrapping.ParametersLiteral) (evalEst Evaluator, eval *bootstrapping.Evaluator, evk *bootstrapping.EvaluationKeys, sk *rlwe.SecretKey, pk *rlwe.PublicKey) {
+
+	t.Helper()
+
+	btpLit.Xs = params.Xs()
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpLit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sk, pk = rlwe.NewKeyGenerator(params).GenKeyPairNew()
+
+	if evk, _, err = btpParams.GenEvaluationKeys(sk); err != nil {
+		t.Fatal(err)
+	}
+
+	if eval, err = bootstrapping.NewEvaluator(btpParams, evk); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewEvaluatorFromSeed(btpParams, 1), eval, evk, sk, pk
+}
+
+// testBootstrap bootstraps the same random elements with Lattigo and with the evaluator of the
+// estimator, and returns the evaluator, the keys of Lattigo and the predicted and actual precision.
+func testBootstrap(t *testing.T, params ckks.Parameters, btpLit bootstrapping.ParametersLiteral, trials int) (evalEst Evaluator, evk *bootstrapping.EvaluationKeys, predicted, actual estimator.Stats) {
+
+	t.Helper()
+
+	evalEst, eval, evk, sk, pk := testEvaluators(t, params, btpLit)
+
+	est := evalEst.ResidualParameters
+	ecd := ckks.NewEncoder(params)
+	dec := rlwe.NewDecryptor(params, sk)
+	source := estimator.NewTestRand(1)
+
+	a, b := complex(-1, -1), complex(1, 1)
+	if params.RingType() == ring.ConjugateInvariant {
+		a, b = -1, 1
+	}
+
+	predicted, actual = estimator.NewStats(), estimator.NewStats()
+
+	for i := 0; i < trials; i++ {
+
+		values, el, _, ct := est.NewTestVectorFromSeed(ecd, pk, a, b, source)
+
+		ct, err := eval.Bootstrap(ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if el, err = evalEst.Bootstrap(el); err != nil {
+			t.Fatal(err)
+		}
+
+		predicted.Add(ckks.GetPrecisionStats(params, ecd, dec, values, est.Decrypt(el), 0, false))
+		actual.Add(ckks.GetPrecisionStats(params, ecd, dec, values, ct, 0, false))
+	}
+
+	predicted.Finalize()
+	actual.Finalize()
+
+	return
+}
+
+// checkPrecision checks that the predicted average precision is
+// within tolerance bits of the actual average precision.
+func checkPrecision(t *testing.T, predicted, actual estimator.Stats, tolerance float64) {
+
+	t.Helper()
+
+	if have, want := predicted.AVGLog2Prec.L2, actual.AVGLog2Prec.L2; math.Abs(have-want) > tolerance {
+		t.Errorf("AVG Log2Prec: predicted %.2f, actual %.2f", have, want)
+	}
+}
+
+// checkEvaluationKey checks that the evaluation key of the estimator has the levels of the key of Lattigo.
+func checkEvaluationKey(t *testing.T, name string, have *estimator.EvaluationKey, want *rlwe.EvaluationKey) {
+
+	t.Helper()
+
+	if (have == nil) != (want == nil) {
+		t.Fatalf("%s: estimator=%t, Lattigo=%t", name, have != nil, want != nil)
+	}
+
+	if have != nil && (have.LevelQ != want.LevelQ() || have.LevelP != want.LevelP()) {
+		t.Fatalf("%s: LevelQ=%d, LevelP=%d, Lattigo: LevelQ=%d, LevelP=%d", name, have.LevelQ, have.LevelP, want.LevelQ(), want.LevelP())
+	}
+}
+
+func TestBootstrapRingSwitching(t *testing.T) {
+
+	params := testParameters(t, 10, ring.Standard)
+
+	evalEst, evk, predicted, actual := testBootstrap(t, params, bootstrapping.ParametersLiteral{LogN: utils.Pointy(11)}, 4)
+
+	checkEvaluationKey(t, "EvkN1ToN2", evalEst.EvkN1ToN2, evk.EvkN1ToN2)
+	checkEvaluationKey(t, "EvkN2ToN1", evalEst.EvkN2ToN1, evk.EvkN2ToN1)
+
+	checkPrecision(t, predicted, actual, 0.5)
+}
+
+func TestBootstrapSparseSecret(t *testing.T) {
+
+	params := testParameters(t, 11, ring.Standard)
+
+	for _, h := range []int{16, 32} {
+		t.Run(fmt.Sprintf("H=%d", h), func(t *testing.T) {
+
+			btpLit := bootstrapping.ParametersLiteral{
+				LogN:                  utils.Pointy(11),
+				EphemeralSecretWeight: utils.Pointy(h),
+			}
+
+			evalEst, evk, predicted, actual := testBootstrap(t, params, btpLit, 4)
+
+			checkEvaluationKey(t, "EvkDenseToSparse", evalEst.EvkDenseToSparse, evk.EvkDenseToSparse)
+			checkEvaluationKey(t, "EvkSparseToDense", evalEst.EvkSparseToDense, evk.EvkSparseToDense)
+
+			// The dense to sparse key is generated with the default error distribution.
+			if have, want := evalEst.EvkDenseToSparse.Sigma, rlwe.DefaultXe.Sigma; have != want {
+				t.Errorf("EvkDenseToSparse.Sigma: %f != %f", have, want)
+			}
+
+			checkPrecision(t, predicted, actual, 0.5)
+		})
+	}
+}
+
+func TestBootstrapMany(t *testing.T) {
+
+	params := testParameters(t, 11, ring.Standard)
+
+	evalEst, eval, _, sk, pk := testEvaluators(t, params, bootstrapping.ParametersLiteral{LogN: utils.Pointy(11)})
+
+	est := evalEst.ResidualParameters
+	ecd := ckks.NewEncoder(params)
+	dec := rlwe.NewDecryptor(params, sk)
+	source := estimator.NewTestRand(1)
+
+	galEl := params.GaloisElementForComplexConjugation()
+	evalCKKS := ckks.NewEvaluator(params, rlwe.NewMemEvaluationKeySet(nil, rlwe.NewKeyGenerator(params).GenGaloisKeyNew(galEl, sk)))
+
+	// The first two elements are packed together and the third one is bootstrapped alone.
+	values := make([][]*bignum.Complex, 3)
+	els := make([]*estimator.Element, 3)
+	cts := make([]*rlwe.Ciphertext, 3)
+
+	for i := range els {
+		values[i], els[i], _, cts[i] = est.NewTestVectorFromSeed(ecd, pk, -1, 1, source)
+	}
+
+	els, err := evalEst.BootstrapMany(els)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same packing evaluated with Lattigo
+	packed, err := evalCKKS.MulNew(cts[1], 1i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = evalCKKS.Add(cts[0], packed, packed); err != nil {
+		t.Fatal(err)
+	}
+
+	if packed, err = eval.Bootstrap(packed); err != nil {
+		t.Fatal(err)
+	}
+
+	conj, err := evalCKKS.ConjugateNew(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cts[0], err = evalCKKS.AddNew(packed, conj); err != nil {
+		t.Fatal(err)
+	}
+
+	if cts[1], err = evalCKKS.SubNew(packed, conj); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = evalCKKS.Mul(cts[1], -1i, cts[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	cts[0].Scale = cts[0].Scale.Mul(rlwe.NewScale(2))
+	cts[1].Scale = cts[1].Scale.Mul(rlwe.NewScale(2))
+
+	if cts[2], err = eval.Bootstrap(cts[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range els {
+
+		have := est.Decrypt(els[i])
+
+		// The imaginary part of the packed pair is not leaked into the real elements.
+		for j := range have {
+			if h, w := have[j].Complex128(), values[i][j].Complex128(); math.Abs(real(h)-real(w)) > 0x1p-10 || math.Abs(imag(h)) > 0x1p-10 {
+				t.Fatalf("element %d, slot %d: %v != %v", i, j, have[j], values[i][j])
+			}
+		}
+
+		predicted, actual := estimator.NewStats(), estimator.NewStats()
+		predicted.Add(ckks.GetPrecisionStats(params, ecd, dec, values[i], have, 0, false))
+		actual.Add(ckks.GetPrecisionStats(params, ecd, dec, values[i], cts[i], 0, false))
+		predicted.Finalize()
+		actual.Finalize()
+
+		checkPrecision(t, predicted, actual, 0.5)
+	}
+}
+
+func TestBootstrapContext(t *testing.T) {
+
+	params := testParameters(t, 11, ring.Standard)
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, bootstrapping.ParametersLiteral{LogN: utils.Pointy(11), Xs: params.Xs()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evalEst := NewEvaluatorFromSeed(btpParams, 1)
+
+	_, el, _, _ := evalEst.ResidualParameters.NewTestVectorFromSeed(ckks.NewEncoder(params), nil, -1-1i, 1+1i, estimator.NewTestRand(1))
+
+	// Cancelled after the first step of the bootstrapping
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var progress []estimator.Progress
+	evalEst.BootstrappingParameters.Progress = func(p estimator.Progress) {
+		progress = append(progress, p)
+		if p.Stage == "bootstrap" && p.Done == 1 {
+			cancel()
+		}
+	}
+
+	if _, err = evalEst.BootstrapContext(ctx, el); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error %v does not wrap %v", err, context.Canceled)
+	}
+
+	if last := progress[len(progress)-1]; last.Stage != "bootstrap" || last.Done != 1 {
+		t.Fatalf("last progress: %v", last)
+	}
+}
@@ -0,0 +1,331 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+)
+
+// SweepParameters are the ranges of bootstrapping parameters explored by Sweep.
+// An empty range keeps the value of the base bootstrapping.ParametersLiteral.
+type SweepParameters struct {
+	LogMessageRatio []int
+	K               []int
+	Mod1Degree      []int
+	DoubleAngle     []int
+	Mod1InvDegree   []int
+
+	// CoeffsToSlotsLevels and SlotsToCoeffsLevels are the number of
+	// levels consumed by the CoeffsToSlots and SlotsToCoeffs steps.
+	CoeffsToSlotsLevels []int
+	SlotsToCoeffsLevels []int
+
+	CoeffsToSlotsLogBSGSRatio []int
+	SlotsToCoeffsLogBSGSRatio []int
+
+	// Samples is the number of elements bootstrapped per candidate.
+	Samples int
+
+	// Heuristic selects the noise model of the estimators (see estimator.Estimator).
+	Heuristic bool
+
+	// Seed, if not nil, is the seed of the secrets, noise and inputs of the
+	// estimators of each candidate (see NewEvaluatorFromSeed).
+	Seed *int64
+}
+
+// SweepResult is a candidate of Sweep.
+type SweepResult struct {
+	ParametersLiteral         bootstrapping.ParametersLiteral
+	CoeffsToSlotsLogBSGSRatio int
+	SlotsToCoeffsLogBSGSRatio int
+
+	// Stats is the output precision of the candidate.
+	Stats estimator.Stats
+
+	// Depth is the number of levels consumed by the bootstrapping.
+	Depth int
+
+	// Log2FailureProbability is the log2 of the probability that the
+	// bootstrapping of an element fails (see Evaluator.Log2FailureProbability).
+	Log2FailureProbability float64
+
+	// Err is non-nil if the candidate could not be instantiated or evaluated.
+	Err error
+}
+
+// Precision returns the precision of the bootstrapping of the given number
+// of elements with values uniformly distributed in [-1, 1] + i[-1, 1], sampled
+// from the Rand of the residual estimator if it is not nil.
+func (eval Evaluator) Precision(samples int) (stats estimator.Stats, err error) {
+
+	est := eval.ResidualParameters
+	params := est.Parameters
+	ecd := ckks.NewEncoder(params)
+
+	source := estimator.TestRand{Rand: est.Rand}
+	if source.Rand == nil {
+		source = estimator.NewTestRand()
+	}
+
+	stats = estimator.NewStats()
+
+	for i := 0; i < samples; i++ {
+
+		values, el, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, source)
+		est.AddEncryptionNoisePk(el)
+
+		if el, err = eval.Bootstrap(el); err != nil {
+			return stats, fmt.Errorf("eval.Bootstrap: %w", err)
+		}
+
+		stats.Add(ckks.GetPrecisionStats(params, ecd, nil, values, est.Decrypt(el), 0, false))
+	}
+
+	stats.Finalize()
+
+	return
+}
+
+// Log2FailureProbability returns the log2 of the probability that the integer overflow I of ModUp,
+// whose distribution is the Irwin-Hall distribution of the sum of H+1 uniform variables, is outside
+// of the interval [-K, K] of the EvalMod step for at least one of the N coefficients.
+func (eval Evaluator) Log2FailureProbability() float64 {
+
+	H := eval.BootstrappingParameters.H
+	if eval.EvkDenseToSparse != nil {
+		H = eval.EphemeralSecretWeight
+	}
+
+	sigma := math.Sqrt(float64(H+1) / 12)
+
+	// Union bound over the N coefficients
+	logP := float64(eval.BootstrappingParameters.LogN) + estimator.Log2Erfc((eval.Mod1Parameters.K-0.5)/(sigma*math.Sqrt2))
+
+	return min(logP, 0)
+}
+
+// Sweep evaluates the bootstrapping for each combination of the ranges of sweep applied to base
+// and returns the results in the order of the enumeration. Candidates that cannot be instantiated
+// are returned with a non-nil Err.
+func Sweep(residualParameters ckks.Parameters, base bootstrapping.ParametersLiteral, sweep SweepParameters) (results []SweepResult, err error) {
+
+	LogSlots, err := base.GetLogSlots()
+	if err != nil {
+		return nil, fmt.Errorf("base.GetLogSlots: %w", err)
+	}
+
+	C2S, err := base.GetCoeffsToSlotsFactorizationDepthAndLogScales(LogSlots)
+	if err != nil {
+		return nil, fmt.Errorf("base.GetCoeffsToSlotsFactorizationDepthAndLogScales: %w", err)
+	}
+
+	S2C, err := base.GetSlotsToCoeffsFactorizationDepthAndLogScales(LogSlots)
+	if err != nil {
+		return nil, fmt.Errorf("base.GetSlotsToCoeffsFactorizationDepthAndLogScales: %w", err)
+	}
+
+	getters := []func() (int, error){
+		base.GetLogMessageRatio,
+		base.GetK,
+		base.GetMod1Degree,
+		base.GetDoubleAngle,
+		base.GetMod1InvDegree,
+	}
+
+	ranges := [][]int{
+		sweep.LogMessageRatio,
+		sweep.K,
+		sweep.Mod1Degree,
+		sweep.DoubleAngle,
+		sweep.Mod1InvDegree,
+		sweep.CoeffsToSlotsLevels,
+		sweep.SlotsToCoeffsLevels,
+		sweep.CoeffsToSlotsLogBSGSRatio,
+		sweep.SlotsToCoeffsLogBSGSRatio,
+	}
+
+	for i, get := range getters {
+		if len(ranges[i]) == 0 {
+			var v int
+			if v, err = get(); err != nil {
+				return nil, fmt.Errorf("base: %w", err)
+			}
+			ranges[i] = []int{v}
+		}
+	}
+
+	if len(ranges[5]) == 0 {
+		ranges[5] = []int{len(C2S)}
+	}
+
+	if len(ranges[6]) == 0 {
+		ranges[6] = []int{len(S2C)}
+	}
+
+	// -1 keeps the ratio of the instantiated parameters
+	for _, i := range []int{7, 8} {
+		if len(ranges[i]) == 0 {
+			ranges[i] = []int{-1}
+		}
+	}
+
+	idx := make([]int, len(ranges))
+
+	for {
+
+		v := make([]int, len(ranges))
+		for i := range ranges {
+			v[i] = ranges[i][idx[i]]
+		}
+
+		lit := base
+		lit.LogMessageRatio = utils.Pointy(v[0])
+		lit.K = utils.Pointy(v[1])
+		lit.Mod1Degree = utils.Pointy(v[2])
+		lit.DoubleAngle = utils.Pointy(v[3])
+		lit.Mod1InvDegree = utils.Pointy(v[4])
+		lit.CoeffsToSlotsFactorizationDepthAndLogScales = factorization(v[5], C2S[0][0])
+		lit.SlotsToCoeffsFactorizationDepthAndLogScales = factorization(v[6], S2C[0][0])
+
+		results = append(results, evaluateCandidate(residualParameters, lit, v[7], v[8], sweep))
+
+		// Next combination
+		i := 0
+		for ; i < len(idx); i++ {
+			if idx[i]++; idx[i] < len(ranges[i]) {
+				break
+			}
+			idx[i] = 0
+		}
+
+		if i == len(idx) {
+			break
+		}
+	}
+
+	return
+}
+
+// factorization returns the factorization of a linear transformation
+// over the given number of levels, each with the given log scale.
+func factorization(levels, logScale int) (f [][]int) {
+	f = make([][]int, levels)
+	for i := range f {
+		f[i] = []int{logScale}
+	}
+	return
+}
+
+func evaluateCandidate(residualParameters ckks.Parameters, lit bootstrapping.ParametersLiteral, logBSGSRatioC2S, logBSGSRatioS2C int, sweep SweepParameters) (res SweepResult) {
+
+	res.ParametersLiteral = lit
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(residualParameters, lit)
+	if err != nil {
+		res.Err = fmt.Errorf("bootstrapping.NewParametersFromLiteral: %w", err)
+		return
+	}
+
+	if logBSGSRatioC2S >= 0 {
+		btpParams.CoeffsToSlotsParameters.LogBSGSRatio = logBSGSRatioC2S
+	}
+
+	if logBSGSRatioS2C >= 0 {
+		btpParams.SlotsToCoeffsParameters.LogBSGSRatio = logBSGSRatioS2C
+	}
+
+	res.CoeffsToSlotsLogBSGSRatio = btpParams.CoeffsToSlotsParameters.LogBSGSRatio
+	res.SlotsToCoeffsLogBSGSRatio = btpParams.SlotsToCoeffsParameters.LogBSGSRatio
+	res.Depth = btpParams.Depth()
+
+	var eval Evaluator
+	if sweep.Seed != nil {
+		eval = NewEvaluatorFromSeed(btpParams, *sweep.Seed)
+		source := estimator.NewTestRand(*sweep.Seed)
+		eval.ResidualParameters.Rand = source.Rand
+		eval.BootstrappingParameters.Rand = source.Rand
+	} else {
+		eval = NewEvaluator(btpParams)
+	}
+
+	eval.ResidualParameters.Heuristic = sweep.Heuristic
+	eval.BootstrappingParameters.Heuristic = sweep.Heuristic
+
+	res.Log2FailureProbability = eval.Log2FailureProbability()
+
+	if res.Stats, err = eval.Precision(max(1, sweep.Samples)); err != nil {
+		res.Err = fmt.Errorf("eval.Precision: %w", err)
+	}
+
+	return
+}
+
+// Dominates returns true if r is at least as good as other for the output precision,
+// the depth and the failure probability, and strictly better for one of them.
+// The output precision is the average log2 precision of the L2 norm.
+func (r SweepResult) Dominates(other SweepResult) bool {
+
+	prec, otherPrec := r.Stats.AVGLog2Prec.L2, other.Stats.AVGLog2Prec.L2
+
+	if prec < otherPrec || r.Depth > other.Depth || r.Log2FailureProbability > other.Log2FailureProbability {
+		return false
+	}
+
+	return prec > otherPrec || r.Depth < other.Depth || r.Log2FailureProbability < other.Log2FailureProbability
+}
+
+// ParetoFront returns the results that are not dominated by any other result,
+// sorted by increasing depth and then by decreasing output precision.
+// Results with a non-nil Err are ignored.
+func ParetoFront(results []SweepResult) (front []SweepResult) {
+
+	for i := range results {
+
+		if results[i].Err != nil {
+			continue
+		}
+
+		dominated := false
+		for j := range results {
+			if results[j].Err == nil && results[j].Dominates(results[i]) {
+				dominated = true
+				break
+			}
+		}
+
+		if !dominated {
+			front = append(front, results[i])
+		}
+	}
+
+	sort.SliceStable(front, func(i, j int) bool {
+		if front[i].Depth != front[j].Depth {
+			return front[i].Depth < front[j].Depth
+		}
+		return front[i].Stats.AVGLog2Prec.L2 > front[j].Stats.AVGLog2Prec.L2
+	})
+
+	return
+}
+
+// String returns a one-line summary of the result.
+func (r SweepResult) String() string {
+
+	if r.Err != nil {
+		return fmt.Sprintf("error: %s", r.Err)
+	}
+
+	lit := r.ParametersLiteral
+
+	return fmt.Sprintf("LogMessageRatio=%2d K=%2d Mod1Degree=%2d DoubleAngle=%d Mod1InvDegree=%d C2S=(%d, %d) S2C=(%d, %d) | Depth=%2d Prec=%5.2f log2(Pfail)=%7.2f",
+		*lit.LogMessageRatio, *lit.K, *lit.Mod1Degree, *lit.DoubleAngle, *lit.Mod1InvDegree,
+		len(lit.CoeffsToSlotsFactorizationDepthAndLogScales), r.CoeffsToSlotsLogBSGSRatio,
+		len(lit.SlotsToCoeffsFactorizationDepthAndLogScales), r.SlotsToCoeffsLogBSGSRatio,
+		r.Depth, r.Stats.AVGLog2Prec.L2, r.Log2FailureProbability)
+}
@@ -0,0 +1,71 @@
+package estimator
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testSweepResult returns a synthetic SweepResult with the given output precision, depth and failure probability.
+func testSweepResult(prec float64, depth int, log2P float64) (r SweepResult) {
+	r.Stats.AVGLog2Prec.L2 = prec
+	r.Depth = depth
+	r.Log2FailureProbability = log2P
+	return
+}
+
+func TestDominates(t *testing.T) {
+
+	r := testSweepResult(20, 10, -40)
+
+	for _, tc := range []struct {
+		other SweepResult
+		want  bool
+	}{
+		{testSweepResult(20, 10, -40), false},
+		{testSweepResult(19, 10, -40), true},
+		{testSweepResult(20, 11, -40), true},
+		{testSweepResult(20, 10, -30), true},
+		{testSweepResult(21, 11, -40), false},
+		{testSweepResult(19, 9, -40), false},
+		{testSweepResult(19, 11, -50), false},
+	} {
+		if have := r.Dominates(tc.other); have != tc.want {
+			t.Errorf("Dominates(prec=%.0f, depth=%d, log2P=%.0f): %t != %t",
+				tc.other.Stats.AVGLog2Prec.L2, tc.other.Depth, tc.other.Log2FailureProbability, have, tc.want)
+		}
+	}
+}
+
+func TestParetoFront(t *testing.T) {
+
+	failed := testSweepResult(30, 1, -100)
+	failed.Err = fmt.Errorf("failed")
+
+	results := []SweepResult{
+		testSweepResult(20, 10, -40), // dominated by the next one
+		testSweepResult(22, 10, -40),
+		testSweepResult(25, 12, -40),
+		testSweepResult(18, 8, -40),
+		testSweepResult(18, 8, -40), // equal to the previous one, which does not dominate it
+		testSweepResult(17, 8, -50),
+		testSweepResult(16, 9, -40), // dominated by (18, 8, -40)
+		failed,                      // ignored, but would dominate all the others
+	}
+
+	type point struct {
+		prec  float64
+		depth int
+		log2P float64
+	}
+
+	var have []point
+	for _, r := range ParetoFront(results) {
+		have = append(have, point{r.Stats.AVGLog2Prec.L2, r.Depth, r.Log2FailureProbability})
+	}
+
+	want := []point{{18, 8, -40}, {18, 8, -40}, {17, 8, -50}, {22, 10, -40}, {25, 12, -40}}
+
+	if fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Fatalf("ParetoFront: %v != %v", have, want)
+	}
+}
@@ -0,0 +1,363 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Trial evaluates one trial of a circuit and returns the expected values and the values
+// predicted by the estimator. The source is seeded with the seed of the trial: sampling the
+// inputs from it and assigning source.Rand to the Rand of a ShallowCopy of the estimator
+// returned by Campaign.NewEstimator, whose secret is sampled from the seed of the campaign,
+// makes the trial reproducible.
+type Trial func(source TestRand) (want, have []*bignum.Complex, err error)
+
+// Campaign runs independent trials of a circuit on a pool of goroutines
+// and aggregates their statistics.
+type Campaign struct {
+	// Parameters are the parameters of the circuit.
+	Parameters ckks.Parameters
+
+	// NewTrial returns the Trial evaluated by a worker. It is called once per worker
+	// and the Trial must only use state that it owns, e.g. a ShallowCopy of an Estimator.
+	NewTrial func() (Trial, error)
+
+	// Trials is the maximum number of trials.
+	Trials int
+
+	// Workers is the number of goroutines (default: runtime.NumCPU()).
+	Workers int
+
+	// Seed is the seed from which the seed of each trial is derived (see TrialSeed).
+	Seed int64
+
+	// Secret identifies the secret of the estimators of the trials, e.g. the SecretID of the
+	// estimator returned by NewEstimator. It is saved in the CampaignState, and Resume returns
+	// an error wrapping ErrSecretMismatch if the completed trials of the state were evaluated
+	// with another secret.
+	Secret SecretID
+
+	// HalfWidth enables the early stopping: the campaign stops once the half-width of
+	// the confidence interval on the mean of the AVG L2 log2 precision of the slots is
+	// at most HalfWidth bits. The trials in progress are completed and included.
+	HalfWidth float64
+
+	// Confidence is the confidence level of the interval (default: 0.95).
+	Confidence float64
+
+	// MinTrials is the number of trials before early stopping (default: 8).
+	MinTrials int
+
+	// Checkpoint is the file to which the CampaignState is saved every CheckpointEvery
+	// completed trials (default: 1) and at the end of the campaign (none if empty).
+	// The campaign can be resumed from it with LoadJSON and Resume.
+	Checkpoint      string
+	CheckpointEvery int
+}
+
+// CampaignResult is the result of a Campaign.
+type CampaignResult struct {
+	// Stats are the finalized statistics of the completed trials.
+	Stats Stats
+
+	// Trials is the number of completed trials.
+	Trials int
+
+	// Mean is the AVG L2 log2 precision of the slots, whose per-trial averages
+	// are used for the confidence interval of half-width HalfWidth.
+	Mean, HalfWidth float64
+
+	// Stopped is true if the campaign stopped before Trials trials.
+	Stopped bool
+
+	// State is the final state of the campaign.
+	State CampaignState
+}
+
+// NewEstimator returns an estimator whose secret is sampled from the seed of the campaign
+// (see NewEstimatorFromSeed), so that the trials are reproducible across processes.
+func (c Campaign) NewEstimator() Estimator {
+	return NewEstimatorFromSeed(c.Parameters, c.Seed)
+}
+
+// TrialSeed returns the seed of the i-th trial of a campaign with the given seed,
+// which does not depend on the worker evaluating the trial.
+func TrialSeed(seed int64, i int) int64 {
+	// SplitMix64
+	z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// campaignWorker is the state of a worker of a campaign.
+type campaignWorker struct {
+	trial  Trial
+	params ckks.Parameters
+	ecd    *ckks.Encoder
+}
+
+// trialResult is the statistics and the AVG L2 log2 precision of the slots of a trial, or its error.
+type trialResult struct {
+	i     int
+	stats Stats
+	prec  float64
+	err   error
+}
+
+// Run runs the campaign and returns the statistics of the completed trials.
+// It returns the first error returned by a trial.
+func (c Campaign) Run() (res CampaignResult, err error) {
+	return c.Resume(NewCampaignState(c.Seed))
+}
+
+// Resume runs the trials of the campaign that are not completed in the given state,
+// whose seed replaces the Seed of the campaign, and returns the statistics of all
+// the completed trials. It returns the first error returned by a trial, after having
+// saved the checkpoint of the other completed trials.
+func (c Campaign) Resume(state CampaignState) (res CampaignResult, err error) {
+
+	if c.NewTrial == nil {
+		return res, fmt.Errorf("invalid campaign: missing NewTrial")
+	}
+
+	if c.Trials < 1 {
+		return res, fmt.Errorf("invalid campaign: Trials=%d < 1", c.Trials)
+	}
+
+	confidence := c.Confidence
+	if confidence == 0 {
+		confidence = 0.95
+	}
+
+	if confidence <= 0 || confidence >= 1 {
+		return res, fmt.Errorf("invalid campaign: Confidence=%f not in (0, 1)", confidence)
+	}
+
+	minTrials := c.MinTrials
+	if minTrials < 1 {
+		minTrials = 8
+	}
+
+	if len(state.Completed) != 0 {
+		if err = state.Secret.check(c.Secret); err != nil {
+			return res, fmt.Errorf("invalid state: %w", err)
+		}
+	}
+
+	state.Secret = c.Secret
+
+	if state.Stats.Slots[2].Counts == nil {
+		state.Stats = NewStats()
+	}
+
+	completed := map[int]bool{}
+	for _, i := range state.Completed {
+		completed[i] = true
+	}
+
+	var pending []int
+	for i := 0; i < c.Trials; i++ {
+		if !completed[i] {
+			pending = append(pending, i)
+		}
+	}
+
+	z := normalQuantile((1 + confidence) / 2)
+
+	// stopping returns true if the confidence interval is tight enough.
+	stopping := func() bool {
+		n := len(state.Completed)
+		res.Mean, res.HalfWidth = meanHalfWidth(state.Sum, state.SumSquares, n, z)
+		return c.HalfWidth > 0 && n >= minTrials && res.HalfWidth <= c.HalfWidth
+	}
+
+	if len(pending) != 0 && !stopping() {
+		if err = c.run(&state, pending, stopping); err != nil {
+			return
+		}
+	}
+
+	stopping()
+
+	res.Trials = len(state.Completed)
+	res.Stopped = res.Trials < c.Trials
+	res.State = state
+
+	res.Stats = NewStats()
+	if err = res.Stats.Merge(state.Stats); err != nil {
+		return res, fmt.Errorf("res.Stats.Merge: %w", err)
+	}
+
+	res.Stats.Finalize()
+
+	return
+}
+
+// run evaluates the pending trials on a pool of workers and adds them to the state,
+// until all are completed, a trial returns an error or stopping returns true.
+func (c Campaign) run(state *CampaignState, pending []int, stopping func() bool) (err error) {
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(pending))
+
+	checkpointEvery := c.CheckpointEvery
+	if checkpointEvery < 1 {
+		checkpointEvery = 1
+	}
+
+	params := c.Parameters
+	log2Scale := params.DefaultScale().Log2()
+	ecd := ckks.NewEncoder(params)
+
+	pool := make([]*campaignWorker, workers)
+	for i := range pool {
+
+		var trial Trial
+		if trial, err = c.NewTrial(); err != nil {
+			return fmt.Errorf("NewTrial: %w", err)
+		}
+
+		pool[i] = &campaignWorker{
+			trial:  trial,
+			params: params,
+			ecd:    ecd.ShallowCopy(),
+		}
+	}
+
+	jobs := make(chan int)
+	done := make(chan struct{})
+	results := make(chan trialResult, workers)
+
+	go func() {
+		defer close(jobs)
+		for _, i := range pending {
+			select {
+			case jobs <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, w := range pool {
+		wg.Add(1)
+		go func(w *campaignWorker) {
+			defer wg.Done()
+			for i := range jobs {
+				results <- w.run(i, TrialSeed(state.Seed, i), log2Scale)
+			}
+		}(w)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var stopped bool
+	stop := func() {
+		if !stopped {
+			close(done)
+			stopped = true
+		}
+	}
+
+	var unsaved int
+
+	for r := range results {
+
+		if r.err != nil {
+			if err == nil {
+				err = fmt.Errorf("trial %d: %w", r.i, r.err)
+			}
+			stop()
+			continue
+		}
+
+		if mergeErr := state.add(r); mergeErr != nil && err == nil {
+			err = mergeErr
+			stop()
+			continue
+		}
+
+		if unsaved++; c.Checkpoint != "" && unsaved >= checkpointEvery {
+			if saveErr := SaveJSON(c.Checkpoint, state); saveErr != nil && err == nil {
+				err = fmt.Errorf("SaveJSON: %w", saveErr)
+				stop()
+			}
+			unsaved = 0
+		}
+
+		if stopping() {
+			stop()
+		}
+	}
+
+	if c.Checkpoint != "" && unsaved != 0 {
+		if saveErr := SaveJSON(c.Checkpoint, state); saveErr != nil && err == nil {
+			err = fmt.Errorf("SaveJSON: %w", saveErr)
+		}
+	}
+
+	return
+}
+
+// add adds the completed trial to the state.
+func (s *CampaignState) add(r trialResult) (err error) {
+
+	if err = s.Stats.Merge(r.stats); err != nil {
+		return fmt.Errorf("s.Stats.Merge: %w", err)
+	}
+
+	j := sort.SearchInts(s.Completed, r.i)
+	s.Completed = append(s.Completed, 0)
+	copy(s.Completed[j+1:], s.Completed[j:])
+	s.Completed[j] = r.i
+
+	s.Sum += r.prec
+	s.SumSquares += r.prec * r.prec
+
+	return
+}
+
+// run evaluates the i-th trial with the given seed and returns its statistics.
+func (w *campaignWorker) run(i int, seed int64, log2Scale float64) trialResult {
+
+	want, have, err := w.trial(NewTestRand(seed))
+	if err != nil {
+		return trialResult{i: i, err: err}
+	}
+
+	stats := NewStats()
+	stats.Add(ckks.GetPrecisionStats(w.params, w.ecd, nil, want, have, 0, false))
+	stats.AddSlots(want, have, log2Scale)
+
+	return trialResult{i: i, stats: stats, prec: stats.Slots[2].Mean()}
+}
+
+// meanHalfWidth returns the mean of n samples given their sum and sum of squares,
+// and the half-width z * std / sqrt(n) of its confidence interval.
+func meanHalfWidth(sum, sum2 float64, n int, z float64) (mean, halfWidth float64) {
+
+	nf := float64(n)
+	mean = sum / nf
+
+	if n < 2 {
+		return mean, math.Inf(1)
+	}
+
+	variance := max(sum2-nf*mean*mean, 0) / (nf - 1)
+
+	return mean, z * math.Sqrt(variance/nf)
+}
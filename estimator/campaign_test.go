@@ -0,0 +1,210 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func TestTrialSeed(t *testing.T) {
+
+	t.Run("KnownAnswer", func(t *testing.T) {
+		// First output of SplitMix64 with the state 0
+		if have, want := uint64(TrialSeed(0, 0)), uint64(0xe220a8397b1dcdaf); have != want {
+			t.Fatalf("TrialSeed(0, 0): %#x != %#x", have, want)
+		}
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+
+		seen := map[int64]bool{}
+
+		for _, seed := range []int64{0, 1, -1, 42} {
+			for i := -1; i < 1024; i++ {
+
+				s := TrialSeed(seed, i)
+
+				if seen[s] {
+					t.Fatalf("TrialSeed(%d, %d)=%d is not distinct", seed, i, s)
+				}
+
+				seen[s] = true
+			}
+		}
+	})
+
+	t.Run("NotIdentity", func(t *testing.T) {
+		for i := 0; i < 16; i++ {
+			if s := TrialSeed(int64(i), i); s == int64(i) || s == int64(i+1) {
+				t.Fatalf("TrialSeed(%d, %d)=%d is not mixed", i, i, s)
+			}
+		}
+	})
+}
+
+// testCampaign returns a campaign whose trials add to uniform values a Gaussian
+// error of log2 standard deviation uniform in [-24, -20]. If calls is not nil,
+// the fail-th evaluated trial returns an error, which interrupts the campaign.
+func testCampaign(t *testing.T, trials int, calls *atomic.Int64, fail int64) Campaign {
+
+	params := testParameters(t)
+
+	return Campaign{
+		Parameters: params,
+		NewTrial: func() (Trial, error) {
+			return func(source TestRand) (want, have []*bignum.Complex, err error) {
+
+				if calls != nil && calls.Add(1) == fail {
+					return nil, nil, fmt.Errorf("interrupted")
+				}
+
+				std := math.Exp2(source.Float64(-24, -20))
+
+				want = make([]*bignum.Complex, params.MaxSlots())
+				have = make([]*bignum.Complex, params.MaxSlots())
+
+				for i := range want {
+					re, im := source.Float64(-1, 1), source.Float64(-1, 1)
+					want[i] = bignum.ToComplex(complex(re, im), prec)
+					have[i] = bignum.ToComplex(complex(re+std*source.NormFloat64(), im+std*source.NormFloat64()), prec)
+				}
+
+				return
+			}, nil
+		},
+		Trials:  trials,
+		Workers: 4,
+		Seed:    1,
+	}
+}
+
+func TestCampaign(t *testing.T) {
+
+	t.Run("Run", func(t *testing.T) {
+
+		res, err := testCampaign(t, 32, nil, 0).Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Trials != 32 || res.Stopped || len(res.State.Completed) != 32 {
+			t.Fatalf("Trials=%d, Stopped=%t, len(Completed)=%d", res.Trials, res.Stopped, len(res.State.Completed))
+		}
+
+		if res.Stats.Slots[2].Count != uint64(32*testParameters(t).MaxSlots()) {
+			t.Fatalf("Slots[2].Count: %d != %d", res.Stats.Slots[2].Count, 32*testParameters(t).MaxSlots())
+		}
+
+		if res.Mean < 20 || res.Mean > 24 {
+			t.Fatalf("Mean: %f not in [20, 24]", res.Mean)
+		}
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+
+		c := testCampaign(t, 32, nil, 0)
+
+		res0, err := c.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.Workers = 1
+
+		res1, err := c.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkStatsEqual(t, res1.Stats, res0.Stats)
+	})
+
+	t.Run("EarlyStopping", func(t *testing.T) {
+
+		c := testCampaign(t, 1024, nil, 0)
+		c.HalfWidth = 0.5
+		c.MinTrials = 16
+
+		res, err := c.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !res.Stopped || res.Trials >= c.Trials || res.Trials < c.MinTrials {
+			t.Fatalf("Stopped=%t, Trials=%d", res.Stopped, res.Trials)
+		}
+
+		if res.HalfWidth > c.HalfWidth {
+			t.Fatalf("HalfWidth: %f > %f", res.HalfWidth, c.HalfWidth)
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+
+		want, err := testCampaign(t, 48, nil, 0).Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkpoint := filepath.Join(t.TempDir(), "state.json")
+
+		var calls atomic.Int64
+		c := testCampaign(t, 48, &calls, 20)
+		c.Checkpoint = checkpoint
+
+		if _, err = c.Run(); err == nil {
+			t.Fatal("interrupted campaign: no error")
+		}
+
+		var state CampaignState
+		if err = LoadJSON(checkpoint, &state); err != nil {
+			t.Fatal(err)
+		}
+
+		if n := len(state.Completed); n == 0 || n >= c.Trials {
+			t.Fatalf("len(Completed)=%d not in [1, %d)", n, c.Trials)
+		}
+
+		have, err := c.Resume(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if have.Trials != want.Trials {
+			t.Fatalf("Trials: %d != %d", have.Trials, want.Trials)
+		}
+
+		checkStatsEqual(t, have.Stats, want.Stats)
+	})
+}
+
+// checkStatsEqual checks that the statistics are equal, up to
+// the rounding errors of the summation in a different order.
+func checkStatsEqual(t *testing.T, have, want Stats) {
+
+	t.Helper()
+
+	if have.N != want.N {
+		t.Fatalf("N: %f != %f", have.N, want.N)
+	}
+
+	for _, stat := range AllStatistics {
+
+		h, _ := have.Get(stat)
+		w, _ := want.Get(stat)
+
+		for _, pair := range [][2]float64{{h.Real, w.Real}, {h.Imag, w.Imag}, {h.L2, w.L2}} {
+			if math.Abs(pair[0]-pair[1]) > 1e-9*math.Abs(pair[1]) {
+				t.Fatalf("%s: %v != %v", stat, h, w)
+			}
+		}
+	}
+
+	for i := range want.Slots {
+		checkHistogramEqual(t, have.Slots[i], want.Slots[i])
+	}
+}
@@ -0,0 +1,289 @@
+package estimator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// elementJSON is the JSON representation of an Element. The values are written in the
+// hexadecimal mantissa and binary exponent format of big.Float, which is exact, and are
+// read with the precision Prec, the largest precision of the values.
+type elementJSON struct {
+	Degree int
+	Level  int
+	Scale  rlwe.Scale
+	Prec   uint
+	Value  [3][][2]string
+	Range  *Range `json:",omitempty"`
+}
+
+// MarshalJSON returns the exact JSON representation of the element.
+func (p Element) MarshalJSON() ([]byte, error) {
+
+	aux := elementJSON{
+		Degree: p.Degree,
+		Level:  p.Level,
+		Scale:  p.Scale,
+		Range:  p.Range,
+	}
+
+	for i := range p.Value {
+
+		if p.Value[i] == nil {
+			continue
+		}
+
+		aux.Value[i] = make([][2]string, len(p.Value[i]))
+
+		for j, v := range p.Value[i] {
+			aux.Prec = max(aux.Prec, v[0].Prec(), v[1].Prec())
+			aux.Value[i][j] = [2]string{v[0].Text('p', 0), v[1].Text('p', 0)}
+		}
+	}
+
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON reads the JSON representation of an element.
+func (p *Element) UnmarshalJSON(data []byte) (err error) {
+
+	var aux elementJSON
+	if err = json.Unmarshal(data, &aux); err != nil {
+		return
+	}
+
+	if aux.Prec == 0 {
+		aux.Prec = prec
+	}
+
+	el := Element{
+		Degree: aux.Degree,
+		Level:  aux.Level,
+		Scale:  aux.Scale,
+		Range:  aux.Range,
+	}
+
+	for i := range aux.Value {
+
+		if aux.Value[i] == nil {
+			continue
+		}
+
+		el.Value[i] = make([]*bignum.Complex, len(aux.Value[i]))
+
+		for j, v := range aux.Value[i] {
+
+			c := &bignum.Complex{new(big.Float).SetPrec(aux.Prec), new(big.Float).SetPrec(aux.Prec)}
+
+			for k := range v {
+				if _, _, err = c[k].Parse(v[k], 0); err != nil {
+					return fmt.Errorf("Value[%d][%d]: %w", i, j, err)
+				}
+			}
+
+			el.Value[i][j] = c
+		}
+	}
+
+	*p = el
+
+	return
+}
+
+// ErrSecretMismatch is wrapped by the errors returned when elements or a campaign
+// state saved with the secret of an estimator are loaded with another secret.
+var ErrSecretMismatch = errors.New("secret mismatch")
+
+// SecretID identifies the secret of an estimator, with which the values of
+// its elements of degree 1 and 2 are decrypted and key-switched.
+type SecretID struct {
+	// Seed is the SecretSeed of the estimator, from which NewEstimatorFromSeed samples
+	// the same secret, or nil if the secret was sampled from the time.
+	Seed *int64 `json:",omitempty"`
+	// Fingerprint is the hexadecimal SHA-256 hash of the secret.
+	Fingerprint string
+}
+
+// SecretID returns the SecretID of the secret of the estimator.
+func (e Estimator) SecretID() SecretID {
+
+	h := sha256.New()
+	for _, v := range e.Sk[0] {
+		fmt.Fprintf(h, "%s,%s;", v[0].Text('p', 0), v[1].Text('p', 0))
+	}
+
+	return SecretID{Seed: e.SecretSeed, Fingerprint: hex.EncodeToString(h.Sum(nil))}
+}
+
+// check returns an error wrapping ErrSecretMismatch if id and other identify different secrets.
+func (id SecretID) check(other SecretID) (err error) {
+
+	if id.Fingerprint == other.Fingerprint {
+		return
+	}
+
+	seed := func(id SecretID) string {
+		if id.Seed == nil {
+			return "unseeded"
+		}
+		return fmt.Sprintf("seed %d", *id.Seed)
+	}
+
+	return fmt.Errorf("%w: saved with the secret %.16s (%s), loaded with the secret %.16s (%s)", ErrSecretMismatch, id.Fingerprint, seed(id), other.Fingerprint, seed(other))
+}
+
+// elementsFile is the content of a file written by Estimator.SaveElements.
+type elementsFile struct {
+	Secret   SecretID
+	Elements []*Element
+}
+
+// SaveElements writes the elements and the SecretID of the estimator to the file at path (see SaveJSON).
+func (e Estimator) SaveElements(path string, els ...*Element) (err error) {
+	return SaveJSON(path, elementsFile{Secret: e.SecretID(), Elements: els})
+}
+
+// LoadElements reads the elements written by SaveElements to the file at path. It returns an error
+// wrapping ErrSecretMismatch if they were saved by an estimator with another secret, e.g. one not
+// created by NewEstimatorFromSeed with the same parameters and seed.
+func (e Estimator) LoadElements(path string) (els []*Element, err error) {
+
+	var f elementsFile
+	if err = LoadJSON(path, &f); err != nil {
+		return
+	}
+
+	if err = f.Secret.check(e.SecretID()); err != nil {
+		return nil, err
+	}
+
+	return f.Elements, nil
+}
+
+// MarshalJSON returns the JSON representation of the histogram.
+// The Min and Max of an empty histogram, which are infinite, are written as 0.
+func (h Histogram) MarshalJSON() ([]byte, error) {
+
+	type histogram Histogram
+
+	if h.Count == 0 {
+		h.Min, h.Max = 0, 0
+	}
+
+	return json.Marshal(histogram(h))
+}
+
+// UnmarshalJSON reads the JSON representation of a histogram.
+func (h *Histogram) UnmarshalJSON(data []byte) (err error) {
+
+	type histogram Histogram
+
+	var aux histogram
+	if err = json.Unmarshal(data, &aux); err != nil {
+		return
+	}
+
+	if aux.Counts == nil {
+		aux.Counts = map[int]uint64{}
+	}
+
+	if aux.Count == 0 {
+		aux.Min, aux.Max = math.Inf(1), math.Inf(-1)
+	}
+
+	*h = Histogram(aux)
+
+	return
+}
+
+// CampaignState is the state of a Campaign, from which it can be resumed.
+type CampaignState struct {
+	// Seed is the seed of the campaign.
+	Seed int64
+
+	// Secret identifies the secret of the estimators of the completed trials (see Campaign.Secret).
+	Secret SecretID
+
+	// Completed are the indices of the completed trials, in increasing order.
+	Completed []int
+
+	// Stats are the statistics of the completed trials, which are not finalized.
+	Stats Stats
+
+	// Sum and SumSquares are the sum and the sum of squares of
+	// the AVG L2 log2 precision of the slots of the completed trials.
+	Sum, SumSquares float64
+}
+
+// NewCampaignState returns the state of a campaign with the given seed and no completed trials.
+func NewCampaignState(seed int64) CampaignState {
+	return CampaignState{
+		Seed:  seed,
+		Stats: NewStats(),
+	}
+}
+
+// SaveJSON writes the JSON representation of v, e.g. an Element or a CampaignState, to the file
+// at path. The file is first written to a temporary file which is then renamed, so that the
+// previous content of path is not lost if the write is interrupted.
+func SaveJSON(path string, v interface{}) (err error) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+
+	if err = f.Chmod(0o644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("f.Chmod: %w", err)
+	}
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("f.Write: %w", err)
+	}
+
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("f.Close: %w", err)
+	}
+
+	if err = os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return
+}
+
+// LoadJSON reads the JSON representation of v from the file at path.
+func LoadJSON(path string, v interface{}) (err error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return
+}
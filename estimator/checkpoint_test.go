@@ -0,0 +1,119 @@
+package estimator
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func testParameters(t *testing.T) ckks.Parameters {
+
+	t.Helper()
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            10,
+		LogQ:            []int{55, 45},
+		LogP:            []int{61},
+		LogDefaultScale: 45,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return params
+}
+
+func TestElementJSON(t *testing.T) {
+
+	params := testParameters(t)
+
+	est := NewEstimatorFromSeed(params, 1)
+	ecd := ckks.NewEncoder(params)
+
+	_, el, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, NewTestRand(1))
+
+	el.Degree = 2
+	el.Range = &Range{Real: Interval{Min: -1, Max: 1}, Imag: Interval{Min: -1, Max: 1}, Noise: 1}
+
+	data, err := json.Marshal(el)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var have Element
+	if err = json.Unmarshal(data, &have); err != nil {
+		t.Fatal(err)
+	}
+
+	checkElementEqual(t, &have, el)
+}
+
+func TestSaveLoadElements(t *testing.T) {
+
+	params := testParameters(t)
+
+	est := NewEstimatorFromSeed(params, 1)
+	ecd := ckks.NewEncoder(params)
+
+	_, el0, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, NewTestRand(1))
+	_, el1, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, NewTestRand(2))
+
+	path := filepath.Join(t.TempDir(), "elements.json")
+
+	if err := est.SaveElements(path, el0, el1); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("SameSecret", func(t *testing.T) {
+
+		els, err := NewEstimatorFromSeed(params, 1).LoadElements(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(els) != 2 {
+			t.Fatalf("len(els): %d != 2", len(els))
+		}
+
+		checkElementEqual(t, els[0], el0)
+		checkElementEqual(t, els[1], el1)
+	})
+
+	t.Run("OtherSecret", func(t *testing.T) {
+		if _, err := NewEstimatorFromSeed(params, 2).LoadElements(path); !errors.Is(err, ErrSecretMismatch) {
+			t.Fatalf("LoadElements with another secret: %v is not ErrSecretMismatch", err)
+		}
+	})
+}
+
+func checkElementEqual(t *testing.T, have, want *Element) {
+
+	t.Helper()
+
+	if have.Degree != want.Degree || have.Level != want.Level || have.Scale.Cmp(want.Scale) != 0 {
+		t.Fatalf("Degree, Level, Scale: (%d, %d, %v) != (%d, %d, %v)", have.Degree, have.Level, have.Scale, want.Degree, want.Level, want.Scale)
+	}
+
+	if (have.Range == nil) != (want.Range == nil) || (have.Range != nil && *have.Range != *want.Range) {
+		t.Fatalf("Range: %v != %v", have.Range, want.Range)
+	}
+
+	for i := range want.Value {
+
+		if len(have.Value[i]) != len(want.Value[i]) {
+			t.Fatalf("len(Value[%d]): %d != %d", i, len(have.Value[i]), len(want.Value[i]))
+		}
+
+		for j := range want.Value[i] {
+			for k := 0; k < 2; k++ {
+				if have.Value[i][j][k].Cmp(want.Value[i][j][k]) != 0 {
+					t.Fatalf("Value[%d][%d][%d]: %v != %v", i, j, k, have.Value[i][j][k], want.Value[i][j][k])
+				}
+			}
+		}
+	}
+}
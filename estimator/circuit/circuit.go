@@ -0,0 +1,388 @@
+// Package circuit implements a declarative description of CKKS circuits, written in JSON or YAML,
+// and a runner evaluating them with the estimator and, optionally, with Lattigo on the same inputs.
+//
+// A circuit declares its parameters, its inputs, a sequence of operations on named values and
+// its outputs. For example:
+//
+//	{
+//	  "Name": "mul_rotate",
+//	  "Parameters": {"LogN": 14, "LogQ": [55, 45], "LogP": [61], "LogDefaultScale": 45},
+//	  "Inputs": [{"Name": "x"}, {"Name": "y", "Min": -2, "Max": 2, "Real": true}],
+//	  "Operations": [
+//	    {"Op": "mul_relin", "Inputs": ["x", "y"], "Output": "z"},
+//	    {"Op": "rescale", "Inputs": ["z"]},
+//	    {"Op": "rotate", "Inputs": ["z"], "K": 5}
+//	  ],
+//	  "Outputs": ["z"]
+//	}
+package circuit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// Operations of a circuit.
+const (
+	// Add adds the two inputs, or the input and the constant.
+	Add = "add"
+	// Sub subtracts the second input, or the constant, from the first input.
+	Sub = "sub"
+	// Mul multiplies the two inputs, or the input and the constant, without relinearization.
+	Mul = "mul"
+	// MulRelin multiplies the two inputs and relinearizes the result.
+	MulRelin = "mul_relin"
+	// Relinearize relinearizes the input.
+	Relinearize = "relinearize"
+	// Rescale rescales the input.
+	Rescale = "rescale"
+	// Rotate rotates the input by K slots to the left.
+	Rotate = "rotate"
+	// Conjugate conjugates the input.
+	Conjugate = "conjugate"
+	// Polynomial evaluates a polynomial on the input.
+	Polynomial = "polynomial"
+	// LinearTransformation evaluates a linear transformation on the input.
+	LinearTransformation = "linear_transformation"
+	// Bootstrap bootstraps the input with the Bootstrapping parameters of the circuit.
+	Bootstrap = "bootstrap"
+)
+
+// Encryptions of the inputs.
+const (
+	// PublicKey encrypts the input with the public key (default).
+	PublicKey = "pk"
+	// SecretKey encrypts the input with the secret key.
+	SecretKey = "sk"
+	// Plaintext only encodes the input, which can then only be the second input of add, sub and mul.
+	Plaintext = "plaintext"
+)
+
+// Circuit is the description of a circuit.
+type Circuit struct {
+	Name string
+
+	// Parameters are the parameters of the circuit.
+	Parameters ckks.ParametersLiteral
+
+	// Bootstrapping are the parameters of the bootstrapping, required by the bootstrap operation.
+	// Their LogN and secret distribution default to the ones of Parameters.
+	Bootstrapping *bootstrapping.ParametersLiteral
+
+	Inputs     []Input
+	Operations []Operation
+
+	// Outputs are the names of the values whose precision is reported.
+	Outputs []string
+}
+
+// Input is an input of a circuit, whose slots are sampled uniformly in [Min, Max] + i[Min, Max]
+// (or [Min, Max] if Real is true). Min and Max default to -1 and 1.
+type Input struct {
+	Name       string
+	Min, Max   float64
+	Real       bool
+	Encryption string
+
+	// Scale is the scale of the encoding (default: the default scale of the parameters).
+	Scale float64 `json:",omitempty"`
+}
+
+// Operation is an operation of a circuit. It reads the values Inputs and writes the value
+// Output, which defaults to the first input.
+type Operation struct {
+	Op     string
+	Inputs []string
+	Output string
+
+	// Constant is the second operand of add, sub and mul, given as [real] or [real, imag].
+	Constant []float64
+
+	// K is the rotation of rotate.
+	K int
+
+	Polynomial           *PolynomialLiteral
+	LinearTransformation *LinearTransformationLiteral
+
+	// Scale is the scale of the constant of mul or of the diagonals of linear_transformation
+	// (default: the prime consumed by the next rescaling), or the target scale of the output
+	// of polynomial (default: the scale of its input). See PlanScales.
+	Scale float64 `json:",omitempty"`
+}
+
+// PolynomialLiteral describes a polynomial, either by its Coefficients in the given Basis
+// ("monomial" or "chebyshev") or as the Chebyshev approximation of degree Degree of the
+// Function ("sigmoid", "exp", "tanh", "sin" or "cos") in the Interval.
+type PolynomialLiteral struct {
+	Basis        string
+	Coefficients []float64
+	Function     string
+	Degree       int
+	Interval     [2]float64
+}
+
+// LinearTransformationLiteral describes a linear transformation by its non-zero diagonals,
+// indexed by their rotation. A diagonal shorter than the number of slots is repeated.
+type LinearTransformationLiteral struct {
+	Diagonals    map[int][]float64
+	LogBSGSRatio int
+}
+
+// Load reads the circuit from a JSON file, or from a YAML file if its extension is .yaml or .yml.
+func Load(path string) (c Circuit, err error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = c.UnmarshalYAML(data)
+	default:
+		err = c.UnmarshalJSON(data)
+	}
+
+	return
+}
+
+// UnmarshalJSON reads the JSON representation of a circuit and validates it.
+func (c *Circuit) UnmarshalJSON(data []byte) (err error) {
+
+	type circuit Circuit
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cc circuit
+	if err = dec.Decode(&cc); err != nil {
+		return fmt.Errorf("json.Decode: %w", err)
+	}
+
+	if err = Circuit(cc).Validate(); err != nil {
+		return fmt.Errorf("invalid circuit: %w", err)
+	}
+
+	*c = Circuit(cc)
+
+	return
+}
+
+// UnmarshalYAML reads the YAML representation of a circuit, which follows the JSON
+// representation, and validates it.
+func (c *Circuit) UnmarshalYAML(data []byte) (err error) {
+
+	var v interface{}
+	if err = yaml.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	if data, err = json.Marshal(yamlToJSON(v)); err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	return c.UnmarshalJSON(data)
+}
+
+// yamlToJSON converts the maps with non-string keys decoded by YAML into maps with string keys.
+func yamlToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = yamlToJSON(x)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprint(k)] = yamlToJSON(x)
+		}
+		return m
+	case []interface{}:
+		for i, x := range v {
+			v[i] = yamlToJSON(x)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Validate checks that the operations only read values that are defined,
+// that their arguments are consistent and that the outputs are defined.
+func (c Circuit) Validate() (err error) {
+
+	defined := map[string]bool{}
+	plaintext := map[string]bool{}
+
+	for _, in := range c.Inputs {
+
+		if in.Name == "" {
+			return fmt.Errorf("input without name")
+		}
+
+		if defined[in.Name] {
+			return fmt.Errorf("input %q defined twice", in.Name)
+		}
+
+		if in.Scale < 0 {
+			return fmt.Errorf("input %q: invalid Scale: %f < 0", in.Name, in.Scale)
+		}
+
+		switch in.Encryption {
+		case "", PublicKey, SecretKey:
+		case Plaintext:
+			plaintext[in.Name] = true
+		default:
+			return fmt.Errorf("input %q: invalid encryption %q", in.Name, in.Encryption)
+		}
+
+		defined[in.Name] = true
+	}
+
+	for i, op := range c.Operations {
+
+		if err = op.validate(defined, plaintext); err != nil {
+			return fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+
+		if op.Op == Bootstrap && c.Bootstrapping == nil {
+			return fmt.Errorf("operation %d (%s): missing Bootstrapping parameters", i, op.Op)
+		}
+
+		defined[op.output()] = true
+		delete(plaintext, op.output())
+	}
+
+	if len(c.Outputs) == 0 {
+		return fmt.Errorf("no outputs")
+	}
+
+	for _, out := range c.Outputs {
+		if !defined[out] {
+			return fmt.Errorf("output %q is not defined", out)
+		}
+		if plaintext[out] {
+			return fmt.Errorf("output %q is a plaintext", out)
+		}
+	}
+
+	return
+}
+
+func (op Operation) validate(defined, plaintext map[string]bool) (err error) {
+
+	for _, in := range op.Inputs {
+		if !defined[in] {
+			return fmt.Errorf("input %q is not defined", in)
+		}
+	}
+
+	binary := false
+	unary := false
+
+	switch op.Op {
+	case Add, Sub, Mul:
+		binary = len(op.Constant) == 0
+		unary = !binary
+		if len(op.Constant) > 2 {
+			return fmt.Errorf("invalid constant: len(Constant)=%d > 2", len(op.Constant))
+		}
+	case MulRelin:
+		binary = true
+	case Relinearize, Rescale, Rotate, Conjugate, Bootstrap:
+		unary = true
+	case Polynomial:
+		unary = true
+		if op.Polynomial == nil {
+			return fmt.Errorf("missing Polynomial")
+		}
+		if err = op.Polynomial.validate(); err != nil {
+			return fmt.Errorf("invalid Polynomial: %w", err)
+		}
+	case LinearTransformation:
+		unary = true
+		if op.LinearTransformation == nil || len(op.LinearTransformation.Diagonals) == 0 {
+			return fmt.Errorf("missing LinearTransformation")
+		}
+	default:
+		return fmt.Errorf("invalid operation")
+	}
+
+	if op.Scale < 0 {
+		return fmt.Errorf("invalid Scale: %f < 0", op.Scale)
+	}
+
+	if op.Scale != 0 && !(op.Op == Mul && unary) && op.Op != Polynomial && op.Op != LinearTransformation {
+		return fmt.Errorf("Scale is only supported by mul with a constant, polynomial and linear_transformation")
+	}
+
+	switch {
+	case binary && len(op.Inputs) != 2:
+		return fmt.Errorf("expects 2 inputs but has %d", len(op.Inputs))
+	case unary && len(op.Inputs) != 1:
+		return fmt.Errorf("expects 1 input but has %d", len(op.Inputs))
+	}
+
+	if plaintext[op.Inputs[0]] {
+		return fmt.Errorf("first input %q is a plaintext", op.Inputs[0])
+	}
+
+	if binary && plaintext[op.Inputs[1]] && op.Op == MulRelin {
+		return fmt.Errorf("second input %q is a plaintext", op.Inputs[1])
+	}
+
+	return
+}
+
+func (op Operation) output() string {
+	if op.Output != "" {
+		return op.Output
+	}
+	return op.Inputs[0]
+}
+
+func (p PolynomialLiteral) validate() error {
+
+	if p.Function != "" {
+
+		if _, ok := functions[p.Function]; !ok {
+			return fmt.Errorf("invalid Function %q", p.Function)
+		}
+
+		if p.Degree < 1 {
+			return fmt.Errorf("invalid Degree: %d < 1", p.Degree)
+		}
+
+		if p.Interval[0] >= p.Interval[1] {
+			return fmt.Errorf("invalid Interval: %v", p.Interval)
+		}
+
+		return nil
+	}
+
+	if len(p.Coefficients) == 0 {
+		return fmt.Errorf("missing Coefficients or Function")
+	}
+
+	switch p.Basis {
+	case "", "monomial":
+	case "chebyshev":
+		if p.Interval[0] >= p.Interval[1] {
+			return fmt.Errorf("invalid Interval: %v", p.Interval)
+		}
+	default:
+		return fmt.Errorf("invalid Basis %q", p.Basis)
+	}
+
+	return nil
+}
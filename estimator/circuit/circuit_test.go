@@ -0,0 +1,75 @@
+package circuit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+
+	t.Run("Examples", func(t *testing.T) {
+		for _, path := range []string{"examples/mul_rotate.json", "examples/sigmoid.yaml"} {
+
+			c, err := Load(path)
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+
+			if err = c.Validate(); err != nil {
+				t.Errorf("%s: %v", path, err)
+			}
+		}
+	})
+
+	valid := func() Circuit {
+		return Circuit{
+			Inputs: []Input{{Name: "x"}, {Name: "y"}, {Name: "w", Encryption: Plaintext}},
+			Operations: []Operation{
+				{Op: MulRelin, Inputs: []string{"x", "y"}, Output: "z"},
+				{Op: Rescale, Inputs: []string{"z"}},
+				{Op: Add, Inputs: []string{"z", "w"}},
+			},
+			Outputs: []string{"z"},
+		}
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid circuit: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(c *Circuit)
+		err    string
+	}{
+		{"InputWithoutName", func(c *Circuit) { c.Inputs[0].Name = "" }, "without name"},
+		{"DuplicateInput", func(c *Circuit) { c.Inputs[1].Name = "x" }, "defined twice"},
+		{"NegativeScale", func(c *Circuit) { c.Inputs[0].Scale = -1 }, "invalid Scale"},
+		{"InvalidEncryption", func(c *Circuit) { c.Inputs[0].Encryption = "xx" }, "invalid encryption"},
+		{"UndefinedInput", func(c *Circuit) { c.Operations[0].Inputs[1] = "u" }, "is not defined"},
+		{"InvalidOperation", func(c *Circuit) { c.Operations[1].Op = "xx" }, "invalid operation"},
+		{"Arity", func(c *Circuit) { c.Operations[0].Inputs = []string{"x"} }, "expects 2 inputs"},
+		{"BootstrapWithoutParameters", func(c *Circuit) {
+			c.Operations = append(c.Operations, Operation{Op: Bootstrap, Inputs: []string{"z"}})
+		}, "missing Bootstrapping"},
+		{"NoOutputs", func(c *Circuit) { c.Outputs = nil }, "no outputs"},
+		{"UndefinedOutput", func(c *Circuit) { c.Outputs = []string{"u"} }, "is not defined"},
+		{"PlaintextOutput", func(c *Circuit) { c.Outputs = []string{"w"} }, "is a plaintext"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+
+			c := valid()
+			tc.modify(&c)
+
+			err := c.Validate()
+
+			if err == nil {
+				t.Fatalf("no error, expected %q", tc.err)
+			}
+
+			if !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("error %q does not contain %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package circuit
+
+import (
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+)
+
+// TestBootstrappingKeys checks that the keys recorded by the bootstrappings of a circuit
+// are the keys generated by Lattigo, at levels no greater than theirs.
+func TestBootstrappingKeys(t *testing.T) {
+
+	for _, tc := range []struct {
+		name string
+		ring ring.Type
+		logN int
+	}{
+		// The bootstrapping switches from N1 = 2^10 to N2 = 2^11 and to a sparse secret.
+		{"RingSwitching", ring.Standard, 10},
+		// The bootstrapping switches from the conjugate invariant ring of degree 2^10.
+		{"ConjugateInvariant", ring.ConjugateInvariant, 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+
+			// The primes are NTT-friendly in the ring of degree 2^11 of the bootstrapping.
+			primes, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+				LogN:            11,
+				LogQ:            []int{60, 40},
+				LogP:            []int{61},
+				LogDefaultScale: 40,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := Circuit{
+				Parameters: ckks.ParametersLiteral{
+					LogN:            tc.logN,
+					Q:               primes.Q(),
+					P:               primes.P(),
+					LogDefaultScale: 40,
+					RingType:        tc.ring,
+				},
+				Bootstrapping: &bootstrapping.ParametersLiteral{
+					LogN: utils.Pointy(11),
+				},
+				Inputs:     []Input{{Name: "x", Real: tc.ring == ring.ConjugateInvariant}},
+				Operations: []Operation{{Op: Bootstrap, Inputs: []string{"x"}}},
+				Outputs:    []string{"x"},
+			}
+
+			opts := Options{Seed: 1, BootstrappingKeys: estimator.NewKeyRequirements()}
+
+			if _, err = Run(c, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			keys := opts.BootstrappingKeys.Keys()
+
+			params, err := ckks.NewParametersFromLiteral(c.Parameters)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			btpLit := *c.Bootstrapping
+			btpLit.Xs = params.Xs()
+
+			btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpLit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			evk, _, err := btpParams.GenEvaluationKeys(rlwe.NewKeyGenerator(params).GenSecretKeyNew())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, lattigo := range map[estimator.SwitchingKey]*rlwe.EvaluationKey{
+				estimator.EvkN1ToN2:        evk.EvkN1ToN2,
+				estimator.EvkN2ToN1:        evk.EvkN2ToN1,
+				estimator.EvkRealToCmplx:   evk.EvkRealToCmplx,
+				estimator.EvkCmplxToReal:   evk.EvkCmplxToReal,
+				estimator.EvkDenseToSparse: evk.EvkDenseToSparse,
+				estimator.EvkSparseToDense: evk.EvkSparseToDense,
+			} {
+
+				l, ok := keys.SwitchingLevels[key]
+
+				if ok != (lattigo != nil) {
+					t.Errorf("%s: recorded=%t, generated by Lattigo=%t", key, ok, lattigo != nil)
+					continue
+				}
+
+				if ok && (l.LevelQ > lattigo.LevelQ() || l.LevelP != lattigo.LevelP()) {
+					t.Errorf("%s: LevelQ=%d, LevelP=%d, Lattigo: LevelQ=%d, LevelP=%d", key, l.LevelQ, l.LevelP, lattigo.LevelQ(), lattigo.LevelP())
+				}
+			}
+
+			if keys.Relinearization() != (evk.RelinearizationKey != nil) {
+				t.Errorf("relinearization: recorded=%t, generated by Lattigo=%t", keys.Relinearization(), evk.RelinearizationKey != nil)
+			}
+
+			galEls := evk.GetGaloisKeysList()
+
+			if have, want := keys.GaloisElements(), utils.GetSortedKeys(evk.GaloisKeys); len(have) != len(want) {
+				t.Errorf("Galois elements: %v != %v", have, want)
+			}
+
+			for _, g := range galEls {
+
+				l, ok := keys.GaloisLevels[g]
+				if !ok {
+					t.Errorf("Galois element %d: not recorded", g)
+					continue
+				}
+
+				if gk := evk.GaloisKeys[g]; l > gk.LevelQ() {
+					t.Errorf("Galois element %d: level %d > %d", g, l, gk.LevelQ())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,805 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/lintrans"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/polynomial"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Options are the options of Run.
+type Options struct {
+	// Trials is the number of evaluations of the circuit (default 1).
+	Trials int
+	// Seed is the seed of the inputs and of the secrets and noise of the estimator.
+	Seed int64
+	// Lattigo also evaluates the circuit with Lattigo on the same inputs.
+	Lattigo bool
+	// Heuristic selects the noise model of the estimator (see estimator.Estimator).
+	Heuristic bool
+	// Trace, if not nil, is called after each operation with the level of
+	// its output and the values expected and predicted by the estimator.
+	Trace func(i int, op Operation, level int, want, predicted []*bignum.Complex)
+	// Ranges, if not nil, propagates the ranges of the values, starting from the intervals
+	// [Min, Max] of the inputs, and records their warnings (see estimator.RangeAnalysis).
+	Ranges *estimator.RangeAnalysis
+	// Overflow, if not nil, checks the values for wraparounds of
+	// the modulus (see estimator.OverflowCheck).
+	Overflow *estimator.OverflowCheck
+	// Strict checks the consistency of the operands of the operations
+	// of the estimator (see estimator.Estimator.Strict).
+	Strict bool
+	// Counter, if not nil, counts the operations of the estimator over all
+	// trials, without the operations of the bootstrappings, which are counted
+	// as one estimator.OpBootstrap each (see estimator.OperationCounter).
+	Counter *estimator.OperationCounter
+	// Keys, if not nil, records the evaluation keys of the residual parameters
+	// used by the circuit (see estimator.KeyRequirements).
+	Keys *estimator.KeyRequirements
+	// BootstrappingKeys, if not nil, records the evaluation keys of the bootstrapping
+	// parameters used by the bootstrappings, including the keys switching between the
+	// rings and the secrets of the bootstrapping.
+	BootstrappingKeys *estimator.KeyRequirements
+	// Progress, if not nil, is called with the progress of the trials, of the operations
+	// of the circuit and of the stages of the long-running operations of the estimator
+	// (see estimator.Progress).
+	Progress func(p estimator.Progress)
+}
+
+// functions are the functions that can be approximated by a polynomial.
+var functions = map[string]func(x float64) float64{
+	"sigmoid": func(x float64) float64 { return 1 / (math.Exp(-x) + 1) },
+	"exp":     math.Exp,
+	"tanh":    math.Tanh,
+	"sin":     math.Sin,
+	"cos":     math.Cos,
+}
+
+// value is a named value of the circuit: the expected values, the element of the
+// estimator and the ciphertext (or plaintext) of Lattigo.
+type value struct {
+	want []*bignum.Complex
+	el   *estimator.Element
+	ct   *rlwe.Ciphertext
+	pt   *rlwe.Plaintext
+}
+
+// runner evaluates a circuit.
+type runner struct {
+	Circuit
+	Options
+
+	params ckks.Parameters
+	ecd    *ckks.Encoder
+	est    estimator.Estimator
+	source estimator.TestRand
+
+	btpEst *bootEst.Evaluator
+
+	polys map[int]polynomial.Polynomial
+	lts   map[int]lintrans.Diagonals[*bignum.Complex]
+
+	// Lattigo
+	kgen     *rlwe.KeyGenerator
+	sk       *rlwe.SecretKey
+	pk       *rlwe.PublicKey
+	dec      *rlwe.Decryptor
+	evk      *rlwe.MemEvaluationKeySet
+	eval     *ckks.Evaluator
+	polyEval *polynomial.Evaluator
+	ltEval   *lintrans.Evaluator
+	btpEval  *bootstrapping.Evaluator
+}
+
+// Run evaluates the circuit Options.Trials times and returns the results of its outputs,
+// in the order of Circuit.Outputs. The results are named <Circuit.Name>/<output>.
+// The Actual statistics of the results are only set if Options.Lattigo is true.
+func Run(c Circuit, opts Options) (results []estimator.Result, err error) {
+	return RunContext(context.Background(), c, opts)
+}
+
+// RunContext is as Run, but stops the evaluation of the estimator if ctx is cancelled. The
+// operations of Lattigo are not interrupted, but no operation is started after the cancellation.
+func RunContext(ctx context.Context, c Circuit, opts Options) (results []estimator.Result, err error) {
+
+	if err = c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid circuit: %w", err)
+	}
+
+	r, err := newRunner(ctx, c, opts)
+	if err != nil {
+		return
+	}
+
+	statsWant := make([]estimator.Stats, len(c.Outputs))
+	statsHave := make([]estimator.Stats, len(c.Outputs))
+	for i := range c.Outputs {
+		statsWant[i] = estimator.NewStats()
+		statsHave[i] = estimator.NewStats()
+	}
+
+	for trial := 0; trial < max(1, opts.Trials); trial++ {
+
+		var values map[string]*value
+		if values, err = r.evaluate(); err != nil {
+			return nil, fmt.Errorf("trial %d: %w", trial, err)
+		}
+
+		for i, name := range c.Outputs {
+
+			v := values[name]
+
+			statsWant[i].Add(ckks.GetPrecisionStats(r.params, r.ecd, nil, v.want, r.est.Decrypt(v.el), 0, false))
+
+			if opts.Lattigo {
+				statsHave[i].Add(ckks.GetPrecisionStats(r.params, r.ecd, r.dec, v.want, v.ct, 0, false))
+			}
+		}
+
+		if err = r.est.Checkpoint("trials", trial+1, max(1, opts.Trials)); err != nil {
+			return nil, err
+		}
+	}
+
+	results = make([]estimator.Result, len(c.Outputs))
+
+	for i, name := range c.Outputs {
+
+		statsWant[i].Finalize()
+
+		if opts.Lattigo {
+			statsHave[i].Finalize()
+		} else {
+			statsHave[i] = estimator.Stats{}
+		}
+
+		results[i] = estimator.NewResult(c.Name+"/"+name, r.params, opts.Seed, statsWant[i], statsHave[i])
+		results[i].Trials = max(1, opts.Trials)
+	}
+
+	return
+}
+
+func newRunner(ctx context.Context, c Circuit, opts Options) (r *runner, err error) {
+
+	r = &runner{
+		Circuit: c,
+		Options: opts,
+		polys:   map[int]polynomial.Polynomial{},
+		lts:     map[int]lintrans.Diagonals[*bignum.Complex]{},
+	}
+
+	if r.params, err = ckks.NewParametersFromLiteral(c.Parameters); err != nil {
+		return nil, fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+	}
+
+	r.ecd = ckks.NewEncoder(r.params)
+	r.source = estimator.NewTestRand(opts.Seed)
+
+	var btpParams bootstrapping.Parameters
+
+	if c.Bootstrapping != nil {
+
+		btpLit := *c.Bootstrapping
+
+		if btpLit.LogN == nil {
+			btpLit.LogN = utils.Pointy(r.params.LogN())
+		}
+
+		if btpLit.Xs == nil {
+			btpLit.Xs = r.params.Xs()
+		}
+
+		if btpParams, err = bootstrapping.NewParametersFromLiteral(r.params, btpLit); err != nil {
+			return nil, fmt.Errorf("bootstrapping.NewParametersFromLiteral: %w", err)
+		}
+
+		btpEst := bootEst.NewEvaluatorFromSeed(btpParams, opts.Seed)
+		btpEst.ResidualParameters.Rand = r.source.Rand
+		btpEst.BootstrappingParameters.Rand = r.source.Rand
+		btpEst.ResidualParameters.Heuristic = opts.Heuristic
+		btpEst.BootstrappingParameters.Heuristic = opts.Heuristic
+		btpEst.ResidualParameters.Ranges = opts.Ranges
+		btpEst.BootstrappingParameters.Ranges = opts.Ranges
+		btpEst.ResidualParameters.Overflow = opts.Overflow
+		btpEst.BootstrappingParameters.Overflow = opts.Overflow
+		btpEst.ResidualParameters.Strict = opts.Strict
+		btpEst.BootstrappingParameters.Strict = opts.Strict
+		btpEst.ResidualParameters.Progress = opts.Progress
+		btpEst.BootstrappingParameters.Progress = opts.Progress
+		btpEst.ResidualParameters.Counter = opts.Counter
+		btpEst.BootstrappingParameters.Counter = opts.Counter
+		btpEst.ResidualParameters.Keys = opts.Keys
+		btpEst.BootstrappingParameters.Keys = opts.BootstrappingKeys
+		btpEst = btpEst.WithContext(ctx)
+
+		r.btpEst = &btpEst
+		r.est = btpEst.ResidualParameters
+
+	} else {
+		r.est = estimator.NewEstimatorFromSeed(r.params, opts.Seed)
+		r.est.Rand = r.source.Rand
+		r.est.Heuristic = opts.Heuristic
+		r.est.Ranges = opts.Ranges
+		r.est.Overflow = opts.Overflow
+		r.est.Strict = opts.Strict
+		r.est.Progress = opts.Progress
+		r.est.Counter = opts.Counter
+		r.est.Keys = opts.Keys
+		r.est = r.est.WithContext(ctx)
+	}
+
+	for i, op := range c.Operations {
+		switch op.Op {
+		case Polynomial:
+			r.polys[i] = op.Polynomial.polynomial()
+		case LinearTransformation:
+			r.lts[i] = op.LinearTransformation.diagonals(r.params.MaxSlots())
+		}
+	}
+
+	if !opts.Lattigo {
+		return
+	}
+
+	r.kgen = rlwe.NewKeyGenerator(r.params)
+	r.sk, r.pk = r.kgen.GenKeyPairNew()
+	r.dec = rlwe.NewDecryptor(r.params, r.sk)
+	r.evk = rlwe.NewMemEvaluationKeySet(nil)
+
+	for _, op := range c.Operations {
+		switch op.Op {
+		case MulRelin, Relinearize, Polynomial:
+			if r.evk.RelinearizationKey == nil {
+				r.evk.RelinearizationKey = r.kgen.GenRelinearizationKeyNew(r.sk)
+			}
+		case Rotate:
+			r.genGaloisKeys(r.params.GaloisElement(op.K))
+		case Conjugate:
+			r.genGaloisKeys(r.params.GaloisElementForComplexConjugation())
+		case LinearTransformation:
+			r.genGaloisKeys(lintrans.GaloisElements(r.params, r.lintransParameters(op, r.params.MaxLevel()))...)
+		}
+	}
+
+	r.eval = ckks.NewEvaluator(r.params, r.evk)
+	r.polyEval = polynomial.NewEvaluator(r.params, r.eval)
+	r.ltEval = lintrans.NewEvaluator(r.eval)
+
+	if c.Bootstrapping != nil {
+
+		var evk *bootstrapping.EvaluationKeys
+		if evk, _, err = btpParams.GenEvaluationKeys(r.sk); err != nil {
+			return nil, fmt.Errorf("btpParams.GenEvaluationKeys: %w", err)
+		}
+
+		if r.btpEval, err = bootstrapping.NewEvaluator(btpParams, evk); err != nil {
+			return nil, fmt.Errorf("bootstrapping.NewEvaluator: %w", err)
+		}
+	}
+
+	return
+}
+
+func (r *runner) genGaloisKeys(galEls ...uint64) {
+	for _, galEl := range galEls {
+		if _, ok := r.evk.GaloisKeys[galEl]; !ok {
+			r.evk.GaloisKeys[galEl] = r.kgen.GenGaloisKeyNew(galEl, r.sk)
+		}
+	}
+}
+
+// evaluate evaluates the circuit once on new inputs.
+func (r *runner) evaluate() (values map[string]*value, err error) {
+
+	values = map[string]*value{}
+
+	for _, in := range r.Inputs {
+		if values[in.Name], err = r.newInput(in); err != nil {
+			return nil, fmt.Errorf("input %q: %w", in.Name, err)
+		}
+	}
+
+	if err = r.est.Checkpoint("circuit", 0, len(r.Operations)); err != nil {
+		return
+	}
+
+	for i, op := range r.Operations {
+
+		var v *value
+		if v, err = r.apply(i, op, values); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+
+		values[op.output()] = v
+
+		if r.Trace != nil {
+			r.Trace(i, op, v.el.Level, v.want, r.est.Decrypt(v.el))
+		}
+
+		if err = r.est.Checkpoint("circuit", i+1, len(r.Operations)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (r *runner) newInput(in Input) (v *value, err error) {
+
+	lo, hi := in.Min, in.Max
+	if lo == 0 && hi == 0 {
+		lo, hi = -1, 1
+	}
+
+	a, b := complex(lo, lo), complex(hi, hi)
+	if in.Real {
+		a, b = complex(lo, 0), complex(hi, 0)
+	}
+
+	values, el, pt, _ := r.est.NewTestVectorFromSeed(r.ecd, nil, a, b, r.source)
+
+	if in.Scale != 0 {
+
+		scale := rlwe.NewScale(in.Scale)
+
+		el = r.est.NewElement(values, 1, r.params.MaxLevel(), scale)
+		r.est.AddEncodingNoise(el)
+
+		pt.Scale = scale
+		if err = r.ecd.Encode(values, pt); err != nil {
+			return nil, fmt.Errorf("ecd.Encode: %w", err)
+		}
+	}
+
+	var key rlwe.EncryptionKey
+
+	switch in.Encryption {
+	case "", PublicKey:
+		key = r.pk
+		r.est.AddEncryptionNoisePk(el)
+	case SecretKey:
+		key = r.sk
+		r.est.AddEncryptionNoiseSk(el)
+	}
+
+	r.est.CheckOverflow("encrypt", el)
+
+	if r.Ranges != nil {
+		r.est.SetRange(el, estimator.Interval{Min: real(a), Max: real(b)}, estimator.Interval{Min: imag(a), Max: imag(b)})
+	}
+
+	var ct *rlwe.Ciphertext
+
+	if r.Lattigo && key != nil {
+		ct = ckks.NewCiphertext(r.params, 1, r.params.MaxLevel())
+		if err = rlwe.NewEncryptor(r.params, key).Encrypt(pt, ct); err != nil {
+			return nil, fmt.Errorf("enc.Encrypt: %w", err)
+		}
+	}
+
+	return &value{want: values, el: el, ct: ct, pt: pt}, nil
+}
+
+// apply applies the operation to copies of its inputs and returns the result.
+func (r *runner) apply(i int, op Operation, values map[string]*value) (out *value, err error) {
+
+	in := values[op.Inputs[0]]
+
+	out = &value{
+		want: copyValues(in.want),
+		el:   in.el.CopyNew(),
+	}
+
+	if in.ct != nil {
+		out.ct = in.ct.CopyNew()
+	}
+
+	mulCmplx := bignum.NewComplexMultiplier().Mul
+
+	switch op.Op {
+	case Add, Sub, Mul, MulRelin:
+
+		if len(op.Constant) != 0 {
+
+			c := op.constant()
+			cBig := bignum.ToComplex(c, out.want[0].Prec())
+
+			for j := range out.want {
+				switch op.Op {
+				case Add:
+					out.want[j].Add(out.want[j], cBig)
+				case Sub:
+					out.want[j].Sub(out.want[j], cBig)
+				case Mul:
+					mulCmplx(out.want[j], cBig, out.want[j])
+				}
+			}
+
+			if op.Op == Mul && op.Scale != 0 {
+				return out, r.mulScaled(out, c, rlwe.NewScale(op.Scale))
+			}
+
+			return out, r.binary(op.Op, out, c, c)
+		}
+
+		in1 := values[op.Inputs[1]]
+
+		for j := range out.want {
+			switch op.Op {
+			case Add:
+				out.want[j].Add(out.want[j], in1.want[j])
+			case Sub:
+				out.want[j].Sub(out.want[j], in1.want[j])
+			case Mul, MulRelin:
+				mulCmplx(out.want[j], in1.want[j], out.want[j])
+			}
+		}
+
+		var op1 rlwe.Operand = in1.ct
+		if in1.ct == nil {
+			op1 = in1.pt
+		}
+
+		return out, r.binary(op.Op, out, in1.el, op1)
+
+	case Relinearize:
+
+		if err = r.est.Relinearize(out.el, out.el); err != nil {
+			return nil, fmt.Errorf("est.Relinearize: %w", err)
+		}
+
+		if r.Lattigo {
+			if err = r.eval.Relinearize(out.ct, out.ct); err != nil {
+				return nil, fmt.Errorf("eval.Relinearize: %w", err)
+			}
+		}
+
+	case Rescale:
+
+		if err = r.est.Rescale(out.el, out.el); err != nil {
+			return nil, fmt.Errorf("est.Rescale: %w", err)
+		}
+
+		if r.Lattigo {
+			if err = r.eval.Rescale(out.ct, out.ct); err != nil {
+				return nil, fmt.Errorf("eval.Rescale: %w", err)
+			}
+		}
+
+	case Rotate:
+
+		utils.RotateSliceInPlace(out.want, op.K)
+
+		if err = r.est.Rotate(out.el, op.K, out.el); err != nil {
+			return nil, fmt.Errorf("est.Rotate: %w", err)
+		}
+
+		if r.Lattigo {
+			if err = r.eval.Rotate(out.ct, op.K, out.ct); err != nil {
+				return nil, fmt.Errorf("eval.Rotate: %w", err)
+			}
+		}
+
+	case Conjugate:
+
+		for j := range out.want {
+			out.want[j][1].Neg(out.want[j][1])
+		}
+
+		if err = r.est.Conjugate(out.el, out.el); err != nil {
+			return nil, fmt.Errorf("est.Conjugate: %w", err)
+		}
+
+		if r.Lattigo {
+			if err = r.eval.Conjugate(out.ct, out.ct); err != nil {
+				return nil, fmt.Errorf("eval.Conjugate: %w", err)
+			}
+		}
+
+	case Polynomial:
+		return out, r.polynomial(r.polys[i], op.Scale, out)
+
+	case LinearTransformation:
+		return out, r.linearTransformation(op, r.lts[i], out)
+
+	case Bootstrap:
+
+		if out.el, err = r.btpEst.Bootstrap(out.el); err != nil {
+			return nil, fmt.Errorf("btpEst.Bootstrap: %w", err)
+		}
+
+		if r.Lattigo {
+			if out.ct, err = r.btpEval.Bootstrap(out.ct); err != nil {
+				return nil, fmt.Errorf("btpEval.Bootstrap: %w", err)
+			}
+		}
+	}
+
+	return
+}
+
+// binary applies add, sub, mul or mul_relin to out and the second operand,
+// given as an element or a constant (op1Est) and as a ciphertext, plaintext or constant (op1).
+func (r *runner) binary(opType string, out *value, op1Est, op1 rlwe.Operand) (err error) {
+
+	switch opType {
+	case Add:
+		err = r.est.Add(out.el, op1Est, out.el)
+	case Sub:
+		err = r.est.Sub(out.el, op1Est, out.el)
+	case Mul:
+		err = r.est.Mul(out.el, op1Est, out.el)
+	case MulRelin:
+		err = r.est.MulRelin(out.el, op1Est, out.el)
+	}
+
+	if err != nil {
+		return fmt.Errorf("est.%s: %w", opType, err)
+	}
+
+	if !r.Lattigo {
+		return
+	}
+
+	switch opType {
+	case Add:
+		err = r.eval.Add(out.ct, op1, out.ct)
+	case Sub:
+		err = r.eval.Sub(out.ct, op1, out.ct)
+	case Mul:
+		err = r.eval.Mul(out.ct, op1, out.ct)
+	case MulRelin:
+		err = r.eval.MulRelin(out.ct, op1, out.ct)
+	}
+
+	if err != nil {
+		return fmt.Errorf("eval.%s: %w", opType, err)
+	}
+
+	return
+}
+
+// mulScaled multiplies out by the constant c encoded at the given scale, that is by
+// the Gaussian integer round(c * scale), and multiplies the scale of out by scale.
+func (r *runner) mulScaled(out *value, c complex128, scale rlwe.Scale) (err error) {
+
+	cInt := bignum.ToComplex(c, out.want[0].Prec())
+	cInt[0].Mul(cInt[0], &scale.Value)
+	cInt[1].Mul(cInt[1], &scale.Value)
+	estimator.Round(cInt[0])
+	estimator.Round(cInt[1])
+
+	if err = r.binary(Mul, out, cInt, cInt); err != nil {
+		return
+	}
+
+	r.est.ReplaceScale(out.el, out.el.Scale.Mul(scale))
+
+	if r.Lattigo {
+		out.ct.Scale = out.ct.Scale.Mul(scale)
+	}
+
+	return
+}
+
+// polynomial evaluates the polynomial on out, after the change of basis
+// mapping its interval to [-1, 1], with the given target scale (if not 0).
+func (r *runner) polynomial(poly polynomial.Polynomial, scale float64, out *value) (err error) {
+
+	for j := range out.want {
+		out.want[j] = poly.Evaluate(out.want[j])
+	}
+
+	scalar, constant := poly.ChangeOfBasis()
+
+	if scalar.Cmp(new(big.Float).SetInt64(1)) != 0 || constant.Sign() != 0 {
+
+		if err = r.est.Mul(out.el, scalar, out.el); err != nil {
+			return fmt.Errorf("est.Mul: %w", err)
+		}
+
+		if err = r.est.Add(out.el, constant, out.el); err != nil {
+			return fmt.Errorf("est.Add: %w", err)
+		}
+
+		if err = r.est.Rescale(out.el, out.el); err != nil {
+			return fmt.Errorf("est.Rescale: %w", err)
+		}
+
+		if r.Lattigo {
+
+			if err = r.eval.Mul(out.ct, scalar, out.ct); err != nil {
+				return fmt.Errorf("eval.Mul: %w", err)
+			}
+
+			if err = r.eval.Add(out.ct, constant, out.ct); err != nil {
+				return fmt.Errorf("eval.Add: %w", err)
+			}
+
+			if err = r.eval.Rescale(out.ct, out.ct); err != nil {
+				return fmt.Errorf("eval.Rescale: %w", err)
+			}
+		}
+	}
+
+	target := out.el.Scale
+	if scale != 0 {
+		target = rlwe.NewScale(scale)
+	}
+
+	if out.el, err = r.est.EvaluatePolynomialNew(out.el, poly, target); err != nil {
+		return fmt.Errorf("est.EvaluatePolynomialNew: %w", err)
+	}
+
+	if r.Lattigo {
+		if out.ct, err = r.polyEval.Evaluate(out.ct, poly, target); err != nil {
+			return fmt.Errorf("polyEval.Evaluate: %w", err)
+		}
+	}
+
+	return
+}
+
+// linearTransformation evaluates the linear transformation of the
+// given diagonals on out. The result must be rescaled.
+func (r *runner) linearTransformation(op Operation, diags lintrans.Diagonals[*bignum.Complex], out *value) (err error) {
+
+	prec := out.want[0].Prec()
+
+	add := func(a, b, c []*bignum.Complex) {
+		for i := range c {
+			if a[i] != nil && b[i] != nil {
+				c[i].Add(a[i], b[i])
+			}
+		}
+	}
+
+	mulCmplx := bignum.NewComplexMultiplier().Mul
+
+	muladd := func(a, b, c []*bignum.Complex) {
+		tmp := bignum.NewComplex().SetPrec(prec)
+		for i := range c {
+			if a[i] != nil && b[i] != nil {
+				mulCmplx(a[i], b[i], tmp)
+				c[i].Add(c[i], tmp)
+			}
+		}
+	}
+
+	newVec := func(size int) (vec []*bignum.Complex) {
+		vec = make([]*bignum.Complex, size)
+		for i := range vec {
+			vec[i] = bignum.NewComplex().SetPrec(prec)
+		}
+		return
+	}
+
+	out.want = diags.Evaluate(out.want, newVec, add, muladd)
+
+	ltParams := r.lintransParameters(op, out.el.Level)
+
+	lt := estimator.LinearTransformation{
+		LogSlots:                 r.params.LogMaxSlots(),
+		LogBabyStepGianStepRatio: ltParams.LogBabyStepGiantStepRatio,
+		Scale:                    ltParams.Scale,
+		Value:                    diags,
+	}
+
+	if out.el, err = r.est.EvaluateLinearTransformationNew(out.el, lt); err != nil {
+		return fmt.Errorf("est.EvaluateLinearTransformationNew: %w", err)
+	}
+
+	if r.Lattigo {
+
+		ltLattigo := lintrans.NewTransformation(r.params, ltParams)
+
+		if err = lintrans.Encode(r.ecd, diags, ltLattigo); err != nil {
+			return fmt.Errorf("lintrans.Encode: %w", err)
+		}
+
+		if out.ct, err = r.ltEval.EvaluateNew(out.ct, ltLattigo); err != nil {
+			return fmt.Errorf("ltEval.EvaluateNew: %w", err)
+		}
+	}
+
+	return
+}
+
+// lintransParameters returns the parameters of the linear transformation of op at the given level,
+// whose scale is op.Scale or, by default, the prime consumed by the subsequent rescaling.
+func (r *runner) lintransParameters(op Operation, level int) lintrans.Parameters {
+
+	scale := rlwe.NewScale(r.params.Q()[level])
+	if op.Scale != 0 {
+		scale = rlwe.NewScale(op.Scale)
+	}
+
+	slots := r.params.MaxSlots()
+
+	diags := make([]int, 0, len(op.LinearTransformation.Diagonals))
+	for k := range op.LinearTransformation.Diagonals {
+		diags = append(diags, (k%slots+slots)%slots)
+	}
+
+	return lintrans.Parameters{
+		DiagonalsIndexList:        diags,
+		LevelQ:                    level,
+		LevelP:                    r.params.MaxLevelP(),
+		Scale:                     scale,
+		LogDimensions:             r.params.LogMaxDimensions(),
+		LogBabyStepGiantStepRatio: op.LinearTransformation.LogBSGSRatio,
+	}
+}
+
+func (op Operation) constant() complex128 {
+	if len(op.Constant) == 1 {
+		return complex(op.Constant[0], 0)
+	}
+	return complex(op.Constant[0], op.Constant[1])
+}
+
+func (p PolynomialLiteral) polynomial() polynomial.Polynomial {
+
+	var prec uint = 128
+
+	if p.Function != "" {
+
+		f := functions[p.Function]
+
+		fBig := func(x *big.Float) (y *big.Float) {
+			xF64, _ := x.Float64()
+			return new(big.Float).SetPrec(x.Prec()).SetFloat64(f(xF64))
+		}
+
+		interval := bignum.Interval{
+			A:     *bignum.NewFloat(p.Interval[0], prec),
+			B:     *bignum.NewFloat(p.Interval[1], prec),
+			Nodes: p.Degree + 1,
+		}
+
+		return polynomial.NewPolynomial(bignum.ChebyshevApproximation(fBig, interval))
+	}
+
+	if p.Basis == "chebyshev" {
+		return polynomial.NewPolynomial(bignum.NewPolynomial(bignum.Chebyshev, p.Coefficients, p.Interval))
+	}
+
+	return polynomial.NewPolynomial(bignum.NewPolynomial(bignum.Monomial, p.Coefficients, nil))
+}
+
+func (lt LinearTransformationLiteral) diagonals(slots int) (diags lintrans.Diagonals[*bignum.Complex]) {
+
+	diags = lintrans.Diagonals[*bignum.Complex]{}
+
+	for k, d := range lt.Diagonals {
+
+		diag := make([]*bignum.Complex, slots)
+		for i := range diag {
+			diag[i] = bignum.ToComplex(d[i%len(d)], 128)
+		}
+
+		diags[(k%slots+slots)%slots] = diag
+	}
+
+	return
+}
+
+func copyValues(values []*bignum.Complex) (c []*bignum.Complex) {
+	c = make([]*bignum.Complex, len(values))
+	for i := range values {
+		c[i] = values[i].Clone()
+	}
+	return
+}
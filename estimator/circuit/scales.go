@@ -0,0 +1,334 @@
+package circuit
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// ScaleOptions are the options of PlanScales.
+type ScaleOptions struct {
+	// Seed and Heuristic are the options of the evaluation of the circuit
+	// with the estimator measuring the levels and magnitudes of its values.
+	Seed      int64
+	Heuristic bool
+
+	// Margin is the minimum number of bits between a scaled value, at least 2^Margin times
+	// its largest measured magnitude, and half of the modulus at its level (default: 1).
+	Margin float64
+
+	// MaxLogScale is the largest log2 of the scale of an input, constant,
+	// plaintext, diagonal or polynomial output (default: 60).
+	MaxLogScale float64
+}
+
+// LevelScale is the planned scale of the values at a level.
+type LevelScale struct {
+	Level     int
+	LogQi     float64
+	Log2Scale float64
+}
+
+// ScalePlan is the scales planned by PlanScales.
+type ScalePlan struct {
+	// Circuit is the circuit with the Scale of its inputs and operations set.
+	Circuit Circuit
+
+	// Levels are the planned scales of the levels used by the circuit.
+	Levels []LevelScale
+
+	// Warnings are the additions and subtractions whose operands do not have the same
+	// scale, and the plaintexts whose uses require different scales.
+	Warnings []string
+}
+
+// PlanScales chooses the scales of the inputs, constants, plaintexts, diagonals and polynomial
+// outputs of the circuit so that the values at each level l have the same scale T_l, and
+// returns the circuit with these scales, which can be run with the estimator and Lattigo.
+//
+// The scales satisfy T_l^2 / q_l = T_{l-1}, so that the product of two values at level l lands
+// exactly on T_{l-1} after the rescaling, and a value of scale s multiplied at level l by a
+// constant, plaintext or diagonals of scale T_{l-1} * q_l / s lands on T_{l-1} too. As T_l is
+// then the geometric mean of T_{l-1} and q_l, the scales are determined by T_0, which is chosen
+// as large as possible, to maximize the precision, such that every value of the circuit, whose
+// magnitude is measured by an evaluation with the estimator, fits in the modulus at its level
+// with Margin bits to spare and such that no encoding scale exceeds MaxLogScale.
+//
+// Additions of values at different levels or of values whose scale does not follow from the
+// plan (e.g. the output of a bootstrapping) may not be aligned, which is reported in Warnings.
+func PlanScales(c Circuit, opts ScaleOptions) (plan ScalePlan, err error) {
+
+	if err = c.Validate(); err != nil {
+		return plan, fmt.Errorf("invalid circuit: %w", err)
+	}
+
+	if opts.Margin == 0 {
+		opts.Margin = 1
+	}
+
+	if opts.MaxLogScale == 0 {
+		opts.MaxLogScale = 60
+	}
+
+	params, err := ckks.NewParametersFromLiteral(c.Parameters)
+	if err != nil {
+		return plan, fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+	}
+
+	// Levels and magnitudes of the outputs of the operations.
+	levels := make([]int, len(c.Operations))
+	magnitudes := make([]float64, len(c.Operations))
+
+	if _, err = Run(c, Options{
+		Seed:      opts.Seed,
+		Heuristic: opts.Heuristic,
+		Trace: func(i int, op Operation, level int, want, predicted []*bignum.Complex) {
+			levels[i] = level
+			magnitudes[i] = magnitude(want)
+		},
+	}); err != nil {
+		return plan, fmt.Errorf("Run: %w", err)
+	}
+
+	p := &scalePlanner{
+		Circuit:    c,
+		params:     params,
+		opts:       opts,
+		levels:     levels,
+		magnitudes: magnitudes,
+		logQ:       make([]float64, params.MaxLevel()+1),
+		logQLevel:  make([]float64, params.MaxLevel()+1),
+	}
+
+	for i, qi := range params.Q() {
+		p.logQ[i] = math.Log2(float64(qi))
+		p.logQLevel[i] = p.logQ[i]
+		if i > 0 {
+			p.logQLevel[i] += p.logQLevel[i-1]
+		}
+	}
+
+	// Bisection on log2(T_0), as the scales increase with T_0.
+	lo, hi := 0.0, p.logQLevel[params.MaxLevel()]
+
+	if _, ok := p.plan(lo); !ok {
+		return plan, fmt.Errorf("no scales fit the values of the circuit")
+	}
+
+	for i := 0; i < 64; i++ {
+
+		mid := (lo + hi) / 2
+
+		if _, ok := p.plan(mid); ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	plan, _ = p.plan(lo)
+
+	for _, in := range plan.Circuit.Inputs {
+		if in.Scale != 0 && in.Scale < 2 {
+			return plan, fmt.Errorf("input %q: scale %g < 2", in.Name, in.Scale)
+		}
+	}
+
+	for i, op := range plan.Circuit.Operations {
+		if op.Scale != 0 && op.Scale < 2 {
+			return plan, fmt.Errorf("operation %d (%s): scale %g < 2", i, op.Op, op.Scale)
+		}
+	}
+
+	return
+}
+
+// scalePlanner plans the scales of a circuit whose levels and magnitudes are known.
+type scalePlanner struct {
+	Circuit
+
+	params     ckks.Parameters
+	opts       ScaleOptions
+	levels     []int
+	magnitudes []float64
+
+	// logQ are the log2 of the primes and logQLevel the log2 of the modulus at each level.
+	logQ, logQLevel []float64
+}
+
+// plannedValue is the level and the log2 of the scale of a value.
+type plannedValue struct {
+	level    int
+	logScale float64
+
+	// input is the index of the plaintext input of the value, or -1.
+	input int
+}
+
+// plan returns the plan with log2(T_0) = logT0 and true if all the values fit.
+func (p *scalePlanner) plan(logT0 float64) (plan ScalePlan, ok bool) {
+
+	maxLevel := p.params.MaxLevel()
+
+	logT := make([]float64, maxLevel+1)
+	logT[0] = logT0
+	for l := 1; l <= maxLevel; l++ {
+		logT[l] = (logT[l-1] + p.logQ[l]) / 2
+	}
+
+	c := p.Circuit
+	c.Inputs = append([]Input{}, c.Inputs...)
+	c.Operations = append([]Operation{}, c.Operations...)
+
+	for i := range c.Inputs {
+		c.Inputs[i].Scale = 0
+	}
+
+	for i := range c.Operations {
+		c.Operations[i].Scale = 0
+	}
+
+	ok = true
+	used := map[int]bool{}
+
+	// fits records the value and returns false if it does not fit.
+	fits := func(v plannedValue, magnitude float64) bool {
+		used[v.level] = true
+		return v.logScale+math.Log2(max(magnitude, 1e-300))+p.opts.Margin+1 <= p.logQLevel[v.level]
+	}
+
+	// encoding returns false if the scale of an encoding is too large.
+	encoding := func(logScale float64) bool {
+		return logScale <= p.opts.MaxLogScale
+	}
+
+	// landing returns the log2 of the scale of the operand of a multiplication, at the level
+	// of v, such that the product lands on the planned scale after the rescaling.
+	landing := func(v plannedValue) float64 {
+		if v.level == 0 {
+			return p.logQ[0]
+		}
+		return logT[v.level-1] + p.logQ[v.level] - v.logScale
+	}
+
+	values := map[string]plannedValue{}
+	pending := map[int]bool{}
+
+	for i, in := range c.Inputs {
+
+		v := plannedValue{level: maxLevel, logScale: logT[maxLevel], input: -1}
+
+		if in.Encryption == Plaintext {
+			v.input = i
+			pending[i] = true
+		} else {
+			c.Inputs[i].Scale = math.Exp2(v.logScale)
+			ok = ok && encoding(v.logScale) && fits(v, max(math.Abs(in.Min), math.Abs(in.Max), 1))
+		}
+
+		values[in.Name] = v
+	}
+
+	// setPlaintext sets the scale of the plaintext input of v, or reports a conflict.
+	setPlaintext := func(i int, v plannedValue, logScale float64) {
+		if pending[v.input] {
+			c.Inputs[v.input].Scale = math.Exp2(logScale)
+			ok = ok && encoding(logScale)
+			delete(pending, v.input)
+		} else if math.Abs(math.Log2(c.Inputs[v.input].Scale)-logScale) > 1e-9 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("operation %d (%s): plaintext %q is used with scales 2^%.4f and 2^%.4f",
+				i, c.Operations[i].Op, c.Inputs[v.input].Name, math.Log2(c.Inputs[v.input].Scale), logScale))
+		}
+	}
+
+	for i, op := range c.Operations {
+
+		in := values[op.Inputs[0]]
+		out := plannedValue{level: p.levels[i], logScale: in.logScale, input: -1}
+
+		switch op.Op {
+		case Add, Sub, Mul, MulRelin:
+
+			if len(op.Constant) != 0 {
+
+				if op.Op == Mul && !bignum.ToComplex(op.constant(), 64).IsInt() {
+					logScale := landing(in)
+					c.Operations[i].Scale = math.Exp2(logScale)
+					ok = ok && encoding(logScale)
+					out.logScale += logScale
+				}
+
+				break
+			}
+
+			in1 := values[op.Inputs[1]]
+
+			if op.Op == Add || op.Op == Sub {
+
+				if in1.input != -1 {
+					setPlaintext(i, in1, in.logScale)
+					in1.logScale = in.logScale
+				}
+
+				if d := in1.logScale - in.logScale; math.Abs(d) > 1e-9 {
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("operation %d (%s): the scales of the operands differ by a factor 2^%.4f", i, op.Op, d))
+					if d >= 1 {
+						out.logScale = in1.logScale
+					}
+				}
+
+				break
+			}
+
+			if in1.input != -1 {
+				in1.logScale = landing(in)
+				setPlaintext(i, in1, in1.logScale)
+			}
+
+			out.logScale += in1.logScale
+
+		case Rescale:
+			out.logScale -= p.logQ[in.level]
+
+		case Polynomial:
+			out.logScale = logT[out.level]
+			c.Operations[i].Scale = math.Exp2(out.logScale)
+			ok = ok && encoding(out.logScale)
+
+		case LinearTransformation:
+			logScale := landing(in)
+			c.Operations[i].Scale = math.Exp2(logScale)
+			ok = ok && encoding(logScale)
+			out.logScale += logScale
+
+		case Bootstrap:
+			out.logScale = p.params.DefaultScale().Log2()
+		}
+
+		ok = ok && fits(out, p.magnitudes[i])
+
+		values[op.output()] = out
+	}
+
+	for l := maxLevel; l >= 0; l-- {
+		if used[l] {
+			plan.Levels = append(plan.Levels, LevelScale{Level: l, LogQi: p.logQ[l], Log2Scale: logT[l]})
+		}
+	}
+
+	plan.Circuit = c
+
+	return
+}
+
+// magnitude returns the largest absolute value of the real and imaginary parts of the values.
+func magnitude(values []*bignum.Complex) (m float64) {
+	for _, v := range values {
+		re, _ := v[0].Float64()
+		im, _ := v[1].Float64()
+		m = max(m, math.Abs(re), math.Abs(im))
+	}
+	return
+}
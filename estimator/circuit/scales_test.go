@@ -0,0 +1,87 @@
+package circuit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestPlanScales(t *testing.T) {
+
+	// z = x^2 + 0.3x + w, whose summands are at the same level but come from different products.
+	c := Circuit{
+		Parameters: ckks.ParametersLiteral{LogN: 10, LogQ: []int{55, 45, 40}, LogP: []int{61}, LogDefaultScale: 45},
+		Inputs:     []Input{{Name: "x"}, {Name: "w", Encryption: Plaintext}},
+		Operations: []Operation{
+			{Op: MulRelin, Inputs: []string{"x", "x"}, Output: "z"},
+			{Op: Rescale, Inputs: []string{"z"}},
+			{Op: Mul, Inputs: []string{"x"}, Constant: []float64{0.3}, Output: "u"},
+			{Op: Rescale, Inputs: []string{"u"}},
+			{Op: Add, Inputs: []string{"z", "u"}},
+			{Op: Add, Inputs: []string{"z", "w"}},
+		},
+		Outputs: []string{"z"},
+	}
+
+	plan, err := PlanScales(c, ScaleOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Warnings) != 0 {
+		t.Fatalf("Warnings: %v", plan.Warnings)
+	}
+
+	if len(plan.Levels) != 2 || plan.Levels[0].Level != 2 || plan.Levels[1].Level != 1 {
+		t.Fatalf("Levels: %v", plan.Levels)
+	}
+
+	// T_l^2 / q_l = T_{l-1}
+	if l := plan.Levels; math.Abs(2*l[0].Log2Scale-l[0].LogQi-l[1].Log2Scale) > 1e-9 {
+		t.Fatalf("Levels: 2 * %f - %f != %f", l[0].Log2Scale, l[0].LogQi, l[1].Log2Scale)
+	}
+
+	// The plaintext w is encoded with the scale T_1, which is at most MaxLogScale.
+	if have := plan.Levels[1].Log2Scale; math.Abs(have-60) > 1e-6 {
+		t.Fatalf("Log2Scale at level 1: %f != 60", have)
+	}
+
+	// With a margin of 40 bits, T_1 is the largest scale such that z, whose real
+	// and imaginary parts are at most 2.3 and 3.3, fits in q_0 * q_1.
+	margin, err := PlanScales(c, ScaleOptions{Seed: 1, Margin: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := ckks.NewParametersFromLiteral(c.Parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logQ1 := math.Log2(float64(params.Q()[0])) + math.Log2(float64(params.Q()[1]))
+
+	if have, want := margin.Levels[1].Log2Scale, logQ1-math.Log2(3.3)-41; have < want || have > want+0.5 {
+		t.Fatalf("Log2Scale at level 1 with a margin of 40 bits: %f not in [%f, %f]", have, want, want+0.5)
+	}
+
+	// The summands of the additions have the same scale, as checked by the strict mode.
+	if _, err = Run(plan.Circuit, Options{Seed: 1, Strict: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The planned scales are more precise than the default scale.
+	planned, err := Run(plan.Circuit, Options{Seed: 1, Lattigo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unplanned, err := Run(c, Options{Seed: 1, Lattigo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if have, want := planned[0].Actual.AVGLog2Prec.L2, unplanned[0].Actual.AVGLog2Prec.L2; have < want {
+		t.Fatalf("AVG Log2Prec: planned %.2f < default %.2f", have, want)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"math"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/inverse"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/minimax"
+	"github.com/tuneinsight/lattigo/v6/circuits/common/polynomial"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func init() {
+	register(
+		experiment{
+			name:        "goldschmidt",
+			description: "Goldschmidt division (inverse) in [2^-4, 2 - 2^-4]",
+			params: ckks.ParametersLiteral{
+				LogN:            16,
+				LogQ:            []int{60, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 55,
+				Xs:              ring.Ternary{H: 192},
+			},
+			inputs: inputs{0.1, 1.9, true},
+			setup:  setupGoldschmidt,
+		},
+		experiment{
+			name:        "power_basis",
+			description: "Chebyshev power basis up to T_4096",
+			params: ckks.ParametersLiteral{
+				LogN:            16,
+				LogQ:            []int{60, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 55,
+			},
+			inputs: inputs{-1, 1, true},
+			setup:  setupPowerBasis,
+		},
+	)
+}
+
+func setupGoldschmidt(env *env) (trial, error) {
+
+	evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+	eval := ckks.NewEvaluator(env.params, evk)
+
+	inverseEval := inverse.NewEvaluator(env.params, minimax.NewEvaluator(env.params, eval, nil))
+
+	log2min := -4.0
+
+	// 2^{-(prec - LogN + 1)}
+	prec := float64(env.params.N()/2) / env.params.DefaultScale().Float64()
+
+	// Estimates the number of iterations required to achieve the desired precision, given the interval [min, 2-min]
+	start := 1 - math.Exp2(log2min)
+	var iters = 1
+	for start >= prec {
+		start *= start // Doubles the bit-precision at each iteration
+		iters++
+	}
+
+	iters = max(iters, 3)
+
+	return func() ([]outcome, error) {
+
+		values, el, _, ct := env.newTestVector(env.pk)
+
+		goldschmidtDivision(values, iters)
+
+		el, err := env.est.GoldschmidtDivisionNew(el, log2min)
+		if err != nil {
+			return nil, err
+		}
+
+		if ct, err = inverseEval.GoldschmidtDivisionNew(ct, log2min); err != nil {
+			return nil, err
+		}
+
+		return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// goldschmidtDivision sets the values to their inverse
+// computed with the given number of Goldschmidt iterations.
+func goldschmidtDivision(values []*bignum.Complex, iters int) {
+
+	a := bignum.NewComplex()
+	b := bignum.NewComplex()
+	tmp := bignum.NewComplex()
+
+	prec := values[0].Prec()
+
+	one := bignum.NewFloat(1, prec)
+	two := bignum.NewFloat(2, prec)
+
+	mul := bignum.NewComplexMultiplier().Mul
+
+	for i := range values {
+
+		a[0].Neg(values[i][0])
+		a[1].Neg(values[i][1])
+
+		b[0].Add(a[0], one)
+		b[1].Set(a[1])
+
+		a[0].Add(a[0], two)
+
+		for j := 1; j < iters; j++ {
+			mul(b, b, b)
+			mul(a, b, tmp)
+			a.Add(a, tmp)
+		}
+
+		values[i].Set(a)
+	}
+}
+
+func setupPowerBasis(env *env) (trial, error) {
+
+	n := 12
+
+	evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+	eval := ckks.NewEvaluator(env.params, evk)
+
+	mul := bignum.NewComplexMultiplier().Mul
+
+	minusOne := &bignum.Complex{estimator.NewFloat(-1), estimator.NewFloat(0)}
+
+	return func() ([]outcome, error) {
+
+		values, el, _, ct := env.newTestVector(env.pk)
+
+		// T_{2^n}(x) = T_2(T_2(...(x)))
+		for k := 0; k < n; k++ {
+			for i := range values {
+				mul(values[i], values[i], values[i])
+				values[i].Add(values[i], values[i])
+				values[i].Add(values[i], minusOne)
+			}
+		}
+
+		pbCt := polynomial.NewPowerBasis(ct, bignum.Chebyshev)
+		if err := pbCt.GenPower(1<<n, false, eval); err != nil {
+			return nil, err
+		}
+
+		pbEl := estimator.NewPowerBasis(el, bignum.Chebyshev)
+		if err := pbEl.GenPower(1<<n, false, env.est); err != nil {
+			return nil, err
+		}
+
+		return []outcome{{values, env.est.Decrypt(pbEl.Value[1<<n]), pbCt.Value[1<<n]}}, nil
+	}, nil
+}
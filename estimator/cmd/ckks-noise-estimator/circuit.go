@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+)
+
+// runCircuit runs the circuit described by the file given with -f.
+func runCircuit(args []string) (err error) {
+
+	fs := flag.NewFlagSet("circuit", flag.ContinueOnError)
+
+	file := fs.String("f", "", "JSON or YAML file describing the circuit (see package circuit)")
+	trials := fs.Int("trials", 1, "number of trials")
+	seed := fs.Int64("seed", 0, "seed of the inputs and of the estimator (default: random)")
+	lattigo := fs.Bool("lattigo", true, "also evaluate the circuit with Lattigo")
+	heuristic := fs.Bool("heuristic", true, "use the heuristic noise model of the estimator")
+	ranges := fs.Bool("ranges", false, "propagate the ranges of the values and warn when one may leave the domain of a polynomial or overflow the modulus")
+	tail := fs.Float64("tail", estimator.DefaultTail, "number of standard deviations of the noise in the error bounds of -ranges")
+	overflow := fs.String("overflow", "", "check the values for wraparounds of the modulus: flag records them, reduce also simulates them")
+	strict := fs.Bool("strict", false, "check the consistency of the levels, scales and degrees of the operands of the operations of the estimator")
+	timeout := fs.Duration("timeout", 0, "maximum duration of the evaluation (0: none)")
+	progress := fs.Bool("progress", false, "print the progress of the evaluation to stderr")
+	count := fs.Bool("count", false, "print the numbers of operations of the estimator, per level, to stderr")
+	keys := fs.String("keys", "", "JSON file to which to write the evaluation keys used by the circuit and by its bootstrappings, with their levels (see estimator.RequiredKeys)")
+	costs := fs.String("costs", "", "JSON file of the cost of each operation, {\"op\": {\"Base\": b, \"PerLevel\": c}}, to print the estimated runtime of a trial to stderr (see estimator.CostTable)")
+	out := newOutputFlags(fs)
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if *file == "" {
+		return fmt.Errorf("missing -f")
+	}
+
+	var cfg config
+	if err = out.apply(&cfg); err != nil {
+		return
+	}
+
+	opts := circuit.Options{
+		Trials:    *trials,
+		Seed:      *seed,
+		Lattigo:   *lattigo,
+		Heuristic: *heuristic,
+		Strict:    *strict,
+	}
+
+	if !isSet(fs, "seed") {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	if *ranges {
+		opts.Ranges = estimator.NewRangeAnalysis()
+		opts.Ranges.Tail = *tail
+	}
+
+	if *progress {
+		opts.Progress = func(p estimator.Progress) {
+			fmt.Fprintf(os.Stderr, "progress: %s\n", p)
+		}
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var table estimator.CostTable
+	if *costs != "" {
+
+		var data []byte
+		if data, err = os.ReadFile(*costs); err != nil {
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		if err = json.Unmarshal(data, &table); err != nil {
+			return fmt.Errorf("json.Unmarshal: %w", err)
+		}
+	}
+
+	if *count || table != nil {
+		opts.Counter = estimator.NewOperationCounter()
+	}
+
+	if *keys != "" {
+		opts.Keys = estimator.NewKeyRequirements()
+		opts.BootstrappingKeys = estimator.NewKeyRequirements()
+	}
+
+	switch *overflow {
+	case "":
+	case "flag", "reduce":
+		opts.Overflow = estimator.NewOverflowCheck(*overflow == "reduce")
+	default:
+		return fmt.Errorf("invalid -overflow: %q", *overflow)
+	}
+
+	c, err := circuit.Load(*file)
+	if err != nil {
+		return fmt.Errorf("circuit.Load: %w", err)
+	}
+
+	results, err := circuit.RunContext(ctx, c, opts)
+	if err != nil {
+		return fmt.Errorf("circuit.RunContext: %w", err)
+	}
+
+	if opts.Ranges != nil {
+		for _, w := range opts.Ranges.Warnings() {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+
+	if opts.Overflow != nil {
+		overflows := opts.Overflow.Overflows()
+		for i, o := range overflows {
+			if i == maxOverflows {
+				fmt.Fprintf(os.Stderr, "warning: ... and %d more overflows\n", len(overflows)-i)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "warning: overflow: %s\n", o)
+		}
+	}
+
+	if opts.Counter != nil {
+
+		counts := opts.Counter.Counts()
+
+		if *count {
+			fmt.Fprintf(os.Stderr, "operations (%d trials):\n%s", max(1, opts.Trials), counts)
+		}
+
+		if table != nil {
+
+			runtime, missing := counts.Runtime(table)
+			fmt.Fprintf(os.Stderr, "runtime: %g per trial\n", runtime/float64(max(1, opts.Trials)))
+
+			if len(missing) != 0 {
+				fmt.Fprintf(os.Stderr, "warning: operations without cost: %v\n", missing)
+			}
+		}
+	}
+
+	if opts.Keys != nil {
+		if err = writeKeys(*keys, c, opts); err != nil {
+			return
+		}
+	}
+
+	return writeFile(cfg, results...)
+}
+
+// requiredKeys are the evaluation keys written by the -keys flag of the circuit subcommand.
+type requiredKeys struct {
+	Residual      estimator.RequiredKeys
+	Bootstrapping *estimator.RequiredKeys `json:",omitempty"`
+}
+
+// writeKeys writes the evaluation keys recorded by opts to the file.
+func writeKeys(file string, c circuit.Circuit, opts circuit.Options) (err error) {
+
+	keys := requiredKeys{Residual: opts.Keys.Keys()}
+
+	if c.Bootstrapping != nil {
+		btpKeys := opts.BootstrappingKeys.Keys()
+		keys.Bootstrapping = &btpKeys
+	}
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	if err = os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return
+}
+
+// maxOverflows is the number of overflows printed by the circuit subcommand.
+const maxOverflows = 16
+
+// isSet returns true if the flag was given.
+func isSet(fs *flag.FlagSet, name string) (set bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return
+}
@@ -0,0 +1,833 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/dft"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/mod1"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/polynomial"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func init() {
+	register(
+		experiment{
+			name:        "poly_eval",
+			description: "evaluation of a degree 255 approximation of the sigmoid in [-32, 32]",
+			params: ckks.ParametersLiteral{
+				LogN:            16,
+				LogQ:            []int{60, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 55,
+			},
+			inputs: inputs{-31, 31, true},
+			setup:  setupPolyEval(0),
+		},
+		experiment{
+			name:        "poly_eval_bounds",
+			description: "poly_eval with inputs, coefficients and decryption rounded to multiples of 3/Delta",
+			params: ckks.ParametersLiteral{
+				LogN:            16,
+				LogQ:            []int{60, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 55,
+			},
+			inputs: inputs{-31, 31, true},
+			setup:  setupPolyEval(3),
+		},
+		experiment{
+			name:        "iterative_step",
+			description: "seven evaluations of the step polynomial 3x^2 - 2x^3 on inputs close to 0 or 1",
+			params: ckks.ParametersLiteral{
+				LogN:            16,
+				LogQ:            []int{60, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogP:            []int{61, 61, 61, 61, 61},
+				LogDefaultScale: 55,
+				Xs:              ring.Ternary{H: 192},
+			},
+			inputs: inputs{-0.2, 0.2, true},
+			setup:  setupIterativeStep,
+		},
+		experiment{
+			name:        "mod1",
+			description: "homomorphic modular reduction (EvalMod) of the bootstrapping",
+			params: ckks.ParametersLiteral{
+				LogN:            16,
+				LogQ:            []int{55, 60, 60, 60, 60, 60, 60, 60, 60, 53},
+				LogP:            []int{61, 61, 61, 61},
+				LogDefaultScale: 45,
+				Xs:              ring.Ternary{H: 192},
+			},
+			setup: setupMod1,
+		},
+		experiment{
+			name:        "coeffs_to_slots",
+			description: "homomorphic encoding (CoeffsToSlots) of the bootstrapping",
+			params: ckks.ParametersLiteral{
+				LogN:            14,
+				LogQ:            []int{55, 55, 55, 55, 55},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 55,
+			},
+			setup: setupDFT(dft.HomomorphicEncode),
+		},
+		experiment{
+			name:        "slots_to_coeffs",
+			description: "homomorphic decoding (SlotsToCoeffs) of the bootstrapping",
+			params: ckks.ParametersLiteral{
+				LogN:            14,
+				LogQ:            []int{60, 45, 45, 45, 45},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 45,
+			},
+			inputs: inputs{-1, 1, true},
+			setup:  setupDFT(dft.HomomorphicDecode),
+		},
+		experiment{
+			name:        "bootstrapping",
+			description: "full bootstrapping",
+			params: ckks.ParametersLiteral{
+				LogN:            14,
+				LogQ:            []int{55, 45},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 45,
+			},
+			btpParams: bootstrapping.ParametersLiteral{
+				LogP: []int{61, 61, 61, 61},
+			},
+			setup: setupBootstrapping,
+		},
+		experiment{
+			name:        "bootstrapping_conjugate_invariant",
+			description: "bootstrapping of two ciphertexts of the conjugate-invariant ring",
+			params: ckks.ParametersLiteral{
+				LogN:            12,
+				LogQ:            []int{55, 45},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 45,
+				RingType:        ring.ConjugateInvariant,
+			},
+			btpParams: bootstrapping.ParametersLiteral{
+				LogP: []int{61, 61, 61, 61},
+			},
+			inputs:   inputs{-1, 1, true},
+			setup:    setupBootstrappingMany,
+			variants: []string{"ct0", "ct1"},
+		},
+		experiment{
+			name:        "bootstrapping_many",
+			description: "bootstrapping of two real ciphertexts packed as ct0 + i * ct1",
+			params: ckks.ParametersLiteral{
+				LogN:            14,
+				LogQ:            []int{55, 45},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 45,
+			},
+			btpParams: bootstrapping.ParametersLiteral{
+				LogP: []int{61, 61, 61, 61},
+			},
+			inputs:   inputs{-1, 1, true},
+			setup:    setupBootstrappingMany,
+			variants: []string{"ct0", "ct1"},
+		},
+		experiment{
+			name:        "bootstrapping_sweep",
+			description: "predicted precision, depth and failure probability of a grid of bootstrapping parameters",
+			params: ckks.ParametersLiteral{
+				LogN:            14,
+				LogQ:            []int{55, 45},
+				LogP:            []int{61, 61, 61},
+				LogDefaultScale: 45,
+			},
+			btpParams: bootstrapping.ParametersLiteral{
+				LogP: []int{61, 61, 61, 61},
+			},
+			sweep: &bootEst.SweepParameters{
+				LogMessageRatio:     []int{6, 8},
+				K:                   []int{12, 16},
+				DoubleAngle:         []int{2, 3},
+				CoeffsToSlotsLevels: []int{3, 4},
+			},
+		},
+	)
+}
+
+// setupPolyEval returns the setup of the evaluation of the sigmoid. If trunc is not zero,
+// the inputs, the coefficients and the decrypted plaintext are rounded to multiples of
+// trunc/Delta, where Delta is the default scale.
+func setupPolyEval(trunc int64) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+		eval := ckks.NewEvaluator(env.params, evk)
+
+		polyEval := polynomial.NewEvaluator(env.params, eval)
+
+		K := 32.0
+
+		sigmoid := func(x float64) (y float64) {
+			return 1 / (math.Exp(-x) + 1)
+		}
+
+		poly := polynomial.NewPolynomial(chebyshevApproximation(K, 255, sigmoid))
+
+		var truncs []*big.Int
+		if trunc != 0 {
+
+			truncs = []*big.Int{big.NewInt(trunc)}
+
+			scale := env.params.DefaultScale()
+			delta := new(big.Float).Quo(&scale.Value, new(big.Float).SetInt64(trunc))
+
+			for _, c := range poly.Coeffs {
+				estimator.Truncate(c[0], delta)
+				estimator.Truncate(c[1], delta)
+			}
+		}
+
+		scalar, constant := poly.ChangeOfBasis()
+
+		return func() ([]outcome, error) {
+
+			values, el, _, ct := env.est.NewTestVectorFromSeed(env.ecd, env.pk, env.a, env.b, env.source, truncs...)
+
+			for i := range values {
+				values[i] = poly.Evaluate(values[i])
+			}
+
+			if scalar.Cmp(new(big.Float).SetInt64(1)) != 0 {
+
+				if err := env.est.Mul(el, scalar, el); err != nil {
+					return nil, err
+				}
+
+				if err := env.est.Add(el, constant, el); err != nil {
+					return nil, err
+				}
+
+				if err := env.est.Rescale(el, el); err != nil {
+					return nil, err
+				}
+
+				if err := polyEval.Mul(ct, scalar, ct); err != nil {
+					return nil, err
+				}
+
+				if err := polyEval.Add(ct, constant, ct); err != nil {
+					return nil, err
+				}
+
+				if err := polyEval.Rescale(ct, ct); err != nil {
+					return nil, err
+				}
+			}
+
+			el, err := env.est.EvaluatePolynomialNew(el, poly, el.Scale)
+			if err != nil {
+				return nil, err
+			}
+
+			if ct, err = polyEval.Evaluate(ct, poly, ct.Scale); err != nil {
+				return nil, err
+			}
+
+			if len(truncs) == 0 {
+				return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+			}
+
+			pt := env.dec.DecryptNew(ct)
+			env.est.TruncatePlaintext(pt, truncs[0])
+
+			return []outcome{{values, env.est.Decrypt(el), pt}}, nil
+		}, nil
+	}
+}
+
+// chebyshevApproximation returns the Chebyshev approximation
+// of f in the interval [-K, K] with the given degree.
+func chebyshevApproximation(K float64, degree int, f func(x float64) (y float64)) bignum.Polynomial {
+
+	FBig := func(x *big.Float) (y *big.Float) {
+		xF64, _ := x.Float64()
+		return new(big.Float).SetPrec(x.Prec()).SetFloat64(f(xF64))
+	}
+
+	var prec uint = 128
+
+	interval := bignum.Interval{
+		A:     *bignum.NewFloat(-K, prec),
+		B:     *bignum.NewFloat(K, prec),
+		Nodes: degree,
+	}
+
+	return bignum.ChebyshevApproximation(FBig, interval)
+}
+
+func setupMod1(env *env) (trial, error) {
+
+	evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+	eval := ckks.NewEvaluator(env.params, evk)
+
+	evm, err := mod1.NewParametersFromLiteral(env.params, mod1.ParametersLiteral{
+		LevelQ:          env.params.MaxLevel() - 1,
+		Mod1Type:        mod1.CosDiscrete,
+		LogMessageRatio: 8,
+		K:               12,
+		Mod1Degree:      30,
+		DoubleAngle:     3,
+		LogScale:        60,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("mod1.NewParametersFromLiteral: %w", err)
+	}
+
+	mod1Eval := mod1.NewEvaluator(eval, polynomial.NewEvaluator(env.params, eval), evm)
+
+	return func() ([]outcome, error) {
+
+		values, el, ct, err := newTestVectorMod1(env, evm)
+		if err != nil {
+			return nil, err
+		}
+
+		// Scale the message to Delta = Q/MessageRatio
+		scale := rlwe.NewScale(math.Exp2(math.Round(math.Log2(float64(env.params.Q()[0]) / evm.MessageRatio()))))
+		scale = scale.Div(ct.Scale)
+
+		if err = eval.ScaleUp(ct, rlwe.NewScale(math.Round(scale.Float64())), ct); err != nil {
+			return nil, err
+		}
+
+		if err = env.est.ScaleUp(el, rlwe.NewScale(math.Round(scale.Float64()))); err != nil {
+			return nil, err
+		}
+
+		// Scale the message up to Sine/MessageRatio
+		scale = evm.ScalingFactor().Div(ct.Scale)
+		scale = scale.Div(rlwe.NewScale(evm.MessageRatio()))
+
+		if err = eval.ScaleUp(ct, rlwe.NewScale(math.Round(scale.Float64())), ct); err != nil {
+			return nil, err
+		}
+
+		if err = env.est.ScaleUp(el, rlwe.NewScale(math.Round(scale.Float64()))); err != nil {
+			return nil, err
+		}
+
+		// Normalization
+		if err = eval.Mul(ct, 1/(evm.K*evm.QDiff), ct); err != nil {
+			return nil, err
+		}
+
+		if err = env.est.Mul(el, 1/(evm.K*evm.QDiff), el); err != nil {
+			return nil, err
+		}
+
+		if err = eval.Rescale(ct, ct); err != nil {
+			return nil, err
+		}
+
+		if err = env.est.Rescale(el, el); err != nil {
+			return nil, err
+		}
+
+		if ct, err = mod1Eval.EvaluateNew(ct); err != nil {
+			return nil, err
+		}
+
+		if el, err = env.est.EvaluateMod1New(el, evm); err != nil {
+			return nil, err
+		}
+
+		ratio := new(big.Float).SetPrec(256).SetFloat64(evm.MessageRatio() * evm.QDiff / (2 * math.Pi))
+		for j := range values {
+			values[j][0].Quo(values[j][0], ratio)
+			values[j][0] = bignum.Sin(values[j][0])
+			values[j][0].Mul(values[j][0], ratio)
+		}
+
+		return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// newTestVectorMod1 returns a test vector whose values are
+// I * Q + m with I in [-K+1, K-1] and m in the range of the inputs.
+func newTestVectorMod1(env *env, evm mod1.Parameters) (values []*bignum.Complex, el *estimator.Element, ct *rlwe.Ciphertext, err error) {
+
+	params := env.params
+
+	values = make([]*bignum.Complex, params.MaxSlots())
+
+	K := evm.K - 1
+	Q := evm.QDiff * evm.MessageRatio()
+
+	for i := range values {
+		values[i] = bignum.ToComplex(math.Round(env.source.Float64(-K, K))*Q+env.source.Float64(real(env.a), real(env.b)), 64)
+	}
+
+	values[0] = bignum.ToComplex(K*Q+0.5, 64)
+
+	el = env.est.NewElement(values, 1, env.est.MaxLevel(), env.est.DefaultScale())
+	env.est.AddEncodingNoise(el)
+
+	pt := ckks.NewPlaintext(params, params.MaxLevel())
+	if err = env.ecd.Encode(values, pt); err != nil {
+		return nil, nil, nil, fmt.Errorf("ecd.Encode: %w", err)
+	}
+
+	if ct, err = rlwe.NewEncryptor(params, env.pk).EncryptNew(pt); err != nil {
+		return nil, nil, nil, fmt.Errorf("enc.EncryptNew: %w", err)
+	}
+
+	env.est.AddEncryptionNoisePk(el)
+
+	return
+}
+
+// setupDFT returns the setup of the CoeffsToSlots (dft.HomomorphicEncode)
+// or SlotsToCoeffs (dft.HomomorphicDecode) linear transformation.
+func setupDFT(t dft.Type) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		params := env.params
+
+		var prec uint = 128
+
+		mulCmplx := bignum.NewComplexMultiplier().Mul
+
+		add := func(a, b, c []*bignum.Complex) {
+			for i := range c {
+				if a[i] != nil && b[i] != nil {
+					c[i].Add(a[i], b[i])
+				}
+			}
+		}
+
+		muladd := func(a, b, c []*bignum.Complex) {
+			tmp := &bignum.Complex{new(big.Float), new(big.Float)}
+			for i := range c {
+				if a[i] != nil && b[i] != nil {
+					mulCmplx(a[i], b[i], tmp)
+					c[i].Add(c[i], tmp)
+				}
+			}
+		}
+
+		newVec := func(size int) (vec []*bignum.Complex) {
+			vec = make([]*bignum.Complex, size)
+			for i := range vec {
+				vec[i] = &bignum.Complex{new(big.Float).SetPrec(prec), new(big.Float).SetPrec(prec)}
+			}
+			return
+		}
+
+		matLit := dft.MatrixLiteral{
+			LogSlots: params.LogMaxSlots(),
+			Type:     t,
+			Format:   dft.RepackImagAsReal,
+			LevelQ:   params.MaxLevelQ(),
+			LevelP:   params.MaxLevelP(),
+			Levels:   []int{1, 1, 1, 1},
+		}
+
+		matEst := estimator.DFTMatrix{MatrixLiteral: matLit}
+		matEst.GenMatrices(params.LogN(), prec)
+
+		mat, err := dft.NewMatrixFromLiteral(params, matLit, env.ecd)
+		if err != nil {
+			return nil, fmt.Errorf("dft.NewMatrixFromLiteral: %w", err)
+		}
+
+		galEls := mat.GaloisElements(params)
+		if t == dft.HomomorphicEncode {
+			galEls = append(galEls, params.GaloisElementForComplexConjugation())
+		}
+
+		evk := rlwe.NewMemEvaluationKeySet(nil, env.kgen.GenGaloisKeysNew(galEls, env.sk)...)
+
+		dftEval := dft.NewEvaluator(params, ckks.NewEvaluator(params, evk))
+
+		evaluate := func(values []*bignum.Complex) []*bignum.Complex {
+			for i := range matEst.Value {
+				values = matEst.Value[i].Evaluate(values, newVec, add, muladd)
+			}
+			return values
+		}
+
+		if t == dft.HomomorphicDecode {
+			return func() ([]outcome, error) {
+
+				valuesReal, elReal, _, ctReal := env.newTestVector(env.sk)
+				valuesImag, elImag, _, ctImag := env.newTestVector(env.sk)
+
+				ct, err := dftEval.SlotsToCoeffsNew(ctReal, ctImag, mat)
+				if err != nil {
+					return nil, err
+				}
+
+				for i := range valuesReal {
+					valuesReal[i][1].Set(valuesImag[i][0])
+				}
+
+				values := evaluate(valuesReal)
+
+				el, err := env.est.SlotsToCoeffsNew(elReal, elImag, matEst)
+				if err != nil {
+					return nil, err
+				}
+
+				return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+			}, nil
+		}
+
+		return func() ([]outcome, error) {
+
+			values, el, _, ct := env.newTestVector(env.pk)
+
+			elReal, elImag, err := env.est.CoeffsToSlotsNew(el, matEst)
+			if err != nil {
+				return nil, err
+			}
+
+			ctReal, ctImag, err := dftEval.CoeffsToSlotsNew(ct, mat)
+			if err != nil {
+				return nil, err
+			}
+
+			values = evaluate(values)
+
+			if elImag == nil {
+				return []outcome{{values, env.est.Decrypt(elReal), ctReal}}, nil
+			}
+
+			two := new(big.Float).SetInt64(2)
+
+			valuesReal := make([]*bignum.Complex, len(values))
+			valuesImag := make([]*bignum.Complex, len(values))
+			for i := range values {
+				valuesReal[i] = &bignum.Complex{new(big.Float).Mul(values[i][0], two), new(big.Float)}
+				valuesImag[i] = &bignum.Complex{new(big.Float).Mul(values[i][1], two), new(big.Float)}
+			}
+
+			return []outcome{
+				{valuesReal, env.est.Decrypt(elReal), ctReal},
+				{valuesImag, env.est.Decrypt(elImag), ctImag},
+			}, nil
+		}, nil
+	}
+}
+
+func setupBootstrapping(env *env) (trial, error) {
+
+	params := env.params
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, bootstrappingLiteral(params, env.btpParams))
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping.NewParametersFromLiteral: %w", err)
+	}
+
+	evk, _, err := btpParams.GenEvaluationKeys(env.sk)
+	if err != nil {
+		return nil, fmt.Errorf("btpParams.GenEvaluationKeys: %w", err)
+	}
+
+	eval, err := bootstrapping.NewEvaluator(btpParams, evk)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping.NewEvaluator: %w", err)
+	}
+
+	evalEst := bootEst.NewEvaluatorFromSeed(btpParams, env.seed)
+	evalEst.ResidualParameters.Heuristic = env.heuristic
+	evalEst.BootstrappingParameters.Heuristic = env.heuristic
+	evalEst.ResidualParameters.Rand = env.source.Rand
+	evalEst.BootstrappingParameters.Rand = env.source.Rand
+
+	est := evalEst.ResidualParameters
+
+	return func() ([]outcome, error) {
+
+		values, el, _, ct := est.NewTestVectorFromSeed(env.ecd, env.pk, env.a, env.b, env.source)
+
+		ct, err := eval.Bootstrap(ct)
+		if err != nil {
+			return nil, err
+		}
+
+		if el, err = evalEst.Bootstrap(el); err != nil {
+			return nil, err
+		}
+
+		return []outcome{{values, est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// bootstrappingLiteral returns btpLit with the ring degree and, if it is not set, the secret
+// distribution of params. The ring.Standard ring of the bootstrapping has twice the degree
+// of a ring.ConjugateInvariant residual ring.
+func bootstrappingLiteral(params ckks.Parameters, btpLit bootstrapping.ParametersLiteral) bootstrapping.ParametersLiteral {
+
+	logN := params.LogN()
+	if params.RingType() == ring.ConjugateInvariant {
+		logN++
+	}
+
+	btpLit.LogN = utils.Pointy(logN)
+
+	if btpLit.Xs == nil {
+		btpLit.Xs = params.Xs()
+	}
+
+	return btpLit
+}
+
+// setupBootstrappingMany returns the setup of the bootstrapping of two real ciphertexts,
+// with Evaluator.BootstrapMany for the ring.ConjugateInvariant ring and packed as
+// ct0 + i * ct1 and unpacked with a conjugation for the ring.Standard ring.
+func setupBootstrappingMany(env *env) (trial, error) {
+
+	params := env.params
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, bootstrappingLiteral(params, env.btpParams))
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping.NewParametersFromLiteral: %w", err)
+	}
+
+	evk, _, err := btpParams.GenEvaluationKeys(env.sk)
+	if err != nil {
+		return nil, fmt.Errorf("btpParams.GenEvaluationKeys: %w", err)
+	}
+
+	eval, err := bootstrapping.NewEvaluator(btpParams, evk)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping.NewEvaluator: %w", err)
+	}
+
+	evalEst := bootEst.NewEvaluatorFromSeed(btpParams, env.seed)
+	evalEst.ResidualParameters.Heuristic = env.heuristic
+	evalEst.BootstrappingParameters.Heuristic = env.heuristic
+	evalEst.ResidualParameters.Rand = env.source.Rand
+	evalEst.BootstrappingParameters.Rand = env.source.Rand
+
+	est := evalEst.ResidualParameters
+
+	bootstrapMany := func(ct0, ct1 *rlwe.Ciphertext) (*rlwe.Ciphertext, *rlwe.Ciphertext, error) {
+		cts, err := eval.BootstrapMany([]rlwe.Ciphertext{*ct0, *ct1})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &cts[0], &cts[1], nil
+	}
+
+	if params.RingType() == ring.Standard {
+
+		// Packing and unpacking are done in the residual parameters
+		evalResidual := ckks.NewEvaluator(params, rlwe.NewMemEvaluationKeySet(nil, env.kgen.GenGaloisKeyNew(params.GaloisElementForComplexConjugation(), env.sk)))
+
+		bootstrapMany = func(ct0, ct1 *rlwe.Ciphertext) (*rlwe.Ciphertext, *rlwe.Ciphertext, error) {
+
+			// ct0 + i * ct1
+			if err := evalResidual.Mul(ct1, 1i, ct1); err != nil {
+				return nil, nil, err
+			}
+
+			if err := evalResidual.Add(ct0, ct1, ct0); err != nil {
+				return nil, nil, err
+			}
+
+			ct0, err := eval.Bootstrap(ct0)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// (ct + conj(ct))/2 and (ct - conj(ct))/2i
+			ctConj, err := evalResidual.ConjugateNew(ct0)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if ct1, err = evalResidual.SubNew(ct0, ctConj); err != nil {
+				return nil, nil, err
+			}
+
+			if err = evalResidual.Mul(ct1, -1i, ct1); err != nil {
+				return nil, nil, err
+			}
+
+			if err = evalResidual.Add(ct0, ctConj, ct0); err != nil {
+				return nil, nil, err
+			}
+
+			ct0.Scale = ct0.Scale.Mul(rlwe.NewScale(2))
+			ct1.Scale = ct1.Scale.Mul(rlwe.NewScale(2))
+
+			return ct0, ct1, nil
+		}
+	}
+
+	return func() ([]outcome, error) {
+
+		values0, el0, _, ct0 := est.NewTestVectorFromSeed(env.ecd, env.pk, env.a, env.b, env.source)
+		values1, el1, _, ct1 := est.NewTestVectorFromSeed(env.ecd, env.pk, env.a, env.b, env.source)
+
+		ct0, ct1, err := bootstrapMany(ct0, ct1)
+		if err != nil {
+			return nil, err
+		}
+
+		els, err := evalEst.BootstrapMany([]*estimator.Element{el0, el1})
+		if err != nil {
+			return nil, err
+		}
+
+		return []outcome{
+			{values0, est.Decrypt(els[0]), ct0},
+			{values1, est.Decrypt(els[1]), ct1},
+		}, nil
+	}, nil
+}
+
+// runSweep writes the predicted precision of the bootstrapping for each combination of
+// the ranges of exp.sweep, with the trials as the number of samples of each candidate.
+// The candidates that are not dominated by another one (see bootEst.ParetoFront) are
+// marked as Pareto-optimal.
+func runSweep(exp experiment, params ckks.Parameters, cfg config) (err error) {
+
+	sweep := *exp.sweep
+	sweep.Samples = cfg.trials
+	sweep.Heuristic = cfg.heuristic
+	sweep.Seed = &cfg.seed
+
+	candidates, err := bootEst.Sweep(params, bootstrappingLiteral(params, cfg.btpParams), sweep)
+	if err != nil {
+		return fmt.Errorf("bootEst.Sweep: %w", err)
+	}
+
+	var results []estimator.Result
+
+	for _, c := range candidates {
+
+		if c.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", exp.name, c)
+			continue
+		}
+
+		lit := c.ParametersLiteral
+
+		name := fmt.Sprintf("%s LogMessageRatio=%d K=%d Mod1Degree=%d DoubleAngle=%d Mod1InvDegree=%d C2S=%d S2C=%d Depth=%d log2(Pfail)=%.2f",
+			exp.name, *lit.LogMessageRatio, *lit.K, *lit.Mod1Degree, *lit.DoubleAngle, *lit.Mod1InvDegree,
+			len(lit.CoeffsToSlotsFactorizationDepthAndLogScales), len(lit.SlotsToCoeffsFactorizationDepthAndLogScales),
+			c.Depth, c.Log2FailureProbability)
+
+		pareto := true
+		for _, other := range candidates {
+			if other.Err == nil && other.Dominates(c) {
+				pareto = false
+				break
+			}
+		}
+
+		if pareto {
+			name += " Pareto-optimal"
+		}
+
+		results = append(results, estimator.NewResult(name, params, cfg.seed, c.Stats, estimator.Stats{}))
+	}
+
+	if len(results) == 0 {
+		return fmt.Errorf("no valid candidate")
+	}
+
+	return writeFile(cfg, results...)
+}
+
+// setupIterativeStep returns the setup of seven evaluations of the polynomial 3x^2 - 2x^3,
+// which moves the inputs, close to 0 or 1, closer to 0 or 1.
+func setupIterativeStep(env *env) (trial, error) {
+
+	evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+	polyEval := polynomial.NewEvaluator(env.params, ckks.NewEvaluator(env.params, evk))
+
+	coeffs := []*big.Float{
+		bignum.NewFloat(0, 64),
+		bignum.NewFloat(0, 64),
+		bignum.NewFloat(3, 64),
+		bignum.NewFloat(-2, 64),
+	}
+
+	poly := bignum.NewPolynomial(bignum.Monomial, coeffs, nil)
+
+	tiny := new(big.Float).SetFloat64(1e-40)
+
+	return func() ([]outcome, error) {
+
+		values, el, ct, err := newTestVectorIterativeStep(env)
+		if err != nil {
+			return nil, err
+		}
+
+		for k := 0; k < 7; k++ {
+
+			for i := range values {
+				if values[i] = poly.Evaluate(values[i]); values[i][0].Cmp(tiny) == -1 {
+					values[i][0].SetFloat64(0)
+				}
+			}
+
+			if el, err = env.est.EvaluatePolynomialNew(el, poly, el.Scale); err != nil {
+				return nil, err
+			}
+
+			if ct, err = polyEval.Evaluate(ct, poly, ct.Scale); err != nil {
+				return nil, err
+			}
+		}
+
+		return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// newTestVectorIterativeStep returns a test vector whose values are
+// round(x) + m with x in [0, 1] and m in the range of the inputs.
+func newTestVectorIterativeStep(env *env) (values []*bignum.Complex, el *estimator.Element, ct *rlwe.Ciphertext, err error) {
+
+	params := env.params
+
+	values = make([]*bignum.Complex, params.MaxSlots())
+
+	for i := range values {
+		values[i] = bignum.ToComplex(math.Round(env.source.Float64(0, 1))+env.source.Float64(real(env.a), real(env.b)), env.ecd.Prec())
+	}
+
+	values[0] = bignum.ToComplex(1, env.ecd.Prec())
+
+	el = env.est.NewElement(values, 1, env.est.MaxLevel(), env.est.DefaultScale())
+	env.est.AddEncodingNoise(el)
+
+	pt := ckks.NewPlaintext(params, params.MaxLevel())
+	if err = env.ecd.Encode(values, pt); err != nil {
+		return nil, nil, nil, fmt.Errorf("ecd.Encode: %w", err)
+	}
+
+	if ct, err = rlwe.NewEncryptor(params, env.pk).EncryptNew(pt); err != nil {
+		return nil, nil, nil, fmt.Errorf("enc.EncryptNew: %w", err)
+	}
+
+	env.est.AddEncryptionNoisePk(el)
+
+	return
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+	"github.com/tuneinsight/ckks-noise-estimator/optimizer"
+)
+
+// decomposition compares the auxiliary moduli P of the key-switching keys of the
+// circuit given with -f and prints the candidates and the recommended one.
+func decomposition(args []string) (err error) {
+
+	fs := flag.NewFlagSet("decomposition", flag.ContinueOnError)
+
+	file := fs.String("f", "", "JSON or YAML file describing the circuit (see package circuit)")
+	target := fs.Float64("target", 0, "minimum predicted precision of the outputs in bits (default: half a bit below the best)")
+	stat := fs.String("stat", string(estimator.AVGLog2Prec), "statistic compared with the target")
+	minSecurity := fs.Float64("security", 128, "minimum bit-security")
+	modelName := fs.String("model", "bkzsieve", "security estimate: coresvp, quantum, bkzsieve or standard (table of the HomomorphicEncryption.org standard)")
+	logP := fs.String("logp", "61", "comma-separated candidate bit-sizes of the primes Pi")
+	pCount := fs.String("pcount", "", "comma-separated candidate numbers of primes Pi (default: 1 to the number of primes Qi)")
+	trials := fs.Int("trials", 1, "number of trials per candidate")
+	seed := fs.Int64("seed", 0, "seed of the inputs and of the estimator (default: random)")
+	heuristic := fs.Bool("heuristic", true, "use the heuristic noise model of the estimator")
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if *file == "" {
+		return fmt.Errorf("missing -f")
+	}
+
+	opts := optimizer.Options{
+		Target:      *target,
+		Statistic:   estimator.Statistic(*stat),
+		MinSecurity: *minSecurity,
+		Trials:      *trials,
+		Seed:        *seed,
+		Heuristic:   *heuristic,
+	}
+
+	if opts.Security, err = securityFunc(*modelName); err != nil {
+		return
+	}
+
+	if !isSet(fs, "seed") {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	if opts.LogP, err = parseInts(*logP); err != nil {
+		return fmt.Errorf("invalid -logp: %w", err)
+	}
+
+	if *pCount != "" {
+		if opts.PCount, err = parseInts(*pCount); err != nil {
+			return fmt.Errorf("invalid -pcount: %w", err)
+		}
+	}
+
+	c, err := circuit.Load(*file)
+	if err != nil {
+		return fmt.Errorf("circuit.Load: %w", err)
+	}
+
+	candidates, best, err := optimizer.Decompositions(c, opts)
+	if err != nil {
+		return fmt.Errorf("optimizer.Decompositions: %w", err)
+	}
+
+	for i, d := range candidates {
+		mark := " "
+		if i == best {
+			mark = "*"
+		}
+		fmt.Printf("%s %s\n", mark, d)
+	}
+
+	if best == -1 {
+		return fmt.Errorf("no candidate meets the target precision with %.0f-bit security", opts.MinSecurity)
+	}
+
+	fmt.Printf("\nrecommended: LogP=%v (dnum=%d)\n", candidates[best].LogP, candidates[best].DNum)
+
+	return
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+	"github.com/tuneinsight/lattigo/v6/utils/sampling"
+)
+
+func init() {
+
+	params := ckks.ParametersLiteral{
+		LogN:            14,
+		LogQ:            []int{55, 45},
+		LogP:            []int{60},
+		LogDefaultScale: 45,
+	}
+
+	register(
+		experiment{
+			name:        "flooding",
+			description: "mul_relin_rescale followed by the flooding noise of 16 decryption queries at 32 bits of security",
+			params:      params,
+			setup:       setupFlooding(16, 32),
+		},
+		experiment{
+			name:        "decrypt_rounded",
+			description: "mul_relin_rescale followed by a decryption rounded around the noise, with margins from -4 to 4 bits",
+			params:      params,
+			setup:       setupDecryptRounded(-4, -2, 0, 2, 4),
+			variants:    []string{"margin=-4", "margin=-2", "margin=0", "margin=2", "margin=4"},
+		},
+	)
+}
+
+// mulRelinRescale returns the product of two test vectors, relinearized and rescaled.
+func mulRelinRescale(env *env, eval *ckks.Evaluator) (values []*bignum.Complex, el *estimator.Element, ct *rlwe.Ciphertext, err error) {
+
+	values, el, _, ct = env.newTestVector(env.pk)
+	values1, el1, _, ct1 := env.newTestVector(env.pk)
+
+	mul := bignum.NewComplexMultiplier().Mul
+
+	for j := range values {
+		mul(values[j], values1[j], values[j])
+	}
+
+	if err = eval.MulRelin(ct, ct1, ct); err != nil {
+		return
+	}
+
+	if err = eval.Rescale(ct, ct); err != nil {
+		return
+	}
+
+	if err = env.est.MulRelin(el, el1, el); err != nil {
+		return
+	}
+
+	err = env.est.Rescale(el, el)
+
+	return
+}
+
+// setupFlooding returns the setup of the flooding of the decryption of a product,
+// for the given number of decryption queries and security level (see Estimator.FloodingNoise).
+// The flooding noise of Lattigo is sampled from a secure source.
+func setupFlooding(queries int, securityLevel float64) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+		eval := ckks.NewEvaluator(env.params, evk)
+
+		prng, err := sampling.NewPRNG()
+		if err != nil {
+			return nil, fmt.Errorf("sampling.NewPRNG: %w", err)
+		}
+
+		return func() ([]outcome, error) {
+
+			values, el, ct, err := mulRelinRescale(env, eval)
+			if err != nil {
+				return nil, err
+			}
+
+			flooding, err := env.est.FloodingNoise(el, values, queries, securityLevel)
+			if err != nil {
+				return nil, err
+			}
+
+			ringQ := env.params.RingQ().AtLevel(ct.Level())
+
+			sampler, err := ring.NewSampler(prng, ringQ, ring.DiscreteGaussian{Sigma: flooding.Sigma, Bound: 6 * flooding.Sigma}, false)
+			if err != nil {
+				return nil, fmt.Errorf("ring.NewSampler: %w", err)
+			}
+
+			noise := sampler.ReadNew()
+			ringQ.NTT(noise, noise)
+			ringQ.Add(ct.Value[0], noise, ct.Value[0])
+
+			env.est.AddFloodingNoise(el, flooding.Sigma)
+
+			return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+		}, nil
+	}
+}
+
+// setupDecryptRounded returns the setup of the decryption of a product rounded around
+// its noise with each of the given margins (see Estimator.DecryptRoundedBelowNoise).
+func setupDecryptRounded(margins ...int) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+		eval := ckks.NewEvaluator(env.params, evk)
+
+		return func() ([]outcome, error) {
+
+			values, el, ct, err := mulRelinRescale(env, eval)
+			if err != nil {
+				return nil, err
+			}
+
+			outcomes := make([]outcome, len(margins))
+
+			for i, margin := range margins {
+
+				d, err := env.est.DecryptRoundedBelowNoise(el, values, margin)
+				if err != nil {
+					return nil, err
+				}
+
+				// The coefficients of the plaintext are integers
+				pt := env.dec.DecryptNew(ct)
+				if d.LogRound > 0 {
+					env.est.TruncatePlaintext(pt, new(big.Int).Lsh(big.NewInt(1), uint(d.LogRound)))
+				}
+
+				outcomes[i] = outcome{values, d.Values, pt}
+			}
+
+			return outcomes, nil
+		}, nil
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/tuneinsight/ckks-noise-estimator"
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// env is the environment shared by the trials of an experiment.
+type env struct {
+	params    ckks.Parameters
+	btpParams bootstrapping.ParametersLiteral
+	ecd       *ckks.Encoder
+	kgen      *rlwe.KeyGenerator
+	sk        *rlwe.SecretKey
+	pk        *rlwe.PublicKey
+	dec       *rlwe.Decryptor
+	est       estimator.Estimator
+	source    estimator.TestRand
+	seed      int64
+	a, b      complex128
+	heuristic bool
+}
+
+func newEnv(params ckks.Parameters, cfg config) *env {
+
+	kgen := rlwe.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPairNew()
+
+	source := estimator.NewTestRand(cfg.seed)
+
+	est := estimator.NewEstimatorFromSeed(params, cfg.seed)
+	est.Heuristic = cfg.heuristic
+	est.Rand = source.Rand
+
+	return &env{
+		params:    params,
+		btpParams: cfg.btpParams,
+		ecd:       ckks.NewEncoder(params),
+		kgen:      kgen,
+		sk:        sk,
+		pk:        pk,
+		dec:       rlwe.NewDecryptor(params, sk),
+		est:       est,
+		source:    source,
+		seed:      cfg.seed,
+		a:         cfg.a,
+		b:         cfg.b,
+		heuristic: cfg.heuristic,
+	}
+}
+
+// newTestVector returns a new test vector with values sampled from the seeded
+// source, encrypted with key (or only encoded if key is nil).
+func (env *env) newTestVector(key rlwe.EncryptionKey) (values []*bignum.Complex, el *estimator.Element, pt *rlwe.Plaintext, ct *rlwe.Ciphertext) {
+	return env.est.NewTestVectorFromSeed(env.ecd, key, env.a, env.b, env.source)
+}
+
+// outcome is an output of a trial: the expected values, the values
+// predicted by the estimator and the plaintext or ciphertext computed by Lattigo.
+type outcome struct {
+	want      []*bignum.Complex
+	predicted []*bignum.Complex
+	actual    interface{}
+}
+
+// trial runs one trial of an experiment.
+type trial func() ([]outcome, error)
+
+// inputs is the default range [min, max] of the real and imaginary parts of the inputs of an experiment.
+type inputs struct {
+	min, max float64
+	real     bool
+}
+
+// experiment is a subcommand of the command.
+type experiment struct {
+	name        string
+	description string
+	params      ckks.ParametersLiteral
+	btpParams   bootstrapping.ParametersLiteral
+	inputs      inputs // default: [-1, 1] + i[-1, 1]
+	setup       func(env *env) (trial, error)
+
+	// variants, if not empty, are the names of the outcomes of each trial,
+	// whose statistics are written as separate results.
+	variants []string
+
+	// tails, if not empty, are the models of the tail of the errors whose failure
+	// probabilities are written instead of the statistics of the precision.
+	tails []estimator.TailModel
+
+	// sweep, if not nil, are the ranges of the bootstrapping parameters whose predicted
+	// precision is written instead of the outcomes of the trials of setup.
+	sweep *bootEst.SweepParameters
+}
+
+// experiments are the experiments indexed by name.
+var experiments = map[string]experiment{}
+
+func register(exps ...experiment) {
+	for _, exp := range exps {
+		experiments[exp.name] = exp
+	}
+}
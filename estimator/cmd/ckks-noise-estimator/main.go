@@ -0,0 +1,118 @@
+// Command ckks-noise-estimator runs the experiments comparing the precision predicted
+// by the estimator with the precision of the same circuit evaluated with Lattigo.
+//
+// Usage:
+//
+//	ckks-noise-estimator <experiment> [flags]
+//	ckks-noise-estimator list
+//	ckks-noise-estimator circuit -f <circuit.json|circuit.yaml> [flags]
+//	ckks-noise-estimator optimize -f <circuit.json|circuit.yaml> -target <bits> [flags]
+//	ckks-noise-estimator decomposition -f <circuit.json|circuit.yaml> [flags]
+//	ckks-noise-estimator scales -f <circuit.json|circuit.yaml> [flags]
+//	ckks-noise-estimator security -params <params.json> | -f <circuit.json|circuit.yaml> [flags]
+//
+// The parameters of an experiment default to the ones of the corresponding program
+// under experiments/ and can be overridden with a JSON file (-params) and with flags.
+// The tail experiment writes the predicted and actual probabilities that the error of
+// a slot exceeds bounds instead of the precision, and bootstrapping_sweep writes the
+// predicted precision of each candidate, with the trials as the number of samples.
+// The circuit subcommand runs a circuit described in JSON or YAML (see package circuit)
+// and the optimize subcommand prints the cheapest parameters evaluating it with the
+// target precision (see package optimizer). The decomposition subcommand compares the
+// auxiliary moduli P, and thus the numbers of digits, of the key-switching keys of a
+// circuit and recommends one. The scales subcommand prints the circuit with the scales
+// of its inputs, constants and intermediate values planned by circuit.PlanScales. The
+// security subcommand prints the estimated bit-security of parameters and of the secrets
+// of their bootstrapping (see package security).
+// The seed drives the sampling of the inputs and of the secrets and noise of the
+// estimator: the keys and the encryption noise of Lattigo are always sampled from
+// a secure source.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+func main() {
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+
+	switch name {
+	case "list":
+		list()
+		return
+	case "circuit":
+		exit(name, runCircuit(os.Args[2:]))
+	case "optimize":
+		exit(name, optimize(os.Args[2:]))
+	case "decomposition":
+		exit(name, decomposition(os.Args[2:]))
+	case "scales":
+		exit(name, scales(os.Args[2:]))
+	case "security":
+		exit(name, estimateSecurity(os.Args[2:]))
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	}
+
+	exp, ok := experiments[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown experiment %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	exit(name, run(exp, os.Args[2:]))
+}
+
+// exit exits with the status 0 if err is nil or if the help of the flags was
+// requested, and otherwise prints err and exits with the status 1.
+func exit(name string, err error) {
+
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+	os.Exit(1)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: ckks-noise-estimator <experiment> [flags]\n")
+	fmt.Fprintf(os.Stderr, "       ckks-noise-estimator circuit -f <file> [flags]\n")
+	fmt.Fprintf(os.Stderr, "       ckks-noise-estimator optimize -f <file> -target <bits> [flags]\n")
+	fmt.Fprintf(os.Stderr, "       ckks-noise-estimator decomposition -f <file> [flags]\n")
+	fmt.Fprintf(os.Stderr, "       ckks-noise-estimator scales -f <file> [flags]\n")
+	fmt.Fprintf(os.Stderr, "       ckks-noise-estimator security -params <file> | -f <file> [flags]\n\n")
+	fmt.Fprintf(os.Stderr, "Run 'ckks-noise-estimator list' for the list of experiments\n")
+	fmt.Fprintf(os.Stderr, "and 'ckks-noise-estimator <experiment> -help' for its flags.\n")
+}
+
+func list() {
+
+	names := make([]string, 0, len(experiments))
+	for name := range experiments {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var width int
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	for _, name := range names {
+		fmt.Printf("%-*s %s\n", width, name, experiments[name].description)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"math/big"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func init() {
+
+	params := func(logN int, logQ []int) ckks.ParametersLiteral {
+		return ckks.ParametersLiteral{
+			LogN:            logN,
+			LogQ:            logQ,
+			LogP:            []int{60},
+			LogDefaultScale: 45,
+		}
+	}
+
+	register(
+		experiment{
+			name:        "encode",
+			description: "encoding",
+			params:      params(16, []int{55}),
+			setup:       setupEncode,
+		},
+		experiment{
+			name:        "enc_sk",
+			description: "secret-key encryption",
+			params:      params(16, []int{55}),
+			setup:       setupEncrypt(false),
+		},
+		experiment{
+			name:        "enc_pk",
+			description: "public-key encryption",
+			params:      params(16, []int{55}),
+			setup:       setupEncrypt(true),
+		},
+		experiment{
+			name:        "add_const",
+			description: "addition of a ciphertext and a constant",
+			params:      params(16, []int{55}),
+			setup:       setupAddConst,
+		},
+		experiment{
+			name:        "add_pt",
+			description: "addition of a ciphertext and a plaintext",
+			params:      params(16, []int{55}),
+			setup:       setupBinary(false, false, false),
+		},
+		experiment{
+			name:        "add_ct",
+			description: "addition of two ciphertexts",
+			params:      params(16, []int{55}),
+			setup:       setupBinary(true, false, false),
+		},
+		experiment{
+			name:        "mul_pt",
+			description: "multiplication of a ciphertext and a plaintext",
+			params:      params(16, []int{55, 45}),
+			inputs:      inputs{-1, 1, true},
+			setup:       setupBinary(false, true, false),
+		},
+		experiment{
+			name:        "mul_ct",
+			description: "multiplication of two ciphertexts without relinearization",
+			params:      params(16, []int{55, 45}),
+			setup:       setupBinary(true, true, false),
+		},
+		experiment{
+			name:        "mul_rescale_ct",
+			description: "multiplication of two ciphertexts without relinearization followed by a rescaling",
+			params:      params(13, []int{55, 45}),
+			setup:       setupBinary(true, true, true),
+		},
+		experiment{
+			name:        "mul_relin",
+			description: "multiplication of two ciphertexts with relinearization",
+			params:      params(16, []int{55, 45}),
+			setup:       setupMulRelin(false),
+		},
+		experiment{
+			name:        "mul_relin_rescale",
+			description: "multiplication of two ciphertexts with relinearization followed by a rescaling",
+			params:      params(14, []int{55, 45}),
+			setup:       setupMulRelin(true),
+		},
+		experiment{
+			name:        "tail",
+			description: "probability that the error of a slot of mul_relin_rescale exceeds a bound",
+			params:      params(12, []int{55, 45}),
+			setup:       setupMulRelin(true),
+			tails:       []estimator.TailModel{estimator.GaussianTail, estimator.ParetoTail},
+		},
+		experiment{
+			name:        "rotate",
+			description: "rotation of a ciphertext by one slot",
+			params:      params(16, []int{55}),
+			setup:       setupRotate,
+		},
+		experiment{
+			name:        "conjugate",
+			description: "complex conjugation of a ciphertext",
+			params:      params(16, []int{55}),
+			setup:       setupConjugate,
+		},
+	)
+}
+
+func setupEncode(env *env) (trial, error) {
+	return func() ([]outcome, error) {
+		values, el, pt, _ := env.newTestVector(nil)
+		return []outcome{{values, env.est.Decrypt(el), pt}}, nil
+	}, nil
+}
+
+func setupEncrypt(publicKey bool) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		var key rlwe.EncryptionKey = env.sk
+		if publicKey {
+			key = env.pk
+		}
+
+		return func() ([]outcome, error) {
+			values, el, _, ct := env.newTestVector(key)
+			return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+		}, nil
+	}
+}
+
+func setupAddConst(env *env) (trial, error) {
+
+	eval := ckks.NewEvaluator(env.params, nil)
+
+	return func() ([]outcome, error) {
+
+		scalar := complex(env.source.Float64(real(env.a), real(env.b)), env.source.Float64(imag(env.a), imag(env.b)))
+
+		values, el, _, ct := env.newTestVector(env.pk)
+
+		scalarR := new(big.Float).SetFloat64(real(scalar))
+		scalarI := new(big.Float).SetFloat64(imag(scalar))
+
+		for j := range values {
+			values[j][0].Add(values[j][0], scalarR)
+			values[j][1].Add(values[j][1], scalarI)
+		}
+
+		if err := eval.Add(ct, scalar, ct); err != nil {
+			return nil, err
+		}
+
+		if err := env.est.Add(el, scalar, el); err != nil {
+			return nil, err
+		}
+
+		return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// setupBinary returns the setup of the addition or multiplication (without relinearization)
+// of a ciphertext with a ciphertext or a plaintext, optionally followed by a rescaling.
+func setupBinary(ciphertext, mul, rescale bool) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		eval := ckks.NewEvaluator(env.params, nil)
+
+		mulCmplx := bignum.NewComplexMultiplier().Mul
+
+		return func() ([]outcome, error) {
+
+			values0, el0, _, ct0 := env.newTestVector(env.pk)
+
+			var key rlwe.EncryptionKey
+			if ciphertext {
+				key = env.pk
+			}
+
+			values1, el1, pt1, ct1 := env.newTestVector(key)
+
+			var op1 rlwe.Operand = pt1
+			if ciphertext {
+				op1 = ct1
+			}
+
+			for j := range values0 {
+				if mul {
+					mulCmplx(values0[j], values1[j], values0[j])
+				} else {
+					values0[j].Add(values0[j], values1[j])
+				}
+			}
+
+			if mul {
+
+				if err := eval.Mul(ct0, op1, ct0); err != nil {
+					return nil, err
+				}
+
+				if err := env.est.Mul(el0, el1, el0); err != nil {
+					return nil, err
+				}
+
+			} else {
+
+				if err := eval.Add(ct0, op1, ct0); err != nil {
+					return nil, err
+				}
+
+				if err := env.est.Add(el0, el1, el0); err != nil {
+					return nil, err
+				}
+			}
+
+			if rescale {
+
+				if err := eval.Rescale(ct0, ct0); err != nil {
+					return nil, err
+				}
+
+				if err := env.est.Rescale(el0, el0); err != nil {
+					return nil, err
+				}
+			}
+
+			return []outcome{{values0, env.est.Decrypt(el0), ct0}}, nil
+		}, nil
+	}
+}
+
+func setupMulRelin(rescale bool) func(env *env) (trial, error) {
+	return func(env *env) (trial, error) {
+
+		evk := rlwe.NewMemEvaluationKeySet(env.kgen.GenRelinearizationKeyNew(env.sk))
+
+		eval := ckks.NewEvaluator(env.params, evk)
+
+		mulCmplx := bignum.NewComplexMultiplier().Mul
+
+		return func() ([]outcome, error) {
+
+			values0, el0, _, ct0 := env.newTestVector(env.pk)
+			values1, el1, _, ct1 := env.newTestVector(env.pk)
+
+			for j := range values0 {
+				mulCmplx(values0[j], values1[j], values0[j])
+			}
+
+			if err := eval.MulRelin(ct0, ct1, ct0); err != nil {
+				return nil, err
+			}
+
+			if err := env.est.MulRelin(el0, el1, el0); err != nil {
+				return nil, err
+			}
+
+			if rescale {
+
+				if err := eval.Rescale(ct0, ct0); err != nil {
+					return nil, err
+				}
+
+				if err := env.est.Rescale(el0, el0); err != nil {
+					return nil, err
+				}
+			}
+
+			return []outcome{{values0, env.est.Decrypt(el0), ct0}}, nil
+		}, nil
+	}
+}
+
+func setupRotate(env *env) (trial, error) {
+
+	k := 1
+
+	evk := rlwe.NewMemEvaluationKeySet(nil, env.kgen.GenGaloisKeyNew(env.params.GaloisElement(k), env.sk))
+
+	eval := ckks.NewEvaluator(env.params, evk)
+
+	return func() ([]outcome, error) {
+
+		values, el, _, ct := env.newTestVector(env.pk)
+
+		utils.RotateSliceInPlace(values, k)
+
+		if err := eval.Rotate(ct, k, ct); err != nil {
+			return nil, err
+		}
+
+		el, err := env.est.RotateNew(el, k)
+		if err != nil {
+			return nil, err
+		}
+
+		return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+func setupConjugate(env *env) (trial, error) {
+
+	evk := rlwe.NewMemEvaluationKeySet(nil, env.kgen.GenGaloisKeyNew(env.params.GaloisElementForComplexConjugation(), env.sk))
+
+	eval := ckks.NewEvaluator(env.params, evk)
+
+	return func() ([]outcome, error) {
+
+		values, el, _, ct := env.newTestVector(env.pk)
+
+		for i := range values {
+			values[i][1].Neg(values[i][1])
+		}
+
+		if err := eval.Conjugate(ct, ct); err != nil {
+			return nil, err
+		}
+
+		el, err := env.est.ConjugateNew(el)
+		if err != nil {
+			return nil, err
+		}
+
+		return []outcome{{values, env.est.Decrypt(el), ct}}, nil
+	}, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+	"github.com/tuneinsight/ckks-noise-estimator/optimizer"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// optimize searches the cheapest parameters of the circuit given with -f.
+func optimize(args []string) (err error) {
+
+	fs := flag.NewFlagSet("optimize", flag.ContinueOnError)
+
+	file := fs.String("f", "", "JSON or YAML file describing the circuit (see package circuit)")
+	target := fs.Float64("target", 0, "minimum predicted precision of the outputs in bits")
+	stat := fs.String("stat", string(estimator.AVGLog2Prec), "statistic compared with the target")
+	minSecurity := fs.Float64("security", 128, "minimum bit-security")
+	modelName := fs.String("model", "bkzsieve", "security estimate: coresvp, quantum, bkzsieve or standard (table of the HomomorphicEncryption.org standard)")
+	logN := fs.String("logn", "12,13,14,15,16", "comma-separated candidate log2 of the ring degree")
+	logP := fs.String("logp", "61", "comma-separated candidate bit-sizes of the primes Pi")
+	pCount := fs.String("pcount", "1,2,3", "comma-separated candidate numbers of primes Pi")
+	hw := fs.String("hw", "192,0", "comma-separated candidate Hamming weights of the secret (0: dense)")
+	minScale := fs.Int("minscale", 20, "smallest log2 of the default scale")
+	maxScale := fs.Int("maxscale", 60, "largest log2 of the default scale")
+	margin := fs.Int("margin", 10, "bit-size of the first prime minus the log2 of the default scale")
+	trials := fs.Int("trials", 1, "number of trials per candidate")
+	seed := fs.Int64("seed", 0, "seed of the inputs and of the estimator (default: random)")
+	heuristic := fs.Bool("heuristic", true, "use the heuristic noise model of the estimator")
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if *file == "" {
+		return fmt.Errorf("missing -f")
+	}
+
+	opts := optimizer.Options{
+		Target:      *target,
+		Statistic:   estimator.Statistic(*stat),
+		MinSecurity: *minSecurity,
+		MinLogScale: *minScale,
+		MaxLogScale: *maxScale,
+		Q0Margin:    *margin,
+		Trials:      *trials,
+		Seed:        *seed,
+		Heuristic:   *heuristic,
+	}
+
+	if opts.Security, err = securityFunc(*modelName); err != nil {
+		return
+	}
+
+	if !isSet(fs, "seed") {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	for _, f := range []struct {
+		name string
+		s    string
+		v    *[]int
+	}{
+		{"logn", *logN, &opts.LogN},
+		{"logp", *logP, &opts.LogP},
+		{"pcount", *pCount, &opts.PCount},
+		{"hw", *hw, &opts.H},
+	} {
+		if *f.v, err = parseInts(f.s); err != nil {
+			return fmt.Errorf("invalid -%s: %w", f.name, err)
+		}
+	}
+
+	c, err := circuit.Load(*file)
+	if err != nil {
+		return fmt.Errorf("circuit.Load: %w", err)
+	}
+
+	res, err := optimizer.Optimize(c, opts)
+	if err != nil {
+		return fmt.Errorf("optimizer.Optimize: %w", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	// The outputs are omitted: the parameters can be used as is in a circuit
+	// and the circuit subcommand reports the statistics of its outputs.
+	res.Outputs = nil
+
+	return enc.Encode(res)
+}
+
+// securityFunc returns the security estimate given with -model.
+func securityFunc(name string) (f func(params ckks.Parameters) float64, err error) {
+
+	if name == "standard" {
+		return optimizer.Standard, nil
+	}
+
+	model, ok := costModels[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid -model: %q", name)
+	}
+
+	return model.Bits, nil
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// ParametersFile is the content of the JSON file given with -params.
+// Bootstrapping is only used by the bootstrapping experiments and its secret
+// distribution defaults to the one of Parameters.
+type ParametersFile struct {
+	Parameters    *ckks.ParametersLiteral
+	Bootstrapping *bootstrapping.ParametersLiteral
+}
+
+// config is the configuration of a run of an experiment.
+type config struct {
+	params    ckks.ParametersLiteral
+	btpParams bootstrapping.ParametersLiteral
+	trials    int
+	seed      int64
+	a, b      complex128
+	heuristic bool
+	format    string
+	rows      []estimator.Statistic
+	caption   string
+	label     string
+	output    string
+}
+
+// parseFlags returns the configuration of exp given by the flags in args.
+func parseFlags(exp experiment, args []string) (cfg config, err error) {
+
+	fs := flag.NewFlagSet(exp.name, flag.ContinueOnError)
+
+	in := exp.inputs
+	if in == (inputs{}) {
+		in = inputs{-1, 1, false}
+	}
+
+	paramsFile := fs.String("params", "", "JSON file with the `Parameters` (ckks.ParametersLiteral) and the Bootstrapping (bootstrapping.ParametersLiteral) of the experiment")
+	logN := fs.Int("logn", 0, "log2 of the ring degree")
+	logQ := fs.String("logq", "", "comma-separated bit-sizes of the primes Qi")
+	logP := fs.String("logp", "", "comma-separated bit-sizes of the primes Pi")
+	logScale := fs.Int("logscale", 0, "log2 of the default scale")
+	hw := fs.Int("hw", 0, "Hamming weight of the ternary secret (0 keeps the default)")
+	ci := fs.Bool("ci", false, "use the conjugate-invariant ring")
+	trials := fs.Int("trials", 1, "number of trials")
+	seed := fs.Int64("seed", 0, "seed of the inputs and of the estimator (default: random)")
+	lo := fs.Float64("min", in.min, "lower bound of the real and imaginary parts of the inputs")
+	hi := fs.Float64("max", in.max, "upper bound of the real and imaginary parts of the inputs")
+	real := fs.Bool("real", in.real, "sample real inputs")
+	heuristic := fs.Bool("heuristic", true, "use the heuristic noise model of the estimator")
+	out := newOutputFlags(fs)
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	cfg.params = exp.params
+	cfg.btpParams = exp.btpParams
+
+	if *paramsFile != "" {
+
+		var data []byte
+		if data, err = os.ReadFile(*paramsFile); err != nil {
+			return cfg, fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		var f ParametersFile
+		if err = json.Unmarshal(data, &f); err != nil {
+			return cfg, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+
+		if f.Parameters != nil {
+			cfg.params = *f.Parameters
+		}
+
+		if f.Bootstrapping != nil {
+			cfg.btpParams = *f.Bootstrapping
+		}
+	}
+
+	if set["logn"] {
+		cfg.params.LogN = *logN
+	}
+
+	if set["logq"] {
+		if cfg.params.LogQ, err = parseInts(*logQ); err != nil {
+			return cfg, fmt.Errorf("invalid -logq: %w", err)
+		}
+		cfg.params.Q = nil
+	}
+
+	if set["logp"] {
+		if cfg.params.LogP, err = parseInts(*logP); err != nil {
+			return cfg, fmt.Errorf("invalid -logp: %w", err)
+		}
+		cfg.params.P = nil
+	}
+
+	if set["logscale"] {
+		cfg.params.LogDefaultScale = *logScale
+	}
+
+	if *hw != 0 {
+		cfg.params.Xs = ring.Ternary{H: *hw}
+	}
+
+	if *ci {
+		cfg.params.RingType = ring.ConjugateInvariant
+	}
+
+	if *trials < 1 {
+		return cfg, fmt.Errorf("invalid -trials: %d < 1", *trials)
+	}
+
+	cfg.trials = *trials
+
+	if set["seed"] {
+		cfg.seed = *seed
+	} else {
+		cfg.seed = time.Now().UnixNano()
+	}
+
+	if *real {
+		cfg.a, cfg.b = complex(*lo, 0), complex(*hi, 0)
+	} else {
+		cfg.a, cfg.b = complex(*lo, *lo), complex(*hi, *hi)
+	}
+
+	cfg.heuristic = *heuristic
+
+	err = out.apply(&cfg)
+
+	return
+}
+
+// outputFlags are the flags of the output of a run.
+type outputFlags struct {
+	format, rows, caption, label, output *string
+}
+
+func newOutputFlags(fs *flag.FlagSet) outputFlags {
+	return outputFlags{
+		format:  fs.String("format", "text", "output format: text, json, csv, latex or markdown"),
+		rows:    fs.String("rows", "", "comma-separated statistics of the latex and markdown tables (e.g. \"MIN Prec,AVG Prec\")"),
+		caption: fs.String("caption", "", "caption of the latex and markdown tables, written as is (default: the circuit, N and the scale)"),
+		label:   fs.String("label", "", "label of the latex table"),
+		output:  fs.String("o", "", "output file (default: stdout)"),
+	}
+}
+
+// apply sets the output of the configuration.
+func (f outputFlags) apply(cfg *config) (err error) {
+
+	switch *f.format {
+	case "text", "json", "csv", "latex", "markdown":
+		cfg.format = *f.format
+	default:
+		return fmt.Errorf("invalid -format: %q", *f.format)
+	}
+
+	if *f.rows != "" {
+		for _, r := range strings.Split(*f.rows, ",") {
+			cfg.rows = append(cfg.rows, estimator.Statistic(strings.TrimSpace(r)))
+		}
+	}
+
+	cfg.caption = *f.caption
+	cfg.label = *f.label
+	cfg.output = *f.output
+
+	return
+}
+
+// run runs the experiment with the given flags and writes its result.
+func run(exp experiment, args []string) (err error) {
+
+	cfg, err := parseFlags(exp, args)
+	if err != nil {
+		return
+	}
+
+	params, err := ckks.NewParametersFromLiteral(cfg.params)
+	if err != nil {
+		return fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+	}
+
+	if exp.sweep != nil {
+		return runSweep(exp, params, cfg)
+	}
+
+	env := newEnv(params, cfg)
+
+	tr, err := exp.setup(env)
+	if err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+
+	statsWant := make([]estimator.Stats, max(1, len(exp.variants)))
+	statsHave := make([]estimator.Stats, len(statsWant))
+	for i := range statsWant {
+		statsWant[i] = estimator.NewStats()
+		statsHave[i] = estimator.NewStats()
+	}
+
+	tails := newTails(exp.tails, cfg.seed)
+
+	for i := 0; i < cfg.trials; i++ {
+
+		var outcomes []outcome
+		if outcomes, err = tr(); err != nil {
+			return fmt.Errorf("trial %d: %w", i, err)
+		}
+
+		if len(exp.variants) != 0 && len(outcomes) != len(exp.variants) {
+			return fmt.Errorf("trial %d: %d outcomes for %d variants", i, len(outcomes), len(exp.variants))
+		}
+
+		for j, o := range outcomes {
+
+			k := 0
+			if len(exp.variants) != 0 {
+				k = j
+			}
+
+			statsWant[k].Add(ckks.GetPrecisionStats(params, env.ecd, env.dec, o.want, o.predicted, 0, false))
+			statsHave[k].Add(ckks.GetPrecisionStats(params, env.ecd, env.dec, o.want, o.actual, 0, false))
+
+			if err = tails.add(env, o); err != nil {
+				return fmt.Errorf("trial %d: %w", i, err)
+			}
+		}
+	}
+
+	if len(exp.tails) != 0 {
+		return writeTailsFile(cfg, exp.name, params, tails)
+	}
+
+	results := make([]estimator.Result, len(statsWant))
+
+	for i := range results {
+
+		statsWant[i].Finalize()
+		statsHave[i].Finalize()
+
+		name := exp.name
+		if len(exp.variants) != 0 {
+			name += " " + exp.variants[i]
+		}
+
+		results[i] = estimator.NewResult(name, params, cfg.seed, statsWant[i], statsHave[i])
+		results[i].Trials = cfg.trials
+	}
+
+	return writeFile(cfg, results...)
+}
+
+// writeFile writes the results to the output of the configuration.
+func writeFile(cfg config, results ...estimator.Result) (err error) {
+
+	if cfg.output == "" {
+		return write(os.Stdout, cfg, results...)
+	}
+
+	f, err := os.Create(cfg.output)
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+
+	if err = write(f, cfg, results...); err != nil {
+		f.Close()
+		return
+	}
+
+	return f.Close()
+}
+
+// write writes the results in the format of the configuration.
+func write(w io.Writer, cfg config, results ...estimator.Result) (err error) {
+
+	switch cfg.format {
+	case "json":
+		return estimator.WriteJSON(w, results...)
+	case "csv":
+		return estimator.WriteCSV(w, results...)
+	}
+
+	for _, result := range results {
+		if err = writeTable(w, result, cfg); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// writeTable writes the result as text, latex or markdown.
+func writeTable(w io.Writer, result estimator.Result, cfg config) (err error) {
+
+	opts := estimator.TableOptions{
+		Rows:    cfg.rows,
+		Caption: cfg.caption,
+		Label:   cfg.label,
+	}
+
+	var table string
+
+	switch cfg.format {
+	case "text":
+		if _, err = fmt.Fprintf(w, "%s (seed=%d, trials=%d)\nPredicted:\n%s\n", result.Circuit, result.Seed, result.Trials, result.Predicted.String()); err == nil && result.Actual.N != 0 {
+			_, err = fmt.Fprintf(w, "Actual:\n%s\n", result.Actual.String())
+		}
+	case "latex":
+		if table, err = result.LaTeXTable(opts); err == nil {
+			_, err = io.WriteString(w, table)
+		}
+	case "markdown":
+		if table, err = result.MarkdownTable(opts); err == nil {
+			_, err = io.WriteString(w, table)
+		}
+	}
+
+	return
+}
+
+// parseInts parses a comma-separated list of integers.
+func parseInts(s string) (v []int, err error) {
+
+	for _, x := range strings.Split(s, ",") {
+
+		var i int
+		if i, err = strconv.Atoi(strings.TrimSpace(x)); err != nil {
+			return nil, err
+		}
+
+		v = append(v, i)
+	}
+
+	return
+}
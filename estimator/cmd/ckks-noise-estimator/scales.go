@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+)
+
+// scales plans the scales of the circuit given with -f, prints the circuit with
+// these scales as JSON and the planned scale of each level on the standard error.
+func scales(args []string) (err error) {
+
+	fs := flag.NewFlagSet("scales", flag.ContinueOnError)
+
+	file := fs.String("f", "", "JSON or YAML file describing the circuit (see package circuit)")
+	margin := fs.Float64("margin", 1, "minimum number of bits between the scaled values and half of the modulus")
+	maxScale := fs.Float64("maxscale", 60, "maximum log2 of the scale of an encoding")
+	seed := fs.Int64("seed", 0, "seed of the inputs and of the estimator (default: random)")
+	heuristic := fs.Bool("heuristic", true, "use the heuristic noise model of the estimator")
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if *file == "" {
+		return fmt.Errorf("missing -f")
+	}
+
+	opts := circuit.ScaleOptions{
+		Seed:        *seed,
+		Heuristic:   *heuristic,
+		Margin:      *margin,
+		MaxLogScale: *maxScale,
+	}
+
+	if !isSet(fs, "seed") {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	c, err := circuit.Load(*file)
+	if err != nil {
+		return fmt.Errorf("circuit.Load: %w", err)
+	}
+
+	plan, err := circuit.PlanScales(c, opts)
+	if err != nil {
+		return fmt.Errorf("circuit.PlanScales: %w", err)
+	}
+
+	for _, l := range plan.Levels {
+		fmt.Fprintf(os.Stderr, "level %2d: log2(qi)=%6.2f log2(scale)=%6.2f\n", l.Level, l.LogQi, l.Log2Scale)
+	}
+
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(plan.Circuit)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+	"github.com/tuneinsight/ckks-noise-estimator/security"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// costModels are the values of -model.
+var costModels = map[string]security.CostModel{
+	"coresvp":  security.CoreSVP,
+	"quantum":  security.QuantumCoreSVP,
+	"bkzsieve": security.BKZSieve,
+}
+
+// estimateSecurity prints the estimated bit-security of the parameters given with
+// -params or -f and, if any, of the secrets used by their bootstrapping.
+func estimateSecurity(args []string) (err error) {
+
+	fs := flag.NewFlagSet("security", flag.ContinueOnError)
+
+	paramsFile := fs.String("params", "", "JSON file with the `Parameters` (ckks.ParametersLiteral) and the Bootstrapping (bootstrapping.ParametersLiteral)")
+	file := fs.String("f", "", "JSON or YAML file describing a circuit whose parameters are estimated")
+	modelName := fs.String("model", "coresvp", "cost model of BKZ: coresvp, quantum or bkzsieve")
+
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	model, ok := costModels[*modelName]
+	if !ok {
+		return fmt.Errorf("invalid -model: %q", *modelName)
+	}
+
+	var f ParametersFile
+
+	switch {
+	case *paramsFile != "" && *file == "":
+
+		var data []byte
+		if data, err = os.ReadFile(*paramsFile); err != nil {
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		if err = json.Unmarshal(data, &f); err != nil {
+			return fmt.Errorf("json.Unmarshal: %w", err)
+		}
+
+		if f.Parameters == nil {
+			return fmt.Errorf("%s: missing Parameters", *paramsFile)
+		}
+
+	case *file != "" && *paramsFile == "":
+
+		var c circuit.Circuit
+		if c, err = circuit.Load(*file); err != nil {
+			return fmt.Errorf("circuit.Load: %w", err)
+		}
+
+		f.Parameters, f.Bootstrapping = &c.Parameters, c.Bootstrapping
+
+	default:
+		return fmt.Errorf("exactly one of -params and -f is required")
+	}
+
+	params, err := ckks.NewParametersFromLiteral(*f.Parameters)
+	if err != nil {
+		return fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+	}
+
+	if f.Bootstrapping == nil {
+
+		var e security.Estimate
+		if e, err = security.Parameters(params, model); err != nil {
+			return fmt.Errorf("security.Parameters: %w", err)
+		}
+
+		fmt.Print(e)
+
+		return
+	}
+
+	btpLit := *f.Bootstrapping
+
+	if btpLit.Xs == nil {
+		btpLit.Xs = params.Xs()
+	}
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpLit)
+	if err != nil {
+		return fmt.Errorf("bootstrapping.NewParametersFromLiteral: %w", err)
+	}
+
+	e, err := security.Bootstrapping(btpParams, model)
+	if err != nil {
+		return fmt.Errorf("security.Bootstrapping: %w", err)
+	}
+
+	fmt.Printf("Residual: %s", e.Residual)
+	fmt.Printf("Bootstrapping: %s", e.Bootstrapping)
+
+	if e.Ephemeral != nil {
+		fmt.Printf("Ephemeral: %s", e.Ephemeral)
+	}
+
+	fmt.Printf("Security: %.2f bits\n", e.Bits())
+
+	return
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// tails are the tails of the predicted and actual errors of the outcomes of an experiment.
+type tails struct {
+	models     []estimator.TailModel
+	want, have []estimator.TailStats
+}
+
+// newTails returns the tails of the models, whose bootstrap confidence intervals are seeded with seed.
+func newTails(models []estimator.TailModel, seed int64) *tails {
+
+	t := &tails{
+		models: models,
+		want:   make([]estimator.TailStats, len(models)),
+		have:   make([]estimator.TailStats, len(models)),
+	}
+
+	for i := range models {
+		t.want[i] = estimator.NewTailStats(models[i])
+		t.have[i] = estimator.NewTailStats(models[i])
+		t.want[i].Seed = seed
+		t.have[i].Seed = seed
+	}
+
+	return t
+}
+
+// add adds the predicted and actual errors of the outcome.
+func (t *tails) add(env *env, o outcome) (err error) {
+
+	if len(t.models) == 0 {
+		return
+	}
+
+	var pt *rlwe.Plaintext
+	switch actual := o.actual.(type) {
+	case *rlwe.Ciphertext:
+		pt = env.dec.DecryptNew(actual)
+	case *rlwe.Plaintext:
+		pt = actual
+	default:
+		return fmt.Errorf("invalid outcome: %T", o.actual)
+	}
+
+	have := make([]*bignum.Complex, len(o.want))
+	if err = env.ecd.Decode(pt, have); err != nil {
+		return fmt.Errorf("ecd.Decode: %w", err)
+	}
+
+	for i := range t.models {
+		t.want[i].Add(o.want, o.predicted)
+		t.have[i].Add(o.want, have)
+	}
+
+	return
+}
+
+// tailRow is the predicted and actual probability that the error of a slot exceeds a bound.
+type tailRow struct {
+	Model     string
+	Predicted estimator.FailureProbability
+	Actual    estimator.FailureProbability
+}
+
+// rows returns the failure probabilities, at the confidence level 0.95, of the bounds
+// from 2^-4 to 2^4 times the largest actual error, rounded down to a power of two.
+func (t *tails) rows() (rows []tailRow, err error) {
+
+	var m float64
+	for i := range t.have {
+		for _, e := range t.have[i].Errors {
+			m = math.Max(m, e)
+		}
+	}
+
+	if m == 0 {
+		return nil, fmt.Errorf("no errors")
+	}
+
+	log2Max := math.Floor(math.Log2(m))
+
+	for i := range t.models {
+		for log2Bound := log2Max - 4; log2Bound <= log2Max+4; log2Bound++ {
+
+			row := tailRow{Model: t.models[i].String()}
+
+			if row.Predicted, err = t.want[i].FailureProbability(log2Bound, 0.95); err != nil {
+				return nil, fmt.Errorf("predicted: %w", err)
+			}
+
+			if row.Actual, err = t.have[i].FailureProbability(log2Bound, 0.95); err != nil {
+				return nil, fmt.Errorf("actual: %w", err)
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	return
+}
+
+// writeTailsFile writes the failure probabilities of the tails to the output of the
+// configuration. The latex and markdown tables ignore the rows of the configuration.
+func writeTailsFile(cfg config, circuit string, params ckks.Parameters, t *tails) (err error) {
+
+	rows, err := t.rows()
+	if err != nil {
+		return fmt.Errorf("tails: %w", err)
+	}
+
+	w := io.Writer(os.Stdout)
+
+	if cfg.output != "" {
+
+		var f *os.File
+		if f, err = os.Create(cfg.output); err != nil {
+			return fmt.Errorf("os.Create: %w", err)
+		}
+
+		defer func() {
+			if errClose := f.Close(); err == nil {
+				err = errClose
+			}
+		}()
+
+		w = f
+	}
+
+	switch cfg.format {
+	case "json":
+		return writeTailsJSON(w, cfg, circuit, params, rows)
+	case "csv":
+		return writeTailsCSV(w, cfg, circuit, params, rows)
+	case "latex", "markdown":
+		return writeTailsTable(w, cfg, circuit, params, rows)
+	}
+
+	if _, err = fmt.Fprintf(w, "%s (seed=%d, trials=%d)\n", circuit, cfg.seed, cfg.trials); err != nil {
+		return
+	}
+
+	for _, r := range rows {
+		p, a := r.Predicted, r.Actual
+		if _, err = fmt.Fprintf(w, "%-8s log2(bound)=%5.1f | Predicted: log2(P)=%8.2f [%8.2f, %8.2f] observed=%6d | Actual: log2(P)=%8.2f [%8.2f, %8.2f] observed=%6d / %d\n",
+			r.Model, p.Log2Bound,
+			p.Log2P, p.Log2PLow, p.Log2PHigh, p.Observed,
+			a.Log2P, a.Log2PLow, a.Log2PHigh, a.Observed, a.Samples); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func writeTailsJSON(w io.Writer, cfg config, circuit string, params ckks.Parameters, rows []tailRow) (err error) {
+
+	// JSON has no infinities: the log2 of a zero probability is null
+	type jsonFailureProbability struct {
+		Log2Bound                  float64
+		Log2P, Log2PLow, Log2PHigh *float64
+		Observed, Samples          int
+		Confidence                 float64
+	}
+
+	finite := func(x float64) *float64 {
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return nil
+		}
+		return &x
+	}
+
+	toJSON := func(f estimator.FailureProbability) jsonFailureProbability {
+		return jsonFailureProbability{f.Log2Bound, finite(f.Log2P), finite(f.Log2PLow), finite(f.Log2PHigh), f.Observed, f.Samples, f.Confidence}
+	}
+
+	type jsonTailRow struct {
+		Model             string
+		Predicted, Actual jsonFailureProbability
+	}
+
+	out := make([]jsonTailRow, len(rows))
+	for i, r := range rows {
+		out[i] = jsonTailRow{r.Model, toJSON(r.Predicted), toJSON(r.Actual)}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err = enc.Encode(struct {
+		Circuit    string
+		Parameters estimator.ParametersSummary
+		Seed       int64
+		Trials     int
+		Tails      []jsonTailRow
+	}{circuit, estimator.NewParametersSummary(params), cfg.seed, cfg.trials, out}); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+
+	return
+}
+
+func writeTailsCSV(w io.Writer, cfg config, circuit string, params ckks.Parameters, rows []tailRow) (err error) {
+
+	cw := csv.NewWriter(w)
+
+	if err = cw.Write([]string{
+		"Circuit", "LogN", "LogDefaultScale", "RingType", "Seed", "Trials", "Model", "Log2Bound", "Confidence",
+		"PredictedLog2P", "PredictedLog2PLow", "PredictedLog2PHigh", "PredictedObserved",
+		"ActualLog2P", "ActualLog2PLow", "ActualLog2PHigh", "ActualObserved", "Samples",
+	}); err != nil {
+		return fmt.Errorf("cw.Write: %w", err)
+	}
+
+	f := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+
+	for _, r := range rows {
+		p, a := r.Predicted, r.Actual
+		if err = cw.Write([]string{
+			circuit, strconv.Itoa(params.LogN()), strconv.Itoa(params.LogDefaultScale()), params.RingType().String(),
+			strconv.FormatInt(cfg.seed, 10), strconv.Itoa(cfg.trials), r.Model, f(p.Log2Bound), f(p.Confidence),
+			f(p.Log2P), f(p.Log2PLow), f(p.Log2PHigh), strconv.Itoa(p.Observed),
+			f(a.Log2P), f(a.Log2PLow), f(a.Log2PHigh), strconv.Itoa(a.Observed), strconv.Itoa(a.Samples),
+		}); err != nil {
+			return fmt.Errorf("cw.Write: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err = cw.Error(); err != nil {
+		return fmt.Errorf("cw.Flush: %w", err)
+	}
+
+	return
+}
+
+func writeTailsTable(w io.Writer, cfg config, circuit string, params ckks.Parameters, rows []tailRow) (err error) {
+
+	caption := func(name string) string {
+		if cfg.caption != "" {
+			return cfg.caption
+		}
+		return fmt.Sprintf("%s with $N=2^{%d}$ and $\\Delta = 2^{%d}$", name, params.LogN(), params.LogDefaultScale())
+	}
+
+	var sb strings.Builder
+
+	if cfg.format == "markdown" {
+
+		fmt.Fprintf(&sb, "**%s**\n\n", caption(circuit))
+		sb.WriteString("| model | log2 bound | Predicted log2 P | Actual log2 P | Actual observed |\n")
+		sb.WriteString("|---|---:|---:|---:|---:|\n")
+
+		for _, r := range rows {
+			fmt.Fprintf(&sb, "| %s | %.1f | %.2f [%.2f, %.2f] | %.2f [%.2f, %.2f] | %d / %d |\n",
+				r.Model, r.Predicted.Log2Bound,
+				r.Predicted.Log2P, r.Predicted.Log2PLow, r.Predicted.Log2PHigh,
+				r.Actual.Log2P, r.Actual.Log2PLow, r.Actual.Log2PHigh, r.Actual.Observed, r.Actual.Samples)
+		}
+
+	} else {
+
+		label := cfg.label
+		if label == "" {
+			label = estimator.LaTeXLabel(circuit)
+		}
+
+		sb.WriteString(`\begin{table}[]
+    \centering
+    \begin{tabular}{|c|c||c|c|c||c|c|c|}
+    \hline
+        & & \multicolumn{3}{c||}{Predicted} & \multicolumn{3}{c|}{Actual}  \\
+        \hline
+        model & $\log_{2}$ bound & $\log_{2} P$ & low & high & $\log_{2} P$ & low & high\\
+        \hline
+`)
+
+		for _, r := range rows {
+			fmt.Fprintf(&sb, "        %s & %5.1f & %5.2f & %5.2f & %5.2f & %5.2f & %5.2f & %5.2f \\\\\n",
+				estimator.LaTeXEscape(r.Model), r.Predicted.Log2Bound,
+				r.Predicted.Log2P, r.Predicted.Log2PLow, r.Predicted.Log2PHigh,
+				r.Actual.Log2P, r.Actual.Log2PLow, r.Actual.Log2PHigh)
+		}
+
+		fmt.Fprintf(&sb, `        \hline
+    \end{tabular}
+    \caption{%s}
+    \label{%s}
+\end{table}
+`, caption(estimator.LaTeXEscape(circuit)), label)
+	}
+
+	_, err = io.WriteString(w, sb.String())
+
+	return
+}
@@ -0,0 +1,61 @@
+package estimator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+)
+
+// Progress is the progress of a stage of a long-running operation of the estimator,
+// e.g. the diagonals of a linear transformation or the steps of a bootstrapping.
+type Progress struct {
+	// Stage is the name of the stage, e.g. "linear_transformation".
+	Stage string
+	// Done is the number of completed steps of the stage, out of Total.
+	Done, Total int
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("%s: %d/%d", p.Stage, p.Done, p.Total)
+}
+
+// WithContext returns a copy of the estimator whose long-running operations (polynomial
+// evaluations, linear transformations, DFTs and, through the estimators of package
+// bootstrapping, bootstrappings) return an error wrapping the cause of the cancellation
+// of ctx at their next checkpoint (see Checkpoint).
+func (e Estimator) WithContext(ctx context.Context) Estimator {
+	e.ctx = ctx
+	return e
+}
+
+// Checkpoint reports the progress of a stage to e.Progress, if not nil, and
+// returns an error wrapping the cause of the cancellation of the context of
+// the estimator (see WithContext), if it is cancelled.
+func (e Estimator) Checkpoint(stage string, done, total int) (err error) {
+
+	if e.Progress != nil {
+		e.Progress(Progress{Stage: stage, Done: done, Total: total})
+	}
+
+	if e.ctx != nil && e.ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", stage, context.Cause(e.ctx))
+	}
+
+	return
+}
+
+// EvaluatePolynomialNewContext is as EvaluatePolynomialNew, but stops if ctx is cancelled.
+func (e Estimator) EvaluatePolynomialNewContext(ctx context.Context, elIn *Element, poly interface{}, targetScale rlwe.Scale) (elOut *Element, err error) {
+	return e.WithContext(ctx).EvaluatePolynomialNew(elIn, poly, targetScale)
+}
+
+// EvaluateLinearTransformationContext is as EvaluateLinearTransformation, but stops if ctx is cancelled.
+func (e Estimator) EvaluateLinearTransformationContext(ctx context.Context, elIn *Element, lt LinearTransformation, elOut *Element) (err error) {
+	return e.WithContext(ctx).EvaluateLinearTransformation(elIn, lt, elOut)
+}
+
+// DFTContext is as DFT, but stops if ctx is cancelled.
+func (e Estimator) DFTContext(ctx context.Context, elIn *Element, mat DFTMatrix, elOut *Element) (err error) {
+	return e.WithContext(ctx).DFT(elIn, mat, elOut)
+}
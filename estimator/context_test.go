@@ -0,0 +1,100 @@
+package estimator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/lintrans"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func TestContext(t *testing.T) {
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            10,
+		LogQ:            []int{55, 45, 45, 45, 45},
+		LogP:            []int{61},
+		LogDefaultScale: 45,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecd := ckks.NewEncoder(params)
+
+	_, el, _, _ := NewEstimatorFromSeed(params, 1).NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, NewTestRand(1))
+
+	// Diagonals of the linear transformation
+	diags := lintrans.Diagonals[*bignum.Complex]{}
+	for _, k := range []int{0, 1, 2, 3, 4, 5, 6, 7} {
+		diags[k] = make([]*bignum.Complex, params.MaxSlots())
+		for i := range diags[k] {
+			diags[k][i] = bignum.ToComplex(0.125, prec)
+		}
+	}
+
+	lt := LinearTransformation{
+		LogSlots: params.LogMaxSlots(),
+		Scale:    rlwe.NewScale(params.Q()[el.Level]),
+		Value:    diags,
+	}
+
+	poly := bignum.NewPolynomial(bignum.Chebyshev, []float64{1, 2, 3, 4, 5, 6, 7, 8}, [2]float64{-1, 1})
+
+	for _, tc := range []struct {
+		name  string
+		stage string
+		f     func(est Estimator, ctx context.Context) error
+	}{
+		{"LinearTransformation", "linear_transformation", func(est Estimator, ctx context.Context) error {
+			return est.EvaluateLinearTransformationContext(ctx, el, lt, est.NewElement(nil, 1, el.Level, el.Scale))
+		}},
+		{"Polynomial", "power_basis", func(est Estimator, ctx context.Context) (err error) {
+			_, err = est.EvaluatePolynomialNewContext(ctx, el, poly, el.Scale)
+			return
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+
+			est := NewEstimatorFromSeed(params, 1)
+
+			// Without cancellation
+			if err := tc.f(est, context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			// Cancelled before the operation
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := tc.f(est, ctx); !errors.Is(err, ctx.Err()) {
+				t.Fatalf("cancelled context: error %v does not wrap %v", err, ctx.Err())
+			}
+
+			// Cancelled at the first step of the stage, with a cause
+			cause := errors.New("timeout")
+			ctx, cancelCause := context.WithCancelCause(context.Background())
+			defer cancelCause(nil)
+
+			var progress []Progress
+			est.Progress = func(p Progress) {
+				progress = append(progress, p)
+				if p.Stage == tc.stage && p.Done == 1 {
+					cancelCause(cause)
+				}
+			}
+
+			if err := tc.f(est, ctx); !errors.Is(err, cause) {
+				t.Fatalf("cancelled context: error %v does not wrap %v", err, cause)
+			}
+
+			// The operation stops at the checkpoint following the cancellation.
+			if last := progress[len(progress)-1]; last.Stage != tc.stage || last.Done != 1 {
+				t.Fatalf("last progress: %v", last)
+			}
+		})
+	}
+}
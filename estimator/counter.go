@@ -0,0 +1,242 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Op is a kind of operation counted by an OperationCounter.
+type Op string
+
+const (
+	// OpAdd is an addition or a subtraction.
+	OpAdd = Op("add")
+	// OpMulConst is a multiplication by a constant.
+	OpMulConst = Op("mul_const")
+	// OpMulPlaintext is a multiplication by a plaintext, e.g. a vector or a diagonal of a linear transformation.
+	OpMulPlaintext = Op("mul_plaintext")
+	// OpMulCiphertext is a tensoring of two ciphertexts.
+	OpMulCiphertext = Op("mul_ciphertext")
+	// OpRelinearize is a relinearization.
+	OpRelinearize = Op("relinearize")
+	// OpRescale is a rescaling, which consumes a level.
+	OpRescale = Op("rescale")
+	// OpAutomorphism is a rotation or a conjugation, with its key-switching.
+	OpAutomorphism = Op("automorphism")
+	// OpHoistedKeySwitch is a hoisted rotation, whose decomposition is shared and which has no ModDown.
+	OpHoistedKeySwitch = Op("hoisted_key_switch")
+	// OpKeySwitch is a key-switching to another secret.
+	OpKeySwitch = Op("key_switch")
+	// OpModDown is a division by the auxiliary modulus P.
+	OpModDown = Op("mod_down")
+	// OpBootstrap is a bootstrapping, whose operations are not counted.
+	OpBootstrap = Op("bootstrap")
+)
+
+// OperationCounts are the numbers of operations of a circuit.
+type OperationCounts struct {
+	// Ops[op][level] is the number of operations op whose input is at the level.
+	Ops map[Op]map[int]int
+	// Galois[g] is the number of automorphisms and hoisted key-switches by the Galois element g.
+	Galois map[uint64]int
+}
+
+// Total returns the number of operations op at all levels.
+func (c OperationCounts) Total(op Op) (n int) {
+	for _, m := range c.Ops[op] {
+		n += m
+	}
+	return
+}
+
+// MinLevel returns the lowest level of an input of an operation, or -1 if there is none.
+// If the circuit has no bootstrapping, it consumes MaxLevel - MinLevel levels, plus one
+// if its outputs are rescaled.
+func (c OperationCounts) MinLevel() (level int) {
+
+	level = math.MaxInt
+
+	for _, m := range c.Ops {
+		for l := range m {
+			level = min(level, l)
+		}
+	}
+
+	if level == math.MaxInt {
+		return -1
+	}
+
+	return
+}
+
+// GaloisElements returns the Galois elements of the automorphisms and hoisted key-switches, in increasing order.
+func (c OperationCounts) GaloisElements() (galEls []uint64) {
+	for g := range c.Galois {
+		galEls = append(galEls, g)
+	}
+	sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
+	return
+}
+
+// Runtime returns the estimated runtime of the operations, the sum of their costs in the table,
+// in the unit of the table. The operations without cost in the table are returned in missing.
+func (c OperationCounts) Runtime(table CostTable) (runtime float64, missing []Op) {
+
+	for op, m := range c.Ops {
+
+		cost, ok := table[op]
+		if !ok {
+			missing = append(missing, op)
+			continue
+		}
+
+		for level, n := range m {
+			runtime += float64(n) * cost.At(level)
+		}
+	}
+
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+
+	return
+}
+
+func (c OperationCounts) String() string {
+
+	ops := make([]Op, 0, len(c.Ops))
+	for op := range c.Ops {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+
+	var sb strings.Builder
+
+	for _, op := range ops {
+
+		levels := make([]int, 0, len(c.Ops[op]))
+		for level := range c.Ops[op] {
+			levels = append(levels, level)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(levels)))
+
+		fmt.Fprintf(&sb, "%-18s %6d:", op, c.Total(op))
+		for _, level := range levels {
+			fmt.Fprintf(&sb, " %d@%d", c.Ops[op][level], level)
+		}
+		sb.WriteByte('\n')
+	}
+
+	if len(c.Galois) != 0 {
+		fmt.Fprintf(&sb, "%-18s %6d:", "galois_elements", len(c.Galois))
+		for _, g := range c.GaloisElements() {
+			fmt.Fprintf(&sb, " %d", g)
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// Cost is the cost of an operation at a level, Base + PerLevel * (level+1),
+// as the cost of most operations is linear in the number of RNS limbs.
+type Cost struct {
+	Base, PerLevel float64
+}
+
+// At returns the cost at the given level.
+func (c Cost) At(level int) float64 {
+	return c.Base + c.PerLevel*float64(level+1)
+}
+
+// CostTable is the cost of each kind of operation, e.g. in seconds, measured for the parameters of the circuit.
+type CostTable map[Op]Cost
+
+// OperationCounter counts the operations of the estimators that share it.
+// The operations that are only an implementation detail of the estimator,
+// e.g. the multiplication by P of the input of a linear transformation,
+// are not counted.
+type OperationCounter struct {
+	mu     sync.Mutex
+	counts OperationCounts
+}
+
+// NewOperationCounter returns a new OperationCounter.
+func NewOperationCounter() *OperationCounter {
+	return &OperationCounter{}
+}
+
+// Count counts an operation op whose input is at the given level,
+// and its Galois elements, if any.
+func (c *OperationCounter) Count(op Op, level int, galEls ...uint64) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts.Ops == nil {
+		c.counts.Ops = map[Op]map[int]int{}
+	}
+
+	if c.counts.Ops[op] == nil {
+		c.counts.Ops[op] = map[int]int{}
+	}
+
+	c.counts.Ops[op][level]++
+
+	for _, g := range galEls {
+
+		if c.counts.Galois == nil {
+			c.counts.Galois = map[uint64]int{}
+		}
+
+		c.counts.Galois[g]++
+	}
+}
+
+// Counts returns a copy of the counts so far.
+func (c *OperationCounter) Counts() (counts OperationCounts) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts.Ops = map[Op]map[int]int{}
+	for op, m := range c.counts.Ops {
+		counts.Ops[op] = map[int]int{}
+		for level, n := range m {
+			counts.Ops[op][level] = n
+		}
+	}
+
+	counts.Galois = map[uint64]int{}
+	for g, n := range c.counts.Galois {
+		counts.Galois[g] = n
+	}
+
+	return
+}
+
+// count counts an operation if e.Counter is not nil.
+func (e Estimator) count(op Op, level int, galEls ...uint64) {
+	if e.Counter != nil {
+		e.Counter.Count(op, level, galEls...)
+	}
+}
+
+// countMul counts a multiplication of an element at the given level by op1.
+func (e Estimator) countMul(level int, op1 interface{}) {
+	switch op1 := op1.(type) {
+	case *Element:
+		if op1.Degree == 0 {
+			e.count(OpMulPlaintext, level)
+		} else {
+			e.count(OpMulCiphertext, level)
+		}
+	case []*bignum.Complex:
+		e.count(OpMulPlaintext, level)
+	default:
+		e.count(OpMulConst, level)
+	}
+}
@@ -0,0 +1,105 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestOperationCounter(t *testing.T) {
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            10,
+		LogQ:            []int{55, 45, 45},
+		LogP:            []int{61},
+		LogDefaultScale: 45,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	est := NewEstimatorFromSeed(params, 1)
+	est.Counter = NewOperationCounter()
+
+	ecd := ckks.NewEncoder(params)
+	source := NewTestRand(1)
+
+	_, x, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, source)
+	_, y, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, source)
+
+	// z = rescale((w + rotate(w, 3)) * 0.5) with w = rescale(x * y)
+	z, err := est.MulRelinNew(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = est.Rescale(z, z); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := est.RotateNew(z, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = est.Add(z, r, z); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = est.Mul(z, 0.5, z); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = est.Rescale(z, z); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := est.Counter.Counts()
+
+	want := map[Op]map[int]int{
+		OpMulCiphertext: {2: 1},
+		OpRelinearize:   {2: 1},
+		OpRescale:       {2: 1, 1: 1},
+		OpAutomorphism:  {1: 1},
+		OpAdd:           {1: 1},
+		OpMulConst:      {1: 1},
+	}
+
+	if fmt.Sprint(counts.Ops) != fmt.Sprint(want) {
+		t.Fatalf("Ops: %v != %v", counts.Ops, want)
+	}
+
+	if have := counts.Total(OpRescale); have != 2 {
+		t.Fatalf("Total(OpRescale): %d != 2", have)
+	}
+
+	if have := counts.MinLevel(); have != 1 {
+		t.Fatalf("MinLevel: %d != 1", have)
+	}
+
+	if have, want := fmt.Sprint(counts.GaloisElements()), fmt.Sprint([]uint64{params.GaloisElement(3)}); have != want {
+		t.Fatalf("GaloisElements: %s != %s", have, want)
+	}
+
+	// Costs linear in the number of primes, without the addition
+	table := CostTable{
+		OpMulCiphertext: {Base: 1, PerLevel: 2},
+		OpRelinearize:   {PerLevel: 10},
+		OpRescale:       {Base: 0.5},
+		OpAutomorphism:  {Base: 2, PerLevel: 8},
+		OpMulConst:      {PerLevel: 1},
+	}
+
+	runtime, missing := counts.Runtime(table)
+
+	// 1 + 2*3 + 10*3 + 2*0.5 + 2 + 8*2 + 1*2
+	if want := 58.0; math.Abs(runtime-want) > 1e-9 {
+		t.Fatalf("Runtime: %f != %f", runtime, want)
+	}
+
+	if fmt.Sprint(missing) != fmt.Sprint([]Op{OpAdd}) {
+		t.Fatalf("missing: %v != [%s]", missing, OpAdd)
+	}
+}
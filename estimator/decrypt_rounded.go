@@ -0,0 +1,188 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// RoundedDecryption is the result of a decryption whose
+// ring element is rounded before being decoded.
+type RoundedDecryption struct {
+	// Values are the decoded rounded values.
+	Values []*bignum.Complex
+	// LogRound is the log2 of the rounding step of the coefficients of the scaled ring element.
+	LogRound int
+	// Log2Precision is the average log2 precision of Values with respect to the expected values.
+	Log2Precision float64
+	// ChangedCoeffs is the share of ring coefficients whose rounding
+	// differs from the rounding of the noiseless message.
+	ChangedCoeffs float64
+	// ChangedSlots is the share of slots whose decoded rounded value
+	// differs from the decoded rounded noiseless message.
+	ChangedSlots float64
+}
+
+// CanonicalToRing maps the canonical embedding of a polynomial to its N coefficients.
+// It is the inverse of RingToCanonical.
+func (e Estimator) CanonicalToRing(values []*bignum.Complex) (coeffs []*big.Float) {
+
+	slots := e.MaxSlots()
+
+	tmp := make([]*bignum.Complex, slots)
+	for i := range tmp {
+		tmp[i] = bignum.NewComplex().SetPrec(prec).Set(values[i])
+	}
+
+	// C^N/2 -> R[X]/(X^N+1)
+	if err := e.Encoder.IFFT(tmp, e.LogMaxSlots()); err != nil {
+		panic(err)
+	}
+
+	coeffs = make([]*big.Float, e.N())
+
+	if e.IsConjugateInvariant() {
+		// Z[X]/(X^2N+1) -> Z[X+X^-1]/(X^2N+1)
+		for i := range coeffs {
+			coeffs[i] = tmp[i][0]
+		}
+	} else {
+		for i := range tmp {
+			coeffs[i] = tmp[i][0]
+			coeffs[i+slots] = tmp[i][1]
+		}
+	}
+
+	return
+}
+
+// DecryptRounded decrypts el and rounds the coefficients of the decrypted (scaled) ring element to the
+// closest multiple of 2^logRound before decoding. The result is compared to the expected values want,
+// whose scaled ring element is rounded the same way.
+func (e Estimator) DecryptRounded(el *Element, want []*bignum.Complex, logRound int) (d RoundedDecryption, err error) {
+
+	if len(want) != e.MaxSlots() {
+		return d, fmt.Errorf("invalid want: len(want)=%d != MaxSlots=%d", len(want), e.MaxSlots())
+	}
+
+	scale := &el.Scale.Value
+
+	haveCoeffs := e.CanonicalToRing(e.phase(el))
+	wantCoeffs := e.CanonicalToRing(e.scaleValues(want, scale))
+
+	step := new(big.Float).SetPrec(prec).SetMantExp(NewFloat(1), logRound)
+
+	var changed int
+	for i := range haveCoeffs {
+		roundToMultiple(haveCoeffs[i], step)
+		roundToMultiple(wantCoeffs[i], step)
+		if haveCoeffs[i].Cmp(wantCoeffs[i]) != 0 {
+			changed++
+		}
+	}
+
+	have := e.RingToCanonical(haveCoeffs)
+	wantRounded := e.RingToCanonical(wantCoeffs)
+
+	// The difference of the rounded ring elements is a multiple of 2^logRound,
+	// thus smaller differences are numerical errors of the FFT.
+	threshold, _ := new(big.Float).SetMantExp(step, -32).Float64()
+
+	var changedSlots int
+	var log2Prec float64
+
+	diff := bignum.NewComplex().SetPrec(prec)
+
+	for i := range have {
+
+		diff.Sub(have[i], wantRounded[i])
+		if absComplex(diff) > threshold {
+			changedSlots++
+		}
+
+		have[i][0].Quo(have[i][0], scale)
+		have[i][1].Quo(have[i][1], scale)
+
+		diff.Sub(have[i], want[i])
+		log2Prec += min(-math.Log2(absComplex(diff)), float64(prec))
+	}
+
+	d.Values = have
+	d.LogRound = logRound
+	d.Log2Precision = log2Prec / float64(len(have))
+	d.ChangedCoeffs = float64(changed) / float64(len(haveCoeffs))
+	d.ChangedSlots = float64(changedSlots) / float64(len(have))
+
+	return
+}
+
+// DecryptRoundedBelowNoise calls DecryptRounded with a rounding step of 2^margin times the
+// standard deviation of the error Decrypt(el) - want per coefficient of the scaled ring element.
+// A negative margin rounds below the noise and a positive margin above it. It returns an error
+// if the standard deviation is zero or not finite, e.g. if el is noiseless.
+func (e Estimator) DecryptRoundedBelowNoise(el *Element, want []*bignum.Complex, margin int) (d RoundedDecryption, err error) {
+
+	if len(want) != e.MaxSlots() {
+		return d, fmt.Errorf("invalid want: len(want)=%d != MaxSlots=%d", len(want), e.MaxSlots())
+	}
+
+	coeffs := e.errorCoefficients(el, want)
+
+	variance := new(big.Float)
+	tmp := new(big.Float)
+	for i := range coeffs {
+		tmp.Mul(coeffs[i], coeffs[i])
+		variance.Add(variance, tmp)
+	}
+
+	variance.Quo(variance, NewFloat(len(coeffs)))
+
+	std, _ := variance.Sqrt(variance).Float64()
+
+	if std == 0 || math.IsInf(std, 0) || math.IsNaN(std) {
+		return d, fmt.Errorf("invalid el: the standard deviation of the error Decrypt(el) - want is %f", std)
+	}
+
+	return e.DecryptRounded(el, want, int(math.Round(math.Log2(std)))+margin)
+}
+
+// errorCoefficients returns the coefficients of the error of the scaled ring element of el,
+// i.e. of the ring element of Decrypt(el) - want multiplied by the scale of el.
+func (e Estimator) errorCoefficients(el *Element, want []*bignum.Complex) (coeffs []*big.Float) {
+
+	have := e.phase(el)
+	scaledWant := e.scaleValues(want, &el.Scale.Value)
+
+	for i := range have {
+		have[i].Sub(have[i], scaledWant[i])
+	}
+
+	return e.CanonicalToRing(have)
+}
+
+// scaleValues returns a new vector equal to values multiplied by scale.
+func (e Estimator) scaleValues(values []*bignum.Complex, scale *big.Float) (scaled []*bignum.Complex) {
+	scaled = make([]*bignum.Complex, len(values))
+	for i := range values {
+		scaled[i] = bignum.NewComplex().SetPrec(prec).Set(values[i])
+		scaled[i][0].Mul(scaled[i][0], scale)
+		scaled[i][1].Mul(scaled[i][1], scale)
+	}
+	return
+}
+
+// roundToMultiple rounds x to the closest multiple of step.
+func roundToMultiple(x, step *big.Float) {
+	x.Quo(x, step)
+	Round(x)
+	x.Mul(x, step)
+}
+
+// absComplex returns |x| as a float64.
+func absComplex(x *bignum.Complex) float64 {
+	re, _ := x[0].Float64()
+	im, _ := x[1].Float64()
+	return math.Hypot(re, im)
+}
@@ -0,0 +1,36 @@
+package estimator
+
+import (
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestDecryptRoundedBelowNoise(t *testing.T) {
+
+	params := testParameters(t)
+
+	est := NewEstimatorFromSeed(params, 1)
+	ecd := ckks.NewEncoder(params)
+
+	values, el, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, NewTestRand(1))
+
+	t.Run("Noisy", func(t *testing.T) {
+
+		// The encoding error is a rounding to the closest integer
+		d, err := est.DecryptRoundedBelowNoise(el, values, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d.LogRound < 1 || d.ChangedCoeffs == 0 {
+			t.Fatalf("LogRound=%d, ChangedCoeffs=%f", d.LogRound, d.ChangedCoeffs)
+		}
+	})
+
+	t.Run("Noiseless", func(t *testing.T) {
+		if _, err := est.DecryptRoundedBelowNoise(est.NewElement(values, 1, el.Level, el.Scale), values, 0); err == nil {
+			t.Fatal("noiseless element: no error")
+		}
+	})
+}
@@ -24,6 +24,10 @@ func (e Estimator) DFTNew(elIn *Element, mat DFTMatrix) (elOut *Element, err err
 
 func (e Estimator) DFT(elIn *Element, mat DFTMatrix, elOut *Element) (err error) {
 
+	if err = e.Checkpoint("dft", 0, len(mat.Value)); err != nil {
+		return
+	}
+
 	for i := range mat.Value {
 
 		if i == 0 {
@@ -56,6 +60,10 @@ func (e Estimator) DFT(elIn *Element, mat DFTMatrix, elOut *Element) (err error)
 		if err = e.Rescale(elOut, elOut); err != nil {
 			return fmt.Errorf("e.Rescale: %w", err)
 		}
+
+		if err = e.Checkpoint("dft", i+1, len(mat.Value)); err != nil {
+			return
+		}
 	}
 
 	return
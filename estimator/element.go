@@ -13,6 +13,9 @@ type Element struct {
 	Level  int
 	Scale  rlwe.Scale
 	Value  [3][]*bignum.Complex //(m + e0, e1, e2)
+
+	// Range is the range of the slots, or nil if unknown (see RangeAnalysis).
+	Range *Range
 }
 
 func (p Element) CopyNew() *Element {
@@ -37,6 +40,7 @@ func (p Element) CopyNew() *Element {
 		Level:  p.Level,
 		Scale:  p.Scale,
 		Value:  Value,
+		Range:  p.Range,
 	}
 }
 
@@ -102,6 +106,13 @@ func (e Estimator) NewElement(v interface{}, Degree, Level int, scale rlwe.Scale
 		panic(fmt.Errorf("invalid v.(type): must be []*bignum.Complex, []complex128, []*big.Float or []float64"))
 	}
 
+	// The imaginary part is discarded for ring.ConjugateInvariant
+	if e.IsConjugateInvariant() {
+		for i := 0; i < slots; i++ {
+			e0[i][1].SetFloat64(0)
+		}
+	}
+
 	for i := 0; i < slots; i++ {
 		e0[i][0].Mul(e0[i][0], &scale.Value)
 		e0[i][1].Mul(e0[i][1], &scale.Value)
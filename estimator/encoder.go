@@ -60,6 +60,27 @@ func NewEncoder(LogN int, prec uint) (ecd *Encoder) {
 	return
 }
 
+// ShallowCopy returns a copy of the encoder sharing its read-only
+// roots but with its own buffers, so that the copy and the original
+// can be used concurrently.
+func (ecd Encoder) ShallowCopy() *Encoder {
+
+	ecd.bigintCoeffs = make([]big.Int, ecd.m>>1)
+
+	switch buff := ecd.buffCmplx.(type) {
+	case []complex128:
+		ecd.buffCmplx = make([]complex128, len(buff))
+	case []*bignum.Complex:
+		tmp := make([]*bignum.Complex, len(buff))
+		for i := range tmp {
+			tmp[i] = &bignum.Complex{bignum.NewFloat(0, ecd.prec), bignum.NewFloat(0, ecd.prec)}
+		}
+		ecd.buffCmplx = tmp
+	}
+
+	return &ecd
+}
+
 func (ecd Encoder) IFFT(values interface{}, logN int) (err error) {
 	switch values := values.(type) {
 	case []complex128:
@@ -1,11 +1,13 @@
 package estimator
 
 import (
+	"context"
 	"math/big"
 	"math/rand"
 	"time"
 
 	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
 	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
 	"github.com/tuneinsight/lattigo/v6/utils/bignum"
 )
@@ -22,9 +24,56 @@ type Estimator struct {
 	LevelP    int
 	Sk        [][]*bignum.Complex
 	Heuristic bool
+
+	// Rand is the source of the sampled noise.
+	// If nil, a new source seeded with the time is used at each sampling.
+	Rand *rand.Rand
+
+	// SecretSeed is the seed from which the secret was sampled (see NewEstimatorFromSeed),
+	// or nil if it was sampled from a source seeded with the time.
+	SecretSeed *int64
+
+	// Ranges, if not nil, propagates and checks the ranges of the elements (see RangeAnalysis).
+	Ranges *RangeAnalysis
+
+	// Overflow, if not nil, checks the elements for wraparounds of the modulus (see OverflowCheck).
+	Overflow *OverflowCheck
+
+	// Strict, if true, checks the degrees, levels, scales and lengths of the operands and
+	// outputs of the operations, and returns errors wrapping ErrInvariant on inconsistencies
+	// that would otherwise panic or be silently accepted.
+	Strict bool
+
+	// Counter, if not nil, counts the operations (see OperationCounter).
+	Counter *OperationCounter
+
+	// Keys, if not nil, records the evaluation keys used by the operations (see KeyRequirements).
+	Keys *KeyRequirements
+
+	// Progress, if not nil, is called with the progress of the stages
+	// of the long-running operations (see Checkpoint).
+	Progress func(p Progress)
+
+	// ctx is the context of the long-running operations (see WithContext).
+	ctx context.Context
 }
 
 func NewEstimator(p ckks.Parameters) (e Estimator) {
+	return newEstimator(p, nil)
+}
+
+// NewEstimatorFromSeed is as NewEstimator, but samples the secret from a source seeded with a
+// seed derived from seed, so that the estimators with the same parameters and seed have the same
+// secret, which is independent of the values sampled from a TestRand seeded with seed.
+// The Rand of the estimator is nil.
+func NewEstimatorFromSeed(p ckks.Parameters, seed int64) (e Estimator) {
+	e = newEstimator(p, rand.New(rand.NewSource(TrialSeed(seed, -1))))
+	e.SecretSeed = &seed
+	return
+}
+
+// newEstimator returns a new estimator whose secret is sampled from r, or from the time if r is nil.
+func newEstimator(p ckks.Parameters, r *rand.Rand) (e Estimator) {
 
 	e = Estimator{}
 	e.Parameters = p
@@ -49,12 +98,16 @@ func NewEstimator(p ckks.Parameters) (e Estimator) {
 	e.P = Pi
 	e.LevelP = len(P) - 1
 
-	e.Encoder = *NewEncoder(p.LogN(), prec)
+	// The canonical embedding of the conjugate invariant
+	// ring of degree N uses the 4N-th roots of unity.
+	e.Encoder = *NewEncoder(e.LogMaxSlots()+1, prec)
 
 	e.H = min(p.N(), p.XsHammingWeight())
 
 	// Samples a secret-key
+	e.Rand = r
 	sk := e.SampleSecretKey(e.H)
+	e.Rand = nil
 
 	mul := bignum.NewComplexMultiplier().Mul
 	sk2 := make([]*bignum.Complex, p.MaxSlots())
@@ -68,6 +121,23 @@ func NewEstimator(p ckks.Parameters) (e Estimator) {
 	return
 }
 
+// ShallowCopy returns a copy of the estimator sharing its read-only fields
+// (parameters, moduli and secret) but with its own Encoder buffers, so that
+// the copy and the original can be used concurrently. The copy has no Rand.
+func (e Estimator) ShallowCopy() Estimator {
+	e.Encoder = *e.Encoder.ShallowCopy()
+	e.Rand = nil
+	return e
+}
+
+// source returns the source of the sampled noise.
+func (e Estimator) source() *rand.Rand {
+	if e.Rand != nil {
+		return e.Rand
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
 func (e Estimator) SampleSecretKey(H int) (sk []*bignum.Complex) {
 
 	N := e.N()
@@ -87,23 +157,54 @@ func (e Estimator) SampleSecretKey(H int) (sk []*bignum.Complex) {
 		skF[i] = NewFloat(0)
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := e.source()
 
 	r.Shuffle(len(skF), func(i, j int) { skF[i], skF[j] = skF[j], skF[i] })
 
-	sk = make([]*bignum.Complex, e.MaxSlots())
-	for i := range sk {
-		sk[i] = &bignum.Complex{skF[2*i], skF[2*i+1]}
+	return e.RingToCanonical(skF)
+}
+
+// RingToCanonical maps the N coefficients of a polynomial to its canonical embedding.
+// For ring.ConjugateInvariant, the coefficients c_i are those of c_0 + sum c_i (X^i + X^-i)
+// in Z[X]/(X^2N+1), whose canonical embedding is real.
+func (e Estimator) RingToCanonical(coeffs []*big.Float) (values []*bignum.Complex) {
+
+	slots := e.MaxSlots()
+
+	values = make([]*bignum.Complex, slots)
+
+	if e.IsConjugateInvariant() {
+		// Z[X+X^-1]/(X^2N+1) -> Z[X]/(X^2N+1)
+		values[0] = &bignum.Complex{coeffs[0], NewFloat(0)}
+		for i := 1; i < slots; i++ {
+			values[i] = &bignum.Complex{coeffs[i], new(big.Float).Neg(coeffs[slots-i])}
+		}
+	} else {
+		for i := range values {
+			values[i] = &bignum.Complex{coeffs[i], coeffs[i+slots]}
+		}
 	}
 
 	// R[X]/(X^N+1) -> C^N/2
-	if err := e.Encoder.FFT(sk, e.LogMaxSlots()); err != nil {
+	if err := e.Encoder.FFT(values, e.LogMaxSlots()); err != nil {
 		panic(err)
 	}
 
+	if e.IsConjugateInvariant() {
+		for i := range values {
+			values[i][1].SetFloat64(0)
+		}
+	}
+
 	return
 }
 
+// IsConjugateInvariant returns true if the parameters
+// use the ring ring.ConjugateInvariant.
+func (e Estimator) IsConjugateInvariant() bool {
+	return e.Parameters.RingType() == ring.ConjugateInvariant
+}
+
 func (e Estimator) N() int {
 	return 1 << e.LogN
 }
@@ -112,7 +213,12 @@ func (e Estimator) MaxSlots() int {
 	return 1 << e.LogMaxSlots()
 }
 
+// LogMaxSlots returns LogN-1 for ring.Standard
+// and LogN for ring.ConjugateInvariant.
 func (e Estimator) LogMaxSlots() int {
+	if e.IsConjugateInvariant() {
+		return e.LogN
+	}
 	return e.LogN - 1
 }
 
@@ -127,6 +233,21 @@ func (e Estimator) DefaultScale() rlwe.Scale {
 // Decrypt decrypts the element by evaluating <(el0, el1, el2), (1, sk, sk^2)>.
 func (e Estimator) Decrypt(el *Element) (values []*bignum.Complex) {
 
+	values = e.phase(el)
+
+	scale := &el.Scale.Value
+
+	for i := range values {
+		values[i][0].Quo(values[i][0], scale)
+		values[i][1].Quo(values[i][1], scale)
+	}
+
+	return
+}
+
+// phase returns <(el0, el1, el2), (1, sk, sk^2)>, the decryption of el before the division by its scale.
+func (e Estimator) phase(el *Element) (values []*bignum.Complex) {
+
 	v := el.Value[0]
 
 	values = make([]*bignum.Complex, e.MaxSlots())
@@ -147,13 +268,6 @@ func (e Estimator) Decrypt(el *Element) (values []*bignum.Complex) {
 		}
 	}
 
-	scale := &el.Scale.Value
-
-	for i := range values {
-		values[i][0].Quo(values[i][0], scale)
-		values[i][1].Quo(values[i][1], scale)
-	}
-
 	return
 }
 
@@ -0,0 +1,67 @@
+package estimator
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestRingToCanonical(t *testing.T) {
+
+	for _, ringType := range []ring.Type{ring.Standard, ring.ConjugateInvariant} {
+		t.Run(ringType.String(), func(t *testing.T) {
+
+			params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+				LogN:            10,
+				LogQ:            []int{55, 45},
+				LogP:            []int{61},
+				LogDefaultScale: 45,
+				RingType:        ringType,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			est := NewEstimatorFromSeed(params, 1)
+			ecd := ckks.NewEncoder(params)
+			source := NewTestRand(1)
+
+			// Small positive integer coefficients, which are their own representatives mod Q.
+			pt := ckks.NewPlaintext(params, 0)
+			pt.IsNTT = false
+			pt.Scale = rlwe.NewScale(1)
+
+			coeffs := make([]*big.Float, est.N())
+			for i := range coeffs {
+				c := uint64(source.Intn(16))
+				pt.Value.Coeffs[0][i] = c
+				coeffs[i] = NewFloat(float64(c))
+			}
+
+			values := est.RingToCanonical(coeffs)
+
+			// The canonical embedding is the decoding of Lattigo.
+			want := make([]complex128, est.MaxSlots())
+			if err = ecd.Decode(pt, want); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range values {
+				if have := values[i].Complex128(); math.Abs(real(have)-real(want[i])) > 1e-6 || math.Abs(imag(have)-imag(want[i])) > 1e-6 {
+					t.Fatalf("slot %d: %v != %v", i, have, want[i])
+				}
+			}
+
+			// CanonicalToRing is the inverse of RingToCanonical.
+			for i, c := range est.CanonicalToRing(values) {
+				if have, _ := c.Float64(); math.Abs(have-float64(pt.Value.Coeffs[0][i])) > 1e-6 {
+					t.Fatalf("coefficient %d: %f != %d", i, have, pt.Value.Coeffs[0][i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package estimator
+
+import (
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// EvaluationKey models a key-switching key by the parameters that
+// determine the noise it adds: the levels at which it was generated,
+// its auxiliary modulus P, the standard deviation of its error and
+// the secret it switches from.
+type EvaluationKey struct {
+	LevelQ int
+	LevelP int
+	P      *big.Float
+	Sigma  float64
+	SkIn   []*bignum.Complex
+}
+
+// NewEvaluationKey returns an EvaluationKey switching from skIn, generated at
+// levelQ with the first levelP+1 auxiliary primes and an error of standard
+// deviation sigma.
+func (e Estimator) NewEvaluationKey(skIn []*bignum.Complex, levelQ, levelP int, sigma float64) (evk EvaluationKey) {
+
+	P := NewFloat(1)
+	for _, pj := range e.Parameters.P()[:levelP+1] {
+		P.Mul(P, NewFloat(pj))
+	}
+
+	return EvaluationKey{
+		LevelQ: levelQ,
+		LevelP: levelP,
+		P:      P,
+		Sigma:  sigma,
+		SkIn:   skIn,
+	}
+}
+
+// ApplyEvaluationKey switches the secret of el from evk.SkIn, which is
+// (el[0], el[1]) = (el[0] + el[1] * SkIn + round(sum(e_i * qalphai)/P), round(1/2))
+// where the qalphai are the moduli of the RNS digits of el[1] at min(el.Level, evk.LevelQ).
+func (e Estimator) ApplyEvaluationKey(el *Element, evk EvaluationKey) (err error) {
+	return e.ApplyEvaluationKeyWithDigits(el, evk, e.Decomposition(min(el.Level, evk.LevelQ), evk.LevelP))
+}
+
+// ApplyEvaluationKeyWithDigits is as ApplyEvaluationKey, but with the digits of el[1] uniform
+// in [-digits[i]/2, digits[i]/2). This models hoisted gadget products whose digits are not
+// the RNS decomposition of el[1].
+func (e Estimator) ApplyEvaluationKeyWithDigits(el *Element, evk EvaluationKey, digits []*big.Float) (err error) {
+
+	if e.Strict {
+		if err = e.checkElement("apply_evaluation_key", "el", el); err != nil {
+			return
+		}
+	}
+
+	if el.Degree != 1 {
+		return invariantError("apply_evaluation_key", "el", "degree %d != 1", el.Degree)
+	}
+
+	e.count(OpKeySwitch, el.Level)
+
+	e0 := e.GadgetProductNoiseRaw(el.Value[1], evk.SkIn, evk.P, evk.Sigma, digits)
+
+	r := e.RoundingNoise()
+
+	m0, m1 := el.Value[0], el.Value[1]
+	for i := range m0 {
+		e0[i][0].Quo(e0[i][0], evk.P)
+		e0[i][1].Quo(e0[i][1], evk.P)
+		m0[i].Add(m0[i], e0[i])
+		m0[i].Add(m0[i], r[i])
+	}
+
+	r = e.RoundingNoise()
+	for i := range m1 {
+		m1[i].Set(r[i])
+	}
+
+	return
+}
@@ -73,7 +73,7 @@ func main() {
 		values, el, _, ct := estParamsResidual.NewTestVector(ecd, pk, -1-1i, 1+1i)
 
 		// Encrypted
-		if ct, err = eval.Evaluate(ct); err != nil {
+		if ct, err = eval.Bootstrap(ct); err != nil {
 			panic(err)
 		}
 
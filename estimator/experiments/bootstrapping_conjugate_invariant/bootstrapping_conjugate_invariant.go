@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func main() {
+	LogN := 12
+	LogScale := 45
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            LogN,
+		LogQ:            []int{55, 45},
+		LogP:            []int{61, 61, 61},
+		LogDefaultScale: LogScale,
+		RingType:        ring.ConjugateInvariant,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	// The bootstrapping ring.Standard ring has twice the degree of the residual ring.ConjugateInvariant ring
+	btpParametersLit := bootstrapping.ParametersLiteral{}
+	btpParametersLit.LogN = utils.Pointy(params.LogN() + 1)
+	btpParametersLit.Xs = params.Xs()
+	btpParametersLit.LogP = []int{61, 61, 61, 61}
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpParametersLit)
+	if err != nil {
+		panic(err)
+	}
+
+	kgen := rlwe.NewKeyGenerator(params)
+
+	sk, pk := kgen.GenKeyPairNew()
+
+	ecd := ckks.NewEncoder(params)
+	dec := rlwe.NewDecryptor(params, sk)
+
+	evk, _, err := btpParams.GenEvaluationKeys(sk)
+	if err != nil {
+		panic(err)
+	}
+
+	var eval *bootstrapping.Evaluator
+	if eval, err = bootstrapping.NewEvaluator(btpParams, evk); err != nil {
+		panic(err)
+	}
+
+	evalEst := bootEst.NewEvaluator(btpParams)
+
+	estParamsResidual := evalEst.ResidualParameters
+
+	statsHave := [2]estimator.Stats{estimator.NewStats(), estimator.NewStats()}
+	statsWant := [2]estimator.Stats{estimator.NewStats(), estimator.NewStats()}
+
+	for i := 0; i < 1; i++ {
+
+		fmt.Println(i)
+
+		values0, el0, _, ct0 := estParamsResidual.NewTestVector(ecd, pk, -1, 1)
+		values1, el1, _, ct1 := estParamsResidual.NewTestVector(ecd, pk, -1, 1)
+
+		cts, err := eval.BootstrapMany([]rlwe.Ciphertext{*ct0, *ct1})
+		if err != nil {
+			panic(err)
+		}
+		ct0, ct1 = &cts[0], &cts[1]
+
+		// Simulated
+		els, err := evalEst.BootstrapMany([]*estimator.Element{el0, el1})
+		if err != nil {
+			panic(err)
+		}
+
+		for j, v := range [][]*bignum.Complex{values0, values1} {
+			pWant := ckks.GetPrecisionStats(params, ecd, dec, v, estParamsResidual.Decrypt(els[j]), 0, false)
+			pHave := ckks.GetPrecisionStats(params, ecd, dec, v, []*rlwe.Ciphertext{ct0, ct1}[j], 0, false)
+			statsWant[j].Add(pWant)
+			statsHave[j].Add(pHave)
+		}
+	}
+
+	for j := range statsWant {
+
+		statsWant[j].Finalize()
+		statsHave[j].Finalize()
+
+		fmt.Println(statsWant[j].String())
+		fmt.Println(statsHave[j].String())
+
+		fmt.Println(estimator.ToLaTeXTable(LogN, LogScale, statsWant[j], statsHave[j]))
+	}
+}
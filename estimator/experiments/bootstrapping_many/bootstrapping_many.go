@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func main() {
+	LogN := 14
+	LogScale := 45
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            LogN,
+		LogQ:            []int{55, 45},
+		LogP:            []int{61, 61, 61},
+		LogDefaultScale: LogScale,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	btpParametersLit := bootstrapping.ParametersLiteral{}
+	btpParametersLit.LogN = utils.Pointy(params.LogN())
+	btpParametersLit.Xs = params.Xs()
+	btpParametersLit.LogP = []int{61, 61, 61, 61}
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpParametersLit)
+	if err != nil {
+		panic(err)
+	}
+
+	kgen := rlwe.NewKeyGenerator(params)
+
+	sk, pk := kgen.GenKeyPairNew()
+
+	ecd := ckks.NewEncoder(params)
+	dec := rlwe.NewDecryptor(params, sk)
+
+	evk, _, err := btpParams.GenEvaluationKeys(sk)
+	if err != nil {
+		panic(err)
+	}
+
+	var eval *bootstrapping.Evaluator
+	if eval, err = bootstrapping.NewEvaluator(btpParams, evk); err != nil {
+		panic(err)
+	}
+
+	// Packing and unpacking are done in the residual parameters
+	evalResidual := ckks.NewEvaluator(params, rlwe.NewMemEvaluationKeySet(nil, kgen.GenGaloisKeyNew(params.GaloisElementForComplexConjugation(), sk)))
+
+	evalEst := bootEst.NewEvaluator(btpParams)
+
+	estParamsResidual := evalEst.ResidualParameters
+
+	statsHave := [2]estimator.Stats{estimator.NewStats(), estimator.NewStats()}
+	statsWant := [2]estimator.Stats{estimator.NewStats(), estimator.NewStats()}
+
+	for i := 0; i < 1; i++ {
+
+		fmt.Println(i)
+
+		values0, el0, _, ct0 := estParamsResidual.NewTestVector(ecd, pk, -1, 1)
+		values1, el1, _, ct1 := estParamsResidual.NewTestVector(ecd, pk, -1, 1)
+
+		// Encrypted
+		// ct0 + i * ct1
+		if err = evalResidual.Mul(ct1, 1i, ct1); err != nil {
+			panic(err)
+		}
+
+		if err = evalResidual.Add(ct0, ct1, ct0); err != nil {
+			panic(err)
+		}
+
+		if ct0, err = eval.Bootstrap(ct0); err != nil {
+			panic(err)
+		}
+
+		// (ct + conj(ct))/2 and (ct - conj(ct))/2i
+		ctConj, err := evalResidual.ConjugateNew(ct0)
+		if err != nil {
+			panic(err)
+		}
+
+		if ct1, err = evalResidual.SubNew(ct0, ctConj); err != nil {
+			panic(err)
+		}
+
+		if err = evalResidual.Mul(ct1, -1i, ct1); err != nil {
+			panic(err)
+		}
+
+		if err = evalResidual.Add(ct0, ctConj, ct0); err != nil {
+			panic(err)
+		}
+
+		ct0.Scale = ct0.Scale.Mul(rlwe.NewScale(2))
+		ct1.Scale = ct1.Scale.Mul(rlwe.NewScale(2))
+
+		// Simulated
+		els, err := evalEst.BootstrapMany([]*estimator.Element{el0, el1})
+		if err != nil {
+			panic(err)
+		}
+
+		for j, v := range [][]*bignum.Complex{values0, values1} {
+			pWant := ckks.GetPrecisionStats(params, ecd, dec, v, estParamsResidual.Decrypt(els[j]), 0, false)
+			pHave := ckks.GetPrecisionStats(params, ecd, dec, v, []*rlwe.Ciphertext{ct0, ct1}[j], 0, false)
+			statsWant[j].Add(pWant)
+			statsHave[j].Add(pHave)
+		}
+	}
+
+	for j := range statsWant {
+
+		statsWant[j].Finalize()
+		statsHave[j].Finalize()
+
+		fmt.Println(statsWant[j].String())
+		fmt.Println(statsHave[j].String())
+
+		fmt.Println(estimator.ToLaTeXTable(LogN, LogScale, statsWant[j], statsHave[j]))
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+)
+
+func main() {
+	LogN := 14
+	LogScale := 45
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            LogN,
+		LogQ:            []int{55, 45},
+		LogP:            []int{61, 61, 61},
+		LogDefaultScale: LogScale,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	btpParametersLit := bootstrapping.ParametersLiteral{}
+	btpParametersLit.LogN = utils.Pointy(params.LogN())
+	btpParametersLit.Xs = params.Xs()
+	btpParametersLit.LogP = []int{61, 61, 61, 61}
+
+	results, err := bootEst.Sweep(params, btpParametersLit, bootEst.SweepParameters{
+		LogMessageRatio:     []int{6, 8},
+		K:                   []int{12, 16},
+		DoubleAngle:         []int{2, 3},
+		CoeffsToSlotsLevels: []int{3, 4},
+		Samples:             1,
+		Heuristic:           true,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	for _, r := range results {
+		fmt.Println(r.String())
+	}
+
+	fmt.Println("Pareto front:")
+
+	for _, r := range bootEst.ParetoFront(results) {
+		fmt.Println(r.String())
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func main() {
+
+	trials := flag.Int("trials", 1024, "maximum number of trials")
+	workers := flag.Int("workers", 0, "number of goroutines (default: number of CPUs)")
+	seed := flag.Int64("seed", 0, "seed of the campaign")
+	halfWidth := flag.Float64("halfwidth", 0.01, "half-width in bits of the confidence interval stopping the campaign (0 disables early stopping)")
+	checkpoint := flag.String("checkpoint", "", "file to which the campaign is checkpointed, and from which it is resumed if it exists")
+	flag.Parse()
+
+	LogN := 14
+	LogScale := 45
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            LogN,
+		LogQ:            []int{55, 45},
+		LogP:            []int{60},
+		LogDefaultScale: LogScale,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	campaign := estimator.Campaign{
+		Parameters: params,
+		Trials:     *trials,
+		Workers:    *workers,
+		Seed:       *seed,
+		HalfWidth:  *halfWidth,
+		Checkpoint: *checkpoint,
+	}
+
+	state := estimator.NewCampaignState(*seed)
+
+	// The campaign is resumed with the seed of the checkpoint,
+	// from which the secret of the estimator is sampled again.
+	if *checkpoint != "" {
+		if _, err = os.Stat(*checkpoint); err == nil {
+			if err = estimator.LoadJSON(*checkpoint, &state); err != nil {
+				panic(err)
+			}
+			campaign.Seed = state.Seed
+			fmt.Printf("Resuming %d completed trials from %s\n", len(state.Completed), *checkpoint)
+		}
+	}
+
+	est := campaign.NewEstimator()
+	ecd := ckks.NewEncoder(params)
+
+	campaign.Secret = est.SecretID()
+
+	// Each worker evaluates mul_relin_rescale with its own copy of the estimator and of the encoder.
+	newTrial := func() (estimator.Trial, error) {
+
+		est := est.ShallowCopy()
+		ecd := ecd.ShallowCopy()
+		mul := bignum.NewComplexMultiplier().Mul
+
+		return func(source estimator.TestRand) (want, have []*bignum.Complex, err error) {
+
+			est.Rand = source.Rand
+
+			values0, el0, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, source)
+			values1, el1, _, _ := est.NewTestVectorFromSeed(ecd, nil, -1-1i, 1+1i, source)
+
+			est.AddEncryptionNoisePk(el0)
+			est.AddEncryptionNoisePk(el1)
+
+			for j := range values0 {
+				mul(values0[j], values1[j], values0[j])
+			}
+
+			if err = est.MulRelin(el0, el1, el0); err != nil {
+				return
+			}
+
+			if err = est.Rescale(el0, el0); err != nil {
+				return
+			}
+
+			return values0, est.Decrypt(el0), nil
+		}, nil
+	}
+
+	campaign.NewTrial = newTrial
+
+	now := time.Now()
+
+	res, err := campaign.Resume(state)
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Trials: %d (stopped: %t) in %s\n", res.Trials, res.Stopped, time.Since(now))
+	fmt.Printf("AVG L2 Prec: %.4f ± %.4f\n", res.Mean, res.HalfWidth)
+	fmt.Println(res.Stats.String())
+}
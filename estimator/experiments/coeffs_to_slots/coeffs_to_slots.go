@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/big"
 
@@ -14,6 +15,10 @@ import (
 
 func main() {
 
+	save := flag.String("save", "", "file to which the elements returned by CoeffsToSlots are saved (see estimator.Estimator.LoadElements)")
+	seed := flag.Int64("seed", 0, "seed of the secret of the estimator, with which the saved elements can be loaded")
+	flag.Parse()
+
 	LogN := 14
 	LogScale := 55
 	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
@@ -60,7 +65,7 @@ func main() {
 	sk, pk := kgen.GenKeyPairNew()
 	dec := ckks.NewDecryptor(params, sk)
 
-	est := estimator.NewEstimator(params)
+	est := estimator.NewEstimatorFromSeed(params, *seed)
 
 	statsHave := estimator.NewStats()
 	statsWant := estimator.NewStats()
@@ -105,6 +110,12 @@ func main() {
 			panic(err)
 		}
 
+		if *save != "" {
+			if err = est.SaveElements(*save, elReal, elImag); err != nil {
+				panic(err)
+			}
+		}
+
 		ctReal, ctImag, err := hdftEval.CoeffsToSlotsNew(ct, DFTMatrixHeFloat)
 
 		if err != nil {
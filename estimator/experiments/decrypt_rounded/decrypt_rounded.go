@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func main() {
+
+	LogN := 14
+	LogScale := 45
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            LogN,
+		LogQ:            []int{55, 45},
+		LogP:            []int{60},
+		LogDefaultScale: LogScale,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	ecd := ckks.NewEncoder(params)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPairNew()
+	dec := ckks.NewDecryptor(params, sk)
+
+	rlk := kgen.GenRelinearizationKeyNew(sk)
+
+	evk := rlwe.NewMemEvaluationKeySet(rlk)
+
+	eval := ckks.NewEvaluator(params, evk)
+
+	est := estimator.NewEstimator(params)
+
+	mul := bignum.NewComplexMultiplier().Mul
+
+	values0, el0, _, ct0 := est.NewTestVector(ecd, pk, -1-1i, 1+1i)
+	values1, el1, _, ct1 := est.NewTestVector(ecd, pk, -1-1i, 1+1i)
+
+	for j := range values0 {
+		mul(values0[j], values1[j], values0[j])
+	}
+
+	if err := eval.MulRelin(ct0, ct1, ct0); err != nil {
+		panic(err)
+	}
+
+	if err := eval.Rescale(ct0, ct0); err != nil {
+		panic(err)
+	}
+
+	if err := est.MulRelin(el0, el1, el0); err != nil {
+		panic(err)
+	}
+
+	if err := est.Rescale(el0, el0); err != nil {
+		panic(err)
+	}
+
+	// Rounds the decrypted ring element around the estimated noise
+	for margin := -4; margin <= 4; margin += 2 {
+
+		// Simulated
+		d, err := est.DecryptRoundedBelowNoise(el0, values0, margin)
+		if err != nil {
+			panic(err)
+		}
+
+		// Encrypted
+		pt := dec.DecryptNew(ct0)
+		est.TruncatePlaintext(pt, new(big.Int).Lsh(big.NewInt(1), uint(d.LogRound)))
+		pHave := ckks.GetPrecisionStats(params, ecd, nil, values0, pt, 0, false)
+		pWant := ckks.GetPrecisionStats(params, ecd, nil, values0, d.Values, 0, false)
+
+		fmt.Printf("margin=%2d LogRound=%2d | Predicted: Prec=%5.2f ChangedCoeffs=%.4f ChangedSlots=%.4f | Actual: Prec=%5.2f\n",
+			margin, d.LogRound, pWant.AVGLog2Prec.L2, d.ChangedCoeffs, d.ChangedSlots, pHave.AVGLog2Prec.L2)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/tuneinsight/ckks-noise-estimator"
 
@@ -77,6 +78,15 @@ func main() {
 
 		statsWant.Add(pWant)
 		statsHave.Add(pHave)
+
+		// Per-slot pooled distribution
+		have := make([]*bignum.Complex, params.MaxSlots())
+		if err := ecd.Decode(dec.DecryptNew(ct0), have); err != nil {
+			panic(err)
+		}
+
+		statsWant.AddSlots(values0, est.Decrypt(el0), float64(LogScale))
+		statsHave.AddSlots(values0, have, float64(LogScale))
 	}
 
 	statsWant.Finalize()
@@ -85,5 +95,23 @@ func main() {
 	fmt.Println(statsWant.String())
 	fmt.Println(statsHave.String())
 
-	fmt.Println(estimator.ToLaTeXTable(LogN, LogScale, statsWant, statsHave))
+	result := estimator.NewResult("mul_relin_rescale", params, 0, statsWant, statsHave)
+
+	table, err := result.MarkdownTable(estimator.TableOptions{
+		Rows: []estimator.Statistic{estimator.MINLog2Prec, estimator.AVGLog2Prec, estimator.MEDLog2Prec, estimator.STDLog2Prec},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(table)
+
+	if err = estimator.WriteJSON(os.Stdout, result); err != nil {
+		panic(err)
+	}
+
+	for _, q := range []float64{0.001, 0.01, 0.1, 0.5} {
+		fmt.Printf("Q%5.3f L2 Prec: Predicted %5.2f | Actual %5.2f\n", q, statsWant.Quantile(q).L2, statsHave.Quantile(q).L2)
+	}
 }
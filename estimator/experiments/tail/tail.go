@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func main() {
+
+	LogN := 12
+	LogScale := 45
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            LogN,
+		LogQ:            []int{55, 45},
+		LogP:            []int{60},
+		LogDefaultScale: LogScale,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	ecd := ckks.NewEncoder(params)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPairNew()
+	dec := ckks.NewDecryptor(params, sk)
+
+	rlk := kgen.GenRelinearizationKeyNew(sk)
+
+	evk := rlwe.NewMemEvaluationKeySet(rlk)
+
+	eval := ckks.NewEvaluator(params, evk)
+
+	est := estimator.NewEstimator(params)
+
+	mul := bignum.NewComplexMultiplier().Mul
+
+	models := []estimator.TailModel{estimator.GaussianTail, estimator.ParetoTail}
+
+	tailsWant := make([]estimator.TailStats, len(models))
+	tailsHave := make([]estimator.TailStats, len(models))
+	for i := range models {
+		tailsWant[i] = estimator.NewTailStats(models[i])
+		tailsHave[i] = estimator.NewTailStats(models[i])
+	}
+
+	for i := 0; i < 32; i++ {
+
+		values0, el0, _, ct0 := est.NewTestVector(ecd, pk, -1-1i, 1+1i)
+		values1, el1, _, ct1 := est.NewTestVector(ecd, pk, -1-1i, 1+1i)
+
+		for j := range values0 {
+			mul(values0[j], values1[j], values0[j])
+		}
+
+		if err := eval.MulRelin(ct0, ct1, ct0); err != nil {
+			panic(err)
+		}
+
+		if err := eval.Rescale(ct0, ct0); err != nil {
+			panic(err)
+		}
+
+		if err := est.MulRelin(el0, el1, el0); err != nil {
+			panic(err)
+		}
+
+		if err := est.Rescale(el0, el0); err != nil {
+			panic(err)
+		}
+
+		have := make([]*bignum.Complex, params.MaxSlots())
+		if err := ecd.Decode(dec.DecryptNew(ct0), have); err != nil {
+			panic(err)
+		}
+
+		want := est.Decrypt(el0)
+
+		for j := range models {
+			tailsWant[j].Add(values0, want)
+			tailsHave[j].Add(values0, have)
+		}
+	}
+
+	for j := range models {
+
+		fmt.Printf("Model %d\n", models[j])
+
+		for log2Bound := -36.0; log2Bound <= -28; log2Bound += 1 {
+
+			pWant, err := tailsWant[j].FailureProbability(log2Bound, 0.95)
+			if err != nil {
+				panic(err)
+			}
+
+			pHave, err := tailsHave[j].FailureProbability(log2Bound, 0.95)
+			if err != nil {
+				panic(err)
+			}
+
+			fmt.Printf("log2(bound)=%5.1f | Predicted: log2(P)=%8.2f [%8.2f, %8.2f] observed=%6d | Actual: log2(P)=%8.2f [%8.2f, %8.2f] observed=%6d / %d\n",
+				log2Bound,
+				pWant.Log2P, pWant.Log2PLow, pWant.Log2PHigh, pWant.Observed,
+				pHave.Log2P, pHave.Log2PLow, pHave.Log2PHigh, pHave.Observed, pHave.Samples)
+		}
+	}
+}
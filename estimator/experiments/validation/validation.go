@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tuneinsight/ckks-noise-estimator/validation"
+)
+
+func main() {
+
+	cases := flag.String("cases", "", "comma-separated names of the cases to run (default: all)")
+	trials := flag.Int("trials", 1, "number of trials per case")
+	seed := flag.Int64("seed", 0, "seed of the inputs")
+	alpha := flag.Float64("alpha", 1e-3, "significance level of the tests")
+	log2Std := flag.Float64("log2std", 0.5, "largest accepted difference of the log2 standard deviations of the errors")
+	ks := flag.Float64("ks", 0.1, "largest accepted Kolmogorov-Smirnov statistic")
+	flag.Parse()
+
+	selected := map[string]bool{}
+	for _, name := range strings.Split(*cases, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[name] = true
+		}
+	}
+
+	opts := validation.Options{
+		Trials:           *trials,
+		Seed:             *seed,
+		Alpha:            *alpha,
+		Log2StdTolerance: *log2Std,
+		KSTolerance:      *ks,
+	}
+
+	failed := 0
+
+	for _, c := range validation.Cases {
+
+		if len(selected) != 0 && !selected[c.Name] {
+			continue
+		}
+
+		r, err := validation.Run(c, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		fmt.Println(r.String())
+
+		if !r.Pass {
+			failed++
+		}
+	}
+
+	if failed != 0 {
+		fmt.Fprintf(os.Stderr, "%d case(s) failed\n", failed)
+		os.Exit(1)
+	}
+}
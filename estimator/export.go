@@ -0,0 +1,370 @@
+package estimator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// Statistic is a row of the exported results.
+type Statistic string
+
+const (
+	MINLog2Prec = Statistic("MIN Prec")
+	MAXLog2Prec = Statistic("MAX Prec")
+	AVGLog2Prec = Statistic("AVG Prec")
+	MEDLog2Prec = Statistic("MED Prec")
+	STDLog2Prec = Statistic("STD Prec")
+	MINLog2Err  = Statistic("MIN Err")
+	MAXLog2Err  = Statistic("MAX Err")
+	AVGLog2Err  = Statistic("AVG Err")
+	MEDLog2Err  = Statistic("MED Err")
+	STDLog2Err  = Statistic("STD Err")
+)
+
+// AllStatistics are all the statistics of a Stats.
+var AllStatistics = []Statistic{
+	MINLog2Prec, MAXLog2Prec, AVGLog2Prec, MEDLog2Prec, STDLog2Prec,
+	MINLog2Err, MAXLog2Err, AVGLog2Err, MEDLog2Err, STDLog2Err,
+}
+
+// Get returns the given statistic.
+func (s Stats) Get(stat Statistic) (ckks.Stats, error) {
+	switch stat {
+	case MINLog2Prec:
+		return s.MINLog2Prec, nil
+	case MAXLog2Prec:
+		return s.MAXLog2Prec, nil
+	case AVGLog2Prec:
+		return s.AVGLog2Prec, nil
+	case MEDLog2Prec:
+		return s.MEDLog2Prec, nil
+	case STDLog2Prec:
+		return s.STDLog2Prec, nil
+	case MINLog2Err:
+		return s.MINLog2Err, nil
+	case MAXLog2Err:
+		return s.MAXLog2Err, nil
+	case AVGLog2Err:
+		return s.AVGLog2Err, nil
+	case MEDLog2Err:
+		return s.MEDLog2Err, nil
+	case STDLog2Err:
+		return s.STDLog2Err, nil
+	default:
+		return ckks.Stats{}, fmt.Errorf("invalid statistic: %q", stat)
+	}
+}
+
+// ParametersSummary summarizes the parameters of an experiment.
+type ParametersSummary struct {
+	LogN            int
+	LogQ            []float64
+	LogP            []float64
+	LogDefaultScale int
+	RingType        string
+	H               int
+	Sigma           float64
+}
+
+// NewParametersSummary returns the summary of the given parameters.
+func NewParametersSummary(params ckks.Parameters) ParametersSummary {
+
+	logQ := make([]float64, params.QCount())
+	for i, qi := range params.Q() {
+		logQ[i] = math.Log2(float64(qi))
+	}
+
+	logP := make([]float64, params.PCount())
+	for i, pi := range params.P() {
+		logP[i] = math.Log2(float64(pi))
+	}
+
+	return ParametersSummary{
+		LogN:            params.LogN(),
+		LogQ:            logQ,
+		LogP:            logP,
+		LogDefaultScale: params.LogDefaultScale(),
+		RingType:        params.RingType().String(),
+		H:               params.XsHammingWeight(),
+		Sigma:           params.NoiseFreshSK(),
+	}
+}
+
+// Result is the predicted-vs-actual result of an experiment.
+type Result struct {
+	Circuit    string
+	Parameters ParametersSummary
+	Seed       int64
+	Trials     int
+	Predicted  Stats
+	Actual     Stats
+}
+
+// NewResult returns a new Result whose number of trials is the number of runs of predicted.
+func NewResult(circuit string, params ckks.Parameters, seed int64, predicted, actual Stats) Result {
+	return Result{
+		Circuit:    circuit,
+		Parameters: NewParametersSummary(params),
+		Seed:       seed,
+		Trials:     int(predicted.N),
+		Predicted:  predicted,
+		Actual:     actual,
+	}
+}
+
+// Row is a statistic of a Result.
+type Row struct {
+	Statistic Statistic
+	Predicted ckks.Stats
+	Actual    ckks.Stats
+}
+
+// Rows returns the given statistics of the result, or all of them if none is given.
+func (r Result) Rows(stats ...Statistic) (rows []Row, err error) {
+
+	if len(stats) == 0 {
+		stats = AllStatistics
+	}
+
+	rows = make([]Row, len(stats))
+
+	for i, stat := range stats {
+
+		rows[i].Statistic = stat
+
+		if rows[i].Predicted, err = r.Predicted.Get(stat); err != nil {
+			return nil, fmt.Errorf("r.Predicted.Get: %w", err)
+		}
+
+		if rows[i].Actual, err = r.Actual.Get(stat); err != nil {
+			return nil, fmt.Errorf("r.Actual.Get: %w", err)
+		}
+	}
+
+	return
+}
+
+// WriteJSON writes the results with all their statistics as a JSON array.
+func WriteJSON(w io.Writer, results ...Result) (err error) {
+
+	type jsonResult struct {
+		Circuit    string
+		Parameters ParametersSummary
+		Seed       int64
+		Trials     int
+		Statistics []Row
+	}
+
+	out := make([]jsonResult, len(results))
+
+	for i, r := range results {
+
+		out[i] = jsonResult{
+			Circuit:    r.Circuit,
+			Parameters: r.Parameters,
+			Seed:       r.Seed,
+			Trials:     r.Trials,
+		}
+
+		if out[i].Statistics, err = r.Rows(); err != nil {
+			return fmt.Errorf("r.Rows: %w", err)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err = enc.Encode(out); err != nil {
+		return fmt.Errorf("enc.Encode: %w", err)
+	}
+
+	return
+}
+
+// WriteCSV writes the results as CSV with one line per result and statistic.
+func WriteCSV(w io.Writer, results ...Result) (err error) {
+
+	cw := csv.NewWriter(w)
+
+	if err = cw.Write([]string{
+		"Circuit", "LogN", "LogQ", "LogP", "LogDefaultScale", "RingType", "H", "Sigma", "Seed", "Trials", "Statistic",
+		"PredictedReal", "PredictedImag", "PredictedL2",
+		"ActualReal", "ActualImag", "ActualL2",
+	}); err != nil {
+		return fmt.Errorf("cw.Write: %w", err)
+	}
+
+	f := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+
+	// The bit-sizes of the primes are joined with ';'.
+	join := func(xs []float64) string {
+		s := make([]string, len(xs))
+		for i, x := range xs {
+			s[i] = f(x)
+		}
+		return strings.Join(s, ";")
+	}
+
+	for _, r := range results {
+
+		var rows []Row
+		if rows, err = r.Rows(); err != nil {
+			return fmt.Errorf("r.Rows: %w", err)
+		}
+
+		for _, row := range rows {
+			if err = cw.Write([]string{
+				r.Circuit, strconv.Itoa(r.Parameters.LogN), join(r.Parameters.LogQ), join(r.Parameters.LogP),
+				strconv.Itoa(r.Parameters.LogDefaultScale), r.Parameters.RingType, strconv.Itoa(r.Parameters.H), f(r.Parameters.Sigma),
+				strconv.FormatInt(r.Seed, 10), strconv.Itoa(r.Trials), string(row.Statistic),
+				f(row.Predicted.Real), f(row.Predicted.Imag), f(row.Predicted.L2),
+				f(row.Actual.Real), f(row.Actual.Imag), f(row.Actual.L2),
+			}); err != nil {
+				return fmt.Errorf("cw.Write: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+
+	if err = cw.Error(); err != nil {
+		return fmt.Errorf("cw.Flush: %w", err)
+	}
+
+	return
+}
+
+// TableOptions are the options of the LaTeX and Markdown tables.
+type TableOptions struct {
+	// Rows are the statistics of the table (default: MIN, AVG and STD of the precision).
+	Rows []Statistic
+	// Caption is the caption of the table (default: the circuit, N and the scale).
+	// It is written as is, so that it can contain LaTeX markup (see LaTeXEscape).
+	Caption string
+	// Label is the LaTeX label of the table (default: tab:<circuit>, see LaTeXLabel).
+	Label string
+}
+
+// withDefaults sets the default options of the result, whose
+// circuit is escaped with escape in the default caption.
+func (opts TableOptions) withDefaults(r Result, escape func(string) string) TableOptions {
+
+	if len(opts.Rows) == 0 {
+		opts.Rows = []Statistic{MINLog2Prec, AVGLog2Prec, STDLog2Prec}
+	}
+
+	if opts.Caption == "" {
+		opts.Caption = fmt.Sprintf("%s with $N=2^{%d}$ and $\\Delta = 2^{%d}$", escape(r.Circuit), r.Parameters.LogN, r.Parameters.LogDefaultScale)
+	}
+
+	if opts.Label == "" {
+		opts.Label = LaTeXLabel(r.Circuit)
+	}
+
+	return opts
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	`_`, `\_`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`{`, `\{`,
+	`}`, `\}`,
+)
+
+// LaTeXEscape escapes the characters of s that are special in LaTeX text.
+func LaTeXEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+// LaTeXLabel returns the label tab:<name> in which the characters of
+// name other than letters, digits, '-', '.' and ':' are replaced by '_'.
+func LaTeXLabel(name string) string {
+	return "tab:" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// LaTeXTable returns the result as a LaTeX table.
+func (r Result) LaTeXTable(opts TableOptions) (table string, err error) {
+
+	opts = opts.withDefaults(r, LaTeXEscape)
+
+	rows, err := r.Rows(opts.Rows...)
+	if err != nil {
+		return "", fmt.Errorf("r.Rows: %w", err)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(`\begin{table}[]
+    \centering
+    \begin{tabular}{|c||c|c|c||c|c|c|}
+    \hline
+        & \multicolumn{3}{c||}{Predicted} & \multicolumn{3}{c|}{Actual}  \\
+        \hline
+        $\log_{2}$ & real & imag & l2 & real & imag & l2\\
+        \hline
+`)
+
+	for _, row := range rows {
+		fmt.Fprintf(&sb, "        %s & %5.2f & %5.2f & %5.2f & %5.2f & %5.2f & %5.2f \\\\\n",
+			LaTeXEscape(string(row.Statistic)),
+			row.Predicted.Real, row.Predicted.Imag, row.Predicted.L2,
+			row.Actual.Real, row.Actual.Imag, row.Actual.L2)
+	}
+
+	fmt.Fprintf(&sb, `        \hline
+    \end{tabular}
+    \caption{%s}
+    \label{%s}
+\end{table}
+`, opts.Caption, opts.Label)
+
+	return sb.String(), nil
+}
+
+// MarkdownTable returns the result as a Markdown table preceded by its caption.
+func (r Result) MarkdownTable(opts TableOptions) (table string, err error) {
+
+	opts = opts.withDefaults(r, func(s string) string { return s })
+
+	rows, err := r.Rows(opts.Rows...)
+	if err != nil {
+		return "", fmt.Errorf("r.Rows: %w", err)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "**%s**\n\n", opts.Caption)
+	sb.WriteString("| log2 | Predicted real | Predicted imag | Predicted l2 | Actual real | Actual imag | Actual l2 |\n")
+	sb.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+
+	for _, row := range rows {
+		fmt.Fprintf(&sb, "| %s | %.2f | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+			row.Statistic,
+			row.Predicted.Real, row.Predicted.Imag, row.Predicted.L2,
+			row.Actual.Real, row.Actual.Imag, row.Actual.L2)
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,176 @@
+package estimator
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+var update = flag.Bool("update", false, "update the golden files under testdata")
+
+func TestLaTeXEscape(t *testing.T) {
+
+	for _, tc := range []struct {
+		s, want string
+	}{
+		{"mul_relin", `mul\_relin`},
+		{`50% & #1 $x$ {a} ~b ^c \d`, `50\% \& \#1 \$x\$ \{a\} \textasciitilde{}b \textasciicircum{}c \textbackslash{}d`},
+		{"mul relin", "mul relin"},
+	} {
+		if have := LaTeXEscape(tc.s); have != tc.want {
+			t.Errorf("LaTeXEscape(%q): %q != %q", tc.s, have, tc.want)
+		}
+	}
+}
+
+func TestLaTeXLabel(t *testing.T) {
+	if have, want := LaTeXLabel(`decrypt_rounded margin=-4 {50%}`), "tab:decrypt_rounded_margin_-4__50__"; have != want {
+		t.Errorf("LaTeXLabel: %q != %q", have, want)
+	}
+}
+
+func TestLaTeXTable(t *testing.T) {
+
+	predicted := NewStats()
+	actual := NewStats()
+
+	r := Result{Circuit: "mul_relin 50%", Predicted: predicted, Actual: actual}
+	r.Parameters.LogN = 16
+	r.Parameters.LogDefaultScale = 45
+
+	table, err := r.LaTeXTable(TableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `\caption{mul\_relin 50\% with $N=2^{16}$ and $\Delta = 2^{45}$}`; !strings.Contains(table, want) {
+		t.Errorf("LaTeXTable does not contain %q:\n%s", want, table)
+	}
+
+	if want := `\label{tab:mul_relin_50_}`; !strings.Contains(table, want) {
+		t.Errorf("LaTeXTable does not contain %q:\n%s", want, table)
+	}
+
+	if table, err = r.MarkdownTable(TableOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "**mul_relin 50% with"; !strings.Contains(table, want) {
+		t.Errorf("MarkdownTable does not contain %q:\n%s", want, table)
+	}
+}
+
+// testResults returns results whose statistics are distinct values.
+func testResults() []Result {
+
+	results := []Result{
+		{
+			Circuit: "mul_relin 50%",
+			Parameters: ParametersSummary{
+				LogN:            16,
+				LogQ:            []float64{55, 45.5, 45.25},
+				LogP:            []float64{61, 60.5},
+				LogDefaultScale: 45,
+				RingType:        "Standard",
+				H:               192,
+				Sigma:           3.2,
+			},
+			Seed:   1,
+			Trials: 8,
+		},
+		{
+			Circuit: "bootstrapping",
+			Parameters: ParametersSummary{
+				LogN:            15,
+				LogQ:            []float64{60, 40},
+				LogP:            []float64{61},
+				LogDefaultScale: 40,
+				RingType:        "ConjugateInvariant",
+				Sigma:           3.2,
+			},
+			Seed:   -2,
+			Trials: 1,
+		},
+	}
+
+	for i := range results {
+
+		for j, stats := range []*Stats{&results[i].Predicted, &results[i].Actual} {
+
+			*stats = NewStats()
+
+			for k, stat := range []*ckks.Stats{
+				&stats.MINLog2Prec, &stats.MAXLog2Prec, &stats.AVGLog2Prec, &stats.MEDLog2Prec, &stats.STDLog2Prec,
+				&stats.MINLog2Err, &stats.MAXLog2Err, &stats.AVGLog2Err, &stats.MEDLog2Err, &stats.STDLog2Err,
+			} {
+				x := float64(10*i+5*j+k) + 0.125
+				*stat = ckks.Stats{Real: x, Imag: x + 0.25, L2: x - 0.5}
+			}
+		}
+	}
+
+	return results
+}
+
+func TestExportGolden(t *testing.T) {
+
+	results := testResults()
+
+	for _, tc := range []struct {
+		file  string
+		write func(w *bytes.Buffer) error
+	}{
+		{"results.json", func(w *bytes.Buffer) error { return WriteJSON(w, results...) }},
+		{"results.csv", func(w *bytes.Buffer) error { return WriteCSV(w, results...) }},
+		{"results.tex", func(w *bytes.Buffer) error {
+			for _, r := range results {
+				table, err := r.LaTeXTable(TableOptions{})
+				if err != nil {
+					return err
+				}
+				w.WriteString(table)
+			}
+			return nil
+		}},
+		{"results.md", func(w *bytes.Buffer) error {
+			for _, r := range results {
+				table, err := r.MarkdownTable(TableOptions{Rows: AllStatistics})
+				if err != nil {
+					return err
+				}
+				w.WriteString(table + "\n")
+			}
+			return nil
+		}},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+
+			var have bytes.Buffer
+			if err := tc.write(&have); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tc.file)
+
+			if *update {
+				if err := os.WriteFile(golden, have.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(have.Bytes(), want) {
+				t.Errorf("output differs from %s (run with -update to update it):\n%s", golden, have.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Flooding is a noise-flooding recommendation for q-IND-CPA-D security.
+type Flooding struct {
+	// Queries is the number of decryption queries.
+	Queries int
+	// SecurityLevel is the target security level in bits.
+	SecurityLevel float64
+	// ErrorStd is the standard deviation of the error of the element per ring coefficient.
+	ErrorStd float64
+	// ErrorBound is the bound t on the error of the element per ring coefficient,
+	// i.e. the largest absolute value of the coefficients of the error.
+	ErrorBound float64
+	// Sigma is the standard deviation of the flooding noise per ring coefficient.
+	Sigma float64
+	// Log2Precision is the log2 precision of the element after the flooding.
+	Log2Precision float64
+	// Log2PrecisionLoss is the number of bits of precision lost by the flooding.
+	Log2PrecisionLoss float64
+}
+
+// FloodingNoise returns the flooding noise that makes the decryption of el, whose error is
+// Decrypt(el) - want, safe to share for the given number of decryption queries and security
+// level, following Li, Micciancio, Schultz and Sorrell, "Securing Approximate Homomorphic
+// Encryption Using Differential Privacy" (CRYPTO 2022): the flooding noise has the standard
+// deviation Sigma = sqrt(24 * queries * N) * 2^(securityLevel/2) * t, where t bounds the
+// coefficients of the error of the scaled ring element with high probability. The bound t
+// is the largest coefficient of the error of el, which must thus be sampled with the same
+// noise distribution as the element whose decryption is shared.
+func (e Estimator) FloodingNoise(el *Element, want []*bignum.Complex, queries int, securityLevel float64) (f Flooding, err error) {
+
+	if queries < 1 {
+		return f, fmt.Errorf("invalid number of queries: %d < 1", queries)
+	}
+
+	if len(want) != e.MaxSlots() {
+		return f, fmt.Errorf("invalid want: len(want)=%d != MaxSlots=%d", len(want), e.MaxSlots())
+	}
+
+	coeffs := e.errorCoefficients(el, want)
+
+	// Variance and bound of the error per ring coefficient
+	variance := new(big.Float)
+	bound := new(big.Float)
+	tmp := new(big.Float)
+
+	for i := range coeffs {
+
+		tmp.Mul(coeffs[i], coeffs[i])
+		variance.Add(variance, tmp)
+
+		if tmp.Abs(coeffs[i]).Cmp(bound) > 0 {
+			bound.Set(tmp)
+		}
+	}
+
+	variance.Quo(variance, NewFloat(len(coeffs)))
+
+	f.Queries = queries
+	f.SecurityLevel = securityLevel
+	f.ErrorStd, _ = new(big.Float).Sqrt(variance).Float64()
+	f.ErrorBound, _ = bound.Float64()
+
+	if f.ErrorBound == 0 {
+		return f, fmt.Errorf("invalid el: the error Decrypt(el) - want is zero")
+	}
+
+	f.Sigma = math.Sqrt(24*float64(queries*e.N())) * math.Exp2(securityLevel/2) * f.ErrorBound
+
+	slotStd := f.ErrorStd * e.CanonicalExpansion()
+	floodStd := f.Sigma * e.CanonicalExpansion()
+
+	f.Log2Precision = -math.Log2(math.Sqrt(slotStd*slotStd+floodStd*floodStd) / el.Scale.Float64())
+	f.Log2PrecisionLoss = 0.5 * math.Log2(1+(f.Sigma/f.ErrorStd)*(f.Sigma/f.ErrorStd))
+
+	return
+}
+
+// AddFloodingNoise adds on el a Gaussian noise in the ring with standard deviation sigma,
+// e.g. Flooding.Sigma, which must be done before the decryption is shared.
+func (e Estimator) AddFloodingNoise(el *Element, sigma float64) {
+	noise := e.NormalNoise(sigma)
+	value := el.Value[0]
+	for i := range value {
+		value[i].Add(value[i], noise[i])
+	}
+}
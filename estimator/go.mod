@@ -2,7 +2,10 @@ module github.com/tuneinsight/ckks-noise-estimator
 
 go 1.21.1
 
-require github.com/tuneinsight/lattigo/v6 v6.1.1
+require (
+	github.com/tuneinsight/lattigo/v6 v6.1.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/ALTree/bigfloat v0.0.0-20220102081255-38c8b72a9924 // indirect
@@ -14,5 +17,4 @@ require (
 	golang.org/x/crypto v0.18.0 // indirect
 	golang.org/x/exp v0.0.0-20230321023759-10a507213a29 // indirect
 	golang.org/x/sys v0.16.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,142 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultBinsPerUnit is the default number of bins per unit of a Histogram.
+const DefaultBinsPerUnit = 64
+
+// Histogram is a mergeable streaming summary of a distribution. It counts the values in
+// bins of width 1/BinsPerUnit and tracks exactly their count, sum, sum of squares, minimum
+// and maximum. Quantiles are exact up to the width of a bin.
+// A Histogram is not safe for concurrent use: each goroutine should fill its own and merge it.
+type Histogram struct {
+	BinsPerUnit int
+	Counts      map[int]uint64
+	Count       uint64
+	Sum         float64
+	SumSquares  float64
+	Min         float64
+	Max         float64
+}
+
+// Bin is a bin [Low, High) of a Histogram.
+type Bin struct {
+	Low, High float64
+	Count     uint64
+}
+
+// NewHistogram returns a new empty Histogram with the given number of bins per unit.
+func NewHistogram(binsPerUnit int) Histogram {
+	return Histogram{
+		BinsPerUnit: binsPerUnit,
+		Counts:      map[int]uint64{},
+		Min:         math.Inf(1),
+		Max:         math.Inf(-1),
+	}
+}
+
+// Add adds x to the histogram.
+func (h *Histogram) Add(x float64) {
+
+	if h.Counts == nil {
+		*h = NewHistogram(max(h.BinsPerUnit, DefaultBinsPerUnit))
+	}
+
+	h.Counts[int(math.Floor(x*float64(h.BinsPerUnit)))]++
+	h.Count++
+	h.Sum += x
+	h.SumSquares += x * x
+	h.Min = min(h.Min, x)
+	h.Max = max(h.Max, x)
+}
+
+// Merge adds the values of other to the histogram.
+func (h *Histogram) Merge(other Histogram) (err error) {
+
+	if other.Count == 0 {
+		return
+	}
+
+	if h.Counts == nil {
+		*h = NewHistogram(other.BinsPerUnit)
+	}
+
+	if h.BinsPerUnit != other.BinsPerUnit {
+		return fmt.Errorf("cannot Merge: BinsPerUnit=%d != other.BinsPerUnit=%d", h.BinsPerUnit, other.BinsPerUnit)
+	}
+
+	for k, v := range other.Counts {
+		h.Counts[k] += v
+	}
+
+	h.Count += other.Count
+	h.Sum += other.Sum
+	h.SumSquares += other.SumSquares
+	h.Min = min(h.Min, other.Min)
+	h.Max = max(h.Max, other.Max)
+
+	return
+}
+
+// Mean returns the mean of the values.
+func (h Histogram) Mean() float64 {
+	return h.Sum / float64(h.Count)
+}
+
+// Std returns the standard deviation of the values.
+func (h Histogram) Std() float64 {
+	mean := h.Mean()
+	return math.Sqrt(max(h.SumSquares/float64(h.Count)-mean*mean, 0))
+}
+
+// Quantile returns the q-quantile of the values, interpolated linearly within its bin.
+func (h Histogram) Quantile(q float64) float64 {
+
+	if h.Count == 0 {
+		return math.NaN()
+	}
+
+	bins := h.Bins()
+
+	target := q * float64(h.Count)
+
+	var cumulative float64
+	for _, b := range bins {
+		if c := float64(b.Count); cumulative+c >= target {
+			x := b.Low + (target-cumulative)/c*(b.High-b.Low)
+			return min(max(x, h.Min), h.Max)
+		}
+		cumulative += float64(b.Count)
+	}
+
+	return h.Max
+}
+
+// Median returns the median of the values.
+func (h Histogram) Median() float64 {
+	return h.Quantile(0.5)
+}
+
+// Bins returns the non-empty bins sorted by increasing value.
+func (h Histogram) Bins() (bins []Bin) {
+
+	keys := make([]int, 0, len(h.Counts))
+	for k := range h.Counts {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+
+	width := 1 / float64(h.BinsPerUnit)
+
+	bins = make([]Bin, len(keys))
+	for i, k := range keys {
+		bins[i] = Bin{Low: float64(k) * width, High: float64(k+1) * width, Count: h.Counts[k]}
+	}
+
+	return
+}
@@ -0,0 +1,137 @@
+package estimator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+
+	h := NewHistogram(DefaultBinsPerUnit)
+
+	if !math.IsNaN(h.Quantile(0.5)) {
+		t.Fatalf("Quantile of an empty histogram: %f != NaN", h.Quantile(0.5))
+	}
+
+	// Uniform values in [-4, 4]
+	n := 1 << 14
+	for i := 0; i < n; i++ {
+		h.Add(-4 + 8*(float64(i)+0.5)/float64(n))
+	}
+
+	width := 1 / float64(DefaultBinsPerUnit)
+
+	for _, q := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99} {
+		if have, want := h.Quantile(q), -4+8*q; math.Abs(have-want) > width {
+			t.Errorf("Quantile(%f): %f != %f", q, have, want)
+		}
+	}
+
+	if h.Median() != h.Quantile(0.5) {
+		t.Errorf("Median: %f != Quantile(0.5)=%f", h.Median(), h.Quantile(0.5))
+	}
+
+	if have := h.Quantile(0); have < h.Min {
+		t.Errorf("Quantile(0): %f < Min=%f", have, h.Min)
+	}
+
+	if have := h.Quantile(1); have > h.Max {
+		t.Errorf("Quantile(1): %f > Max=%f", have, h.Max)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+
+	values := make([]float64, 4096)
+	for i := range values {
+		values[i] = math.Sin(float64(i)) * 10
+	}
+
+	all := NewHistogram(DefaultBinsPerUnit)
+	for _, x := range values {
+		all.Add(x)
+	}
+
+	t.Run("Halves", func(t *testing.T) {
+
+		a := NewHistogram(DefaultBinsPerUnit)
+		b := NewHistogram(DefaultBinsPerUnit)
+
+		for i, x := range values {
+			if i&1 == 0 {
+				a.Add(x)
+			} else {
+				b.Add(x)
+			}
+		}
+
+		if err := a.Merge(b); err != nil {
+			t.Fatal(err)
+		}
+
+		checkHistogramEqual(t, a, all)
+	})
+
+	t.Run("IntoZero", func(t *testing.T) {
+
+		var h Histogram
+		if err := h.Merge(all); err != nil {
+			t.Fatal(err)
+		}
+
+		checkHistogramEqual(t, h, all)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+
+		h := NewHistogram(DefaultBinsPerUnit)
+		for _, x := range values {
+			h.Add(x)
+		}
+
+		if err := h.Merge(NewHistogram(8)); err != nil {
+			t.Fatal(err)
+		}
+
+		checkHistogramEqual(t, h, all)
+	})
+
+	t.Run("BinsPerUnitMismatch", func(t *testing.T) {
+
+		h := NewHistogram(8)
+		h.Add(1)
+
+		if err := h.Merge(all); err == nil {
+			t.Fatal("Merge of histograms with different BinsPerUnit: no error")
+		}
+	})
+}
+
+func checkHistogramEqual(t *testing.T, have, want Histogram) {
+
+	t.Helper()
+
+	if have.Count != want.Count || have.Min != want.Min || have.Max != want.Max {
+		t.Fatalf("Count, Min, Max: (%d, %f, %f) != (%d, %f, %f)", have.Count, have.Min, have.Max, want.Count, want.Min, want.Max)
+	}
+
+	if math.Abs(have.Sum-want.Sum) > 1e-9*math.Abs(want.Sum) || math.Abs(have.SumSquares-want.SumSquares) > 1e-9*want.SumSquares {
+		t.Fatalf("Sum, SumSquares: (%f, %f) != (%f, %f)", have.Sum, have.SumSquares, want.Sum, want.SumSquares)
+	}
+
+	if len(have.Counts) != len(want.Counts) {
+		t.Fatalf("len(Counts): %d != %d", len(have.Counts), len(want.Counts))
+	}
+
+	for k, v := range want.Counts {
+		if have.Counts[k] != v {
+			t.Fatalf("Counts[%d]: %d != %d", k, have.Counts[k], v)
+		}
+	}
+
+	for _, q := range []float64{0.1, 0.5, 0.9} {
+		if have.Quantile(q) != want.Quantile(q) {
+			t.Fatalf("Quantile(%f): %f != %f", q, have.Quantile(q), want.Quantile(q))
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package estimator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+)
+
+// RequiredKeys are the evaluation keys used by a circuit, with the highest level,
+// i.e. the LevelQ of the key, at which each is used.
+type RequiredKeys struct {
+	// RelinearizationLevel is the highest level of a relinearization, or -1 if there is none.
+	RelinearizationLevel int
+	// GaloisLevels[g] is the highest level of an automorphism or a hoisted rotation by the Galois element g.
+	GaloisLevels map[uint64]int
+	// SwitchingLevels[k] are the highest level at which the switching key k of the bootstrapping
+	// is used and the LevelP of the key.
+	SwitchingLevels map[SwitchingKey]SwitchingLevels
+}
+
+// SwitchingKey names a key of the bootstrapping switching between the rings or the secrets,
+// as the field of the bootstrapping.EvaluationKeys of Lattigo.
+type SwitchingKey string
+
+const (
+	EvkN1ToN2        = SwitchingKey("EvkN1ToN2")
+	EvkN2ToN1        = SwitchingKey("EvkN2ToN1")
+	EvkRealToCmplx   = SwitchingKey("EvkRealToCmplx")
+	EvkCmplxToReal   = SwitchingKey("EvkCmplxToReal")
+	EvkDenseToSparse = SwitchingKey("EvkDenseToSparse")
+	EvkSparseToDense = SwitchingKey("EvkSparseToDense")
+)
+
+// SwitchingLevels are the levels of a switching key.
+type SwitchingLevels struct {
+	LevelQ int
+	LevelP int
+}
+
+// Relinearization returns true if a relinearization key is required.
+func (k RequiredKeys) Relinearization() bool {
+	return k.RelinearizationLevel >= 0
+}
+
+// GaloisElements returns the Galois elements of the required Galois keys, in increasing
+// order, e.g. to be given to rlwe.KeyGenerator.GenGaloisKeysNew.
+func (k RequiredKeys) GaloisElements() (galEls []uint64) {
+	for g := range k.GaloisLevels {
+		galEls = append(galEls, g)
+	}
+	sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
+	return
+}
+
+// SwitchingKeys returns the names of the required switching keys, in increasing order.
+func (k RequiredKeys) SwitchingKeys() (keys []SwitchingKey) {
+	for key := range k.SwitchingLevels {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return
+}
+
+// GenEvaluationKeySetNew generates the required keys of the secret sk, each at its level.
+// The optional evkParams set the other parameters of the keys, and their LevelQ is ignored.
+func (k RequiredKeys) GenEvaluationKeySetNew(kgen *rlwe.KeyGenerator, sk *rlwe.SecretKey, evkParams ...rlwe.EvaluationKeyParameters) *rlwe.MemEvaluationKeySet {
+
+	atLevel := func(level int) rlwe.EvaluationKeyParameters {
+		var p rlwe.EvaluationKeyParameters
+		if len(evkParams) != 0 {
+			p = evkParams[0]
+		}
+		p.LevelQ = &level
+		return p
+	}
+
+	var rlk *rlwe.RelinearizationKey
+	if k.Relinearization() {
+		rlk = kgen.GenRelinearizationKeyNew(sk, atLevel(k.RelinearizationLevel))
+	}
+
+	galEls := k.GaloisElements()
+	gks := make([]*rlwe.GaloisKey, len(galEls))
+	for i, g := range galEls {
+		gks[i] = kgen.GenGaloisKeyNew(g, sk, atLevel(k.GaloisLevels[g]))
+	}
+
+	return rlwe.NewMemEvaluationKeySet(rlk, gks...)
+}
+
+func (k RequiredKeys) String() string {
+
+	var sb strings.Builder
+
+	if k.Relinearization() {
+		fmt.Fprintf(&sb, "relinearization: level %d\n", k.RelinearizationLevel)
+	}
+
+	for _, g := range k.GaloisElements() {
+		fmt.Fprintf(&sb, "galois %d: level %d\n", g, k.GaloisLevels[g])
+	}
+
+	for _, key := range k.SwitchingKeys() {
+		l := k.SwitchingLevels[key]
+		fmt.Fprintf(&sb, "%s: level %d, levelP %d\n", key, l.LevelQ, l.LevelP)
+	}
+
+	return sb.String()
+}
+
+// KeyRequirements records the evaluation keys used by the operations of the estimators that share it:
+// the relinearization key, the Galois keys of the rotations, conjugations, hoisted rotations and
+// giant steps of the linear transformations and the switching keys of the bootstrapping. The keys of
+// the estimators of the bootstrapping, whose ring differs from the ring of the circuit, must be
+// recorded separately.
+type KeyRequirements struct {
+	mu   sync.Mutex
+	keys RequiredKeys
+}
+
+// NewKeyRequirements returns a new KeyRequirements.
+func NewKeyRequirements() *KeyRequirements {
+	return &KeyRequirements{keys: RequiredKeys{RelinearizationLevel: -1, GaloisLevels: map[uint64]int{}, SwitchingLevels: map[SwitchingKey]SwitchingLevels{}}}
+}
+
+// AddRelinearization records a relinearization at the given level.
+func (k *KeyRequirements) AddRelinearization(level int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys.RelinearizationLevel = max(k.keys.RelinearizationLevel, level)
+}
+
+// AddGaloisElement records an automorphism by the Galois element galEl at the given level.
+func (k *KeyRequirements) AddGaloisElement(galEl uint64, level int) {
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if l, ok := k.keys.GaloisLevels[galEl]; !ok || level > l {
+		k.keys.GaloisLevels[galEl] = level
+	}
+}
+
+// AddSwitchingKey records a use of the switching key at the given level, the key having levelP+1 auxiliary primes.
+func (k *KeyRequirements) AddSwitchingKey(key SwitchingKey, level, levelP int) {
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	l, ok := k.keys.SwitchingLevels[key]
+	if !ok {
+		l.LevelQ = level
+	}
+
+	k.keys.SwitchingLevels[key] = SwitchingLevels{LevelQ: max(l.LevelQ, level), LevelP: max(l.LevelP, levelP)}
+}
+
+// Keys returns a copy of the keys recorded so far.
+func (k *KeyRequirements) Keys() (keys RequiredKeys) {
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keys.RelinearizationLevel = k.keys.RelinearizationLevel
+	keys.GaloisLevels = map[uint64]int{}
+	for g, l := range k.keys.GaloisLevels {
+		keys.GaloisLevels[g] = l
+	}
+
+	keys.SwitchingLevels = map[SwitchingKey]SwitchingLevels{}
+	for key, l := range k.keys.SwitchingLevels {
+		keys.SwitchingLevels[key] = l
+	}
+
+	return
+}
+
+// requireGalois records the Galois keys if e.Keys is not nil.
+func (e Estimator) requireGalois(level int, galEls ...uint64) {
+	if e.Keys != nil {
+		for _, g := range galEls {
+			e.Keys.AddGaloisElement(g, level)
+		}
+	}
+}
+
+// requireRelinearization records the relinearization key if e.Keys is not nil.
+func (e Estimator) requireRelinearization(level int) {
+	if e.Keys != nil {
+		e.Keys.AddRelinearization(level)
+	}
+}
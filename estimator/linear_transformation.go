@@ -31,10 +31,27 @@ func (e Estimator) EvaluateLinearTransformationNew(elIn *Element, lt LinearTrans
 
 func (e Estimator) EvaluateLinearTransformation(elIn *Element, lt LinearTransformation, elOut *Element) (err error) {
 
+	if err = e.strictOperands("linear_transformation", elIn, elOut); err != nil {
+		return
+	}
+
 	if elIn.Degree != 1 {
-		return fmt.Errorf("elIn.Degree != 1")
+		return invariantError("linear_transformation", "elIn", "degree %d != 1", elIn.Degree)
+	}
+
+	if e.Strict {
+		if lt.LogSlots < 0 || lt.LogSlots > e.LogMaxSlots() {
+			return invariantError("linear_transformation", "lt", "LogSlots %d not in [0, %d]", lt.LogSlots, e.LogMaxSlots())
+		}
+		if lt.Scale.Value.Sign() <= 0 {
+			return invariantError("linear_transformation", "lt", "scale %v is not positive", &lt.Scale.Value)
+		}
 	}
 
+	// The range of the output is set from the diagonals rather than from the evaluation.
+	rIn, ranges := elIn.Range, e.Ranges
+	e.Ranges = nil
+
 	index, _, rotN2 := lt.BSGSIndex()
 
 	ctPreRot := map[int][]*bignum.Complex{}
@@ -49,13 +66,25 @@ func (e Estimator) EvaluateLinearTransformation(elIn *Element, lt LinearTransfor
 		}
 	}
 
-	// TODO: check that no scaling is applied
+	// The phase of elIn * P, which is checked against Q_level * P,
+	// overflows if and only if the phase of elIn overflows Q_level.
+	// The multiplication by P is not an operation of Lattigo.
+	overflow, counter := e.Overflow, e.Counter
+	e.Overflow, e.Counter = nil, nil
+
 	ctPreRot0, err := e.MulNew(elIn, e.P)
 
 	if err != nil {
 		return fmt.Errorf("e.MulNew: %w", err)
 	}
 
+	// P is an integer, so that the multiplication does not change the scale.
+	if e.Strict && ctPreRot0.Scale.Cmp(elIn.Scale) != 0 {
+		return invariantError("linear_transformation", "elIn", "the multiplication by P changed the scale from 2^%.4f to 2^%.4f", elIn.Scale.Log2(), ctPreRot0.Scale.Log2())
+	}
+
+	e.Overflow, e.Counter = overflow, counter
+
 	keys := utils.GetSortedKeys(index)
 
 	scale := &lt.Scale.Value
@@ -75,6 +104,11 @@ func (e Estimator) EvaluateLinearTransformation(elIn *Element, lt LinearTransfor
 
 	mul := bignum.NewComplexMultiplier().Mul
 
+	var done int
+	if err = e.Checkpoint("linear_transformation", done, len(lt.Value)); err != nil {
+		return
+	}
+
 	for _, j := range keys {
 
 		rot := -j & (slots - 1)
@@ -153,11 +187,21 @@ func (e Estimator) EvaluateLinearTransformation(elIn *Element, lt LinearTransfor
 				}
 			}
 
+			e.count(OpMulPlaintext, elIn.Level)
+
 			cnt++
+
+			done++
+			if err = e.Checkpoint("linear_transformation", done, len(lt.Value)); err != nil {
+				return
+			}
 		}
 
 		if j != 0 {
 
+			e.count(OpAutomorphism, elOut.Level, e.Parameters.GaloisElement(j))
+			e.requireGalois(elOut.Level, e.Parameters.GaloisElement(j))
+
 			m0 := acc.Value[0]
 			e0 := e.KeySwitchingNoiseRaw(elOut.Level, e.RoundingNoise(), e.Sk[0])
 			for k := range m0 {
@@ -175,7 +219,12 @@ func (e Estimator) EvaluateLinearTransformation(elIn *Element, lt LinearTransfor
 		}
 	}
 
+	e.checkOverflow("linear_transformation", elOut, e.modulusP(elOut.Level))
+
 	e.ModDown(elOut, elOut)
 
-	return
+	e.Ranges = ranges
+	e.linearTransformationRange(rIn, lt, elOut)
+
+	return e.strictOutput("linear_transformation", "elOut", elOut)
 }
@@ -40,7 +40,14 @@ func (e Estimator) EvaluateMod1AndScaleNew(elIn *Element, evm mod1.Parameters, s
 	elOut.Level = evm.LevelQ
 
 	// Normalize the modular reduction to mod by 1 (division by Q)
-	elOut.Scale = evm.ScalingFactor()
+	e.ReplaceScale(elOut, evm.ScalingFactor())
+
+	// The input, normalized by 1/K, must be in [-1, 1]
+	if r := elOut.Range; e.Ranges != nil && r != nil {
+		if !(Interval{Min: -1, Max: 1}).Contains(r.Real) {
+			e.Ranges.warn("mod1: the input, of real part in %v, may leave the interval [-K, K] = [-%.6g, %.6g]", r.Real.Mul(Interval{Min: evm.K, Max: evm.K}), evm.K, evm.K)
+		}
+	}
 
 	// Compute the scales that the ciphertext should have before the double angle
 	// formula such that after it it has the scale it had before the polynomial
@@ -141,7 +148,7 @@ func (e Estimator) EvaluateMod1AndScaleNew(elIn *Element, evm mod1.Parameters, s
 	runtime.GC()
 
 	// Multiplies back by q
-	elOut.Scale = elIn.Scale
+	e.ReplaceScale(elOut, elIn.Scale)
 
 	return
 }
@@ -3,8 +3,6 @@ package estimator
 import (
 	"math"
 	"math/big"
-	"math/rand"
-	"time"
 
 	"github.com/tuneinsight/lattigo/v6/utils/bignum"
 )
@@ -12,52 +10,32 @@ import (
 // Noise samples noise in R[X]/(X^N+1) according to f(), scales it by 2^-logScale and
 // returns it in C^N/2.
 func (e Estimator) Noise(f func() *big.Float) (noise []*bignum.Complex) {
-	noise = make([]*bignum.Complex, e.MaxSlots())
-	for i := range noise {
-		noise[i] = &bignum.Complex{f(), f()}
-	}
-
-	// R[X]/(X^N+1) -> C^N/2
-	if err := e.Encoder.FFT(noise, e.LogMaxSlots()); err != nil {
-		panic(err)
+	coeffs := make([]*big.Float, e.N())
+	for i := range coeffs {
+		coeffs[i] = f()
 	}
-
-	return
+	return e.RingToCanonical(coeffs)
 }
 
 // NoiseRingToCanonical samples a noisy vector with standard deviation
 // sigma * sqrt(N/2), which emulates the sampling in the ring followed
 // by the encoding of the noisy vector with the canonical embeding.
 func (e Estimator) NoiseRingToCanonical(sigma float64) (noise []*bignum.Complex) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	// R[X]/(X^N+1) -> C^N/2 increases variance by sqrt(N/2)
-	sigma *= math.Sqrt(float64(e.N() / 2))
-
-	f := func() *big.Float {
-
-		s := r.NormFloat64() * sigma
-
-		if r.Int()&1 == 0 {
-			s *= -1
-		}
-
-		return NewFloat(s)
-	}
-
 	noise = make([]*bignum.Complex, e.MaxSlots())
 	for i := range noise {
-		noise[i] = &bignum.Complex{f(), f()}
+		noise[i] = bignum.NewComplex().SetPrec(prec)
 	}
-
+	e.AddNoiseRingToCanonical(sigma, noise)
 	return
 }
 
+// AddNoiseRingToCanonical adds on noise a vector sampled as by NoiseRingToCanonical.
+// For ring.ConjugateInvariant, the noise is real and Z[X+X^-1]/(X^2N+1) -> R^N
+// increases the variance by sqrt(2N).
 func (e Estimator) AddNoiseRingToCanonical(sigma float64, noise []*bignum.Complex) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := e.source()
 
-	// R[X]/(X^N+1) -> C^N/2 increases variance by sqrt(N/2)
-	sigma *= math.Sqrt(float64(e.N() / 2))
+	sigma *= e.CanonicalExpansion()
 
 	f := func() *big.Float {
 
@@ -71,10 +49,24 @@ func (e Estimator) AddNoiseRingToCanonical(sigma float64, noise []*bignum.Comple
 	}
 
 	for i := range noise {
-		noise[i].Add(noise[i], &bignum.Complex{f(), f()})
+		if e.IsConjugateInvariant() {
+			noise[i][0].Add(noise[i][0], f())
+		} else {
+			noise[i].Add(noise[i], &bignum.Complex{f(), f()})
+		}
 	}
 }
 
+// CanonicalExpansion returns the factor by which the standard deviation of
+// a noise sampled in the ring increases in the canonical embedding, which is
+// sqrt(N/2) for ring.Standard and sqrt(2N) for ring.ConjugateInvariant.
+func (e Estimator) CanonicalExpansion() float64 {
+	if e.IsConjugateInvariant() {
+		return math.Sqrt(float64(2 * e.N()))
+	}
+	return math.Sqrt(float64(e.N() / 2))
+}
+
 // RoundingNoise samples a rounding error in the ring and decode it into the canonical embeding
 // Standard deviation: sqrt(1/12)
 func (e Estimator) RoundingNoise() (noise []*bignum.Complex) {
@@ -83,7 +75,7 @@ func (e Estimator) RoundingNoise() (noise []*bignum.Complex) {
 		return e.NoiseRingToCanonical(math.Sqrt(1 / 12.0))
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := e.source()
 	return e.Noise(func() *big.Float { return NewFloat(r.Float64() - 0.5) })
 }
 
@@ -93,7 +85,7 @@ func (e Estimator) NormalNoise(sigma float64) (noise []*bignum.Complex) {
 		return e.NoiseRingToCanonical(sigma)
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := e.source()
 
 	f := func() *big.Float {
 
@@ -133,8 +125,40 @@ func (e Estimator) KeySwitchingNoise(levelQ int, eCt, sk []*bignum.Complex) (e0,
 // Standard Deviation: sqrt(N * (var(noise_base) * var(SK) * P + var(noise_key) * sum(var(q_alpha_i)))
 // Returns eCt * sk * P + sum(e_i * qalphai)
 func (e Estimator) KeySwitchingNoiseRaw(levelQ int, eCt, sk []*bignum.Complex) (noise []*bignum.Complex) {
+	return e.GadgetProductNoiseRaw(eCt, sk, e.P, e.Sigma, e.Decomposition(levelQ, e.LevelP))
+}
 
-	P := e.P
+// Decomposition returns the moduli qalphai of the RNS digits of
+// an element at levelQ decomposed with levelP+1 auxiliary primes.
+func (e Estimator) Decomposition(levelQ, levelP int) (qalpha []*big.Float) {
+
+	decompRNS := DecompRNS(levelQ, levelP)
+
+	qalpha = make([]*big.Float, decompRNS)
+
+	for i := 0; i < decompRNS; i++ {
+
+		start := i * (levelP + 1)
+		end := (i + 1) * (levelP + 1)
+
+		if i == decompRNS-1 {
+			end = levelQ + 1
+		}
+
+		// prod[qi * ... * ]
+		qalpha[i] = NewFloat(1)
+		for j := start; j < end; j++ {
+			qalpha[i].Mul(qalpha[i], &e.Q[j])
+		}
+	}
+
+	return
+}
+
+// GadgetProductNoiseRaw returns eCt * sk * P + sum(e_i * d_i), where the
+// digits d_i are uniform in [-digits[i]/2, digits[i]/2) and the e_i are
+// the errors of the key, of standard deviation sigma.
+func (e Estimator) GadgetProductNoiseRaw(eCt, sk []*bignum.Complex, P *big.Float, sigma float64, digits []*big.Float) (noise []*bignum.Complex) {
 
 	noise = make([]*bignum.Complex, e.MaxSlots())
 
@@ -152,33 +176,18 @@ func (e Estimator) KeySwitchingNoiseRaw(levelQ int, eCt, sk []*bignum.Complex) (
 		noise[i].Add(noise[i], tmp)
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := e.source()
 
 	// var(noise_ct) * var(H) * P + var(ekey) * sum(var(q_alpha_i)))
-	decompRNS := DecompRNS(levelQ, e.LevelP)
-
 	if e.Heuristic {
 
 		// sqrt(sum qalphi^2) * sqrt(1/12) * sqrt(N) * eSWK
 		sumQAlhai := new(big.Float)
 
-		for i := 0; i < decompRNS; i++ {
-
-			start := i * (e.LevelP + 1)
-			end := (i + 1) * (e.LevelP + 1)
-
-			if i == decompRNS-1 {
-				end = levelQ + 1
-			}
-
-			// prod[qi * ... * ]
-			qalphai := NewFloat(1)
-			for i := start; i < end; i++ {
-				qalphai.Mul(qalphai, &e.Q[i])
-			}
+		for i := range digits {
 
 			// variances (delays)
-			qalphai.Mul(qalphai, qalphai)
+			qalphai := new(big.Float).Mul(digits[i], digits[i])
 
 			// sum of variances
 			sumQAlhai.Add(sumQAlhai, qalphai)
@@ -187,49 +196,28 @@ func (e Estimator) KeySwitchingNoiseRaw(levelQ int, eCt, sk []*bignum.Complex) (
 		// Uniform distribution
 		sumQAlhai.Mul(sumQAlhai, NewFloat(1/12.0))
 
-		// Ring expansion
-		sumQAlhai.Mul(sumQAlhai, NewFloat(e.N()))
+		// Ring expansion (N for ring.Standard and 2N for ring.ConjugateInvariant)
+		sumQAlhai.Mul(sumQAlhai, NewFloat(2*e.MaxSlots()))
 
 		// Variance -> Standard Deviation
 		sumQAlhai.Sqrt(sumQAlhai)
 
 		f64, _ := sumQAlhai.Float64()
 
-		e.AddNoiseRingToCanonical(f64*e.Sigma, noise)
-
-		/*
-			stdR, stdI := Log2STD(e)
-			maxR, maxI := Log2MAX(e)
-			avgR, avgI := Log2AVG(e)
-
-			fmt.Println(stdR, stdI)
-			fmt.Println(maxR, maxI)
-			fmt.Println(avgR, avgI)
-			fmt.Println()
-		*/
+		e.AddNoiseRingToCanonical(f64*sigma, noise)
 
 	} else {
-		for i := 0; i < decompRNS; i++ {
-
-			start := i * (e.LevelP + 1)
-			end := (i + 1) * (e.LevelP + 1)
-
-			if i == decompRNS-1 {
-				end = levelQ + 1
-			}
+		for i := range digits {
 
 			//std(q_alpha_i) * eKey
-			qalphai := NewFloat(1)
-			for i := start; i < end; i++ {
-				qalphai.Mul(qalphai, &e.Q[i])
-			}
+			qalphai := digits[i]
 
 			qalphaiHalf := new(big.Float).Quo(qalphai, new(big.Float).SetInt64(2))
 
 			qalphaInt := new(big.Int)
 			qalphai.Int(qalphaInt)
 
-			ei := e.NormalNoise(e.Sigma)
+			ei := e.NormalNoise(sigma)
 
 			f := func() (x *big.Float) {
 				y := bignum.RandInt(r, qalphaInt)
@@ -246,17 +234,6 @@ func (e Estimator) KeySwitchingNoiseRaw(levelQ int, eCt, sk []*bignum.Complex) (
 				noise[i].Add(noise[i], ei[i])
 			}
 		}
-
-		/*
-			stdR, stdI := Log2STD(noise)
-			maxR, maxI := Log2MAX(noise)
-			avgR, avgI := Log2AVG(noise)
-
-			fmt.Println(stdR, stdI)
-			fmt.Println(maxR, maxI)
-			fmt.Println(avgR, avgI)
-			fmt.Println()
-		*/
 	}
 
 	return
@@ -2,6 +2,7 @@ package estimator
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/tuneinsight/lattigo/v6/core/rlwe"
@@ -17,9 +18,15 @@ func (e Estimator) AddNew(op0 *Element, op1 rlwe.Operand) (op2 *Element, err err
 // Add adds elIn to op1 and writes the result on p, also returns p.
 func (e Estimator) Add(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error) {
 
+	if err = e.strictOperands("add", op0, op1, op2); err != nil {
+		return
+	}
+
 	switch op1 := op1.(type) {
 	case *Element:
 
+		s0, s1 := matchedScales(op0.Scale, op1.Scale)
+
 		var tmp0, tmp1 *Element
 
 		switch op0.Scale.Cmp(op1.Scale) {
@@ -59,6 +66,14 @@ func (e Estimator) Add(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 			tmp0 = op0
 		}
 
+		if err = e.strictScale("add", "op0", s0, op2.Scale); err != nil {
+			return
+		}
+
+		if err = e.strictScale("add", "op1", s1, op2.Scale); err != nil {
+			return
+		}
+
 		op2.Level = min(op0.Level, op1.Level)
 		op2.Degree = max(op0.Degree, op1.Degree)
 
@@ -109,7 +124,11 @@ func (e Estimator) Add(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 		return fmt.Errorf("invalid op1.(type): must be *Element, complex128, float64, int, int64, uint, uint64, *big.Int, *big.Float, *bignum.Complex, []complex128, []float64, []*big.Float or []*bignum.Complex, but is %T", op1)
 	}
 
-	return
+	e.rangeBinary("add", op0.Range, op1, op2, Range.Add)
+	e.CheckOverflow("add", op2)
+	e.count(OpAdd, op2.Level)
+
+	return e.strictOutput("add", "op2", op2)
 }
 
 func (e Estimator) SubNew(op0 *Element, op1 rlwe.Operand) (op2 *Element, err error) {
@@ -120,9 +139,15 @@ func (e Estimator) SubNew(op0 *Element, op1 rlwe.Operand) (op2 *Element, err err
 // Sub subtracts op1 to op0 and writes the result on op2.
 func (e Estimator) Sub(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error) {
 
+	if err = e.strictOperands("sub", op0, op1, op2); err != nil {
+		return
+	}
+
 	switch op1 := op1.(type) {
 	case *Element:
 
+		s0, s1 := matchedScales(op0.Scale, op1.Scale)
+
 		var tmp0, tmp1 *Element
 
 		switch op0.Scale.Cmp(op1.Scale) {
@@ -162,6 +187,14 @@ func (e Estimator) Sub(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 			tmp0 = op0
 		}
 
+		if err = e.strictScale("sub", "op0", s0, op2.Scale); err != nil {
+			return
+		}
+
+		if err = e.strictScale("sub", "op1", s1, op2.Scale); err != nil {
+			return
+		}
+
 		op2.Level = min(op0.Level, op1.Level)
 		op2.Degree = max(op0.Degree, op1.Degree)
 
@@ -211,7 +244,11 @@ func (e Estimator) Sub(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 		return fmt.Errorf("invalid op1.(type): must be *Element, complex128, float64, int, int64, uint, uint64, *big.Int, *big.Float, *bignum.Complex, []complex128, []float64, []*big.Float or []*bignum.Complex, but is %T", op1)
 	}
 
-	return
+	e.rangeBinary("sub", op0.Range, op1, op2, Range.Sub)
+	e.CheckOverflow("sub", op2)
+	e.count(OpAdd, op2.Level)
+
+	return e.strictOutput("sub", "op2", op2)
 }
 
 func (e Estimator) MulRelin(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error) {
@@ -247,13 +284,20 @@ func (e Estimator) MulNew(op0 *Element, op1 rlwe.Operand) (op2 *Element, err err
 
 func (e Estimator) Mul(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error) {
 
+	if err = e.strictOperands("mul", op0, op1, op2); err != nil {
+		return
+	}
+
 	mul := bignum.NewComplexMultiplier().Mul
 
+	// Bound on the error of the encoding of the constants
+	var constNoise float64
+
 	switch op1 := op1.(type) {
 	case *Element:
 
 		if op0.Degree+op1.Degree > 2 {
-			return fmt.Errorf("invalid input degree: sum cannot exceed 2")
+			return invariantError("mul", "op1", "degree %d + degree %d of op0 > 2", op1.Degree, op0.Degree)
 		}
 
 		// m0 * m1
@@ -295,6 +339,7 @@ func (e Estimator) Mul(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 			bComplex[0].Mul(bComplex[0], &e.Q[op2.Level])
 			bComplex[1].Mul(bComplex[1], &e.Q[op2.Level])
 			op2.Scale = op0.Scale.Mul(rlwe.NewScale(e.Q[op2.Level]))
+			constNoise = math.Sqrt2 / 2 / rlwe.NewScale(e.Q[op2.Level]).Float64()
 		} else {
 			op2.Scale = op0.Scale
 		}
@@ -315,6 +360,7 @@ func (e Estimator) Mul(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 
 		scale := e.Q[op2.Level]
 		op2.Scale = op0.Scale.Mul(rlwe.NewScale(scale))
+		constNoise = math.Sqrt2 / 2 / rlwe.NewScale(scale).Float64()
 
 		for i := range op1 {
 			bComplex[0].Mul(op1[i][0], &scale)
@@ -328,18 +374,35 @@ func (e Estimator) Mul(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error)
 		return fmt.Errorf("invalid op1.(type): must be *Element, complex128, float64, int, int64, uint, uint64, *big.Int, *big.Float, *bignum.Complex, []complex128, []float64, []*big.Float or []*bignum.Complex, but is %T", op1)
 	}
 
-	return
+	e.rangeBinary("mul", op0.Range, op1, op2, func(r0, r1 Range) Range {
+		r := r0.Mul(r1)
+		r.Noise += math.Hypot(r0.Real.Abs(), r0.Imag.Abs()) * constNoise
+		return r
+	})
+
+	e.CheckOverflow("mul", op2)
+	e.countMul(op2.Level, op1)
+
+	return e.strictOutput("mul", "op2", op2)
 }
 
 func (e Estimator) MulThenAdd(op0 *Element, op1 rlwe.Operand, op2 *Element) (err error) {
 
+	if err = e.strictOperands("mul_then_add", op0, op1, op2); err != nil {
+		return
+	}
+
 	mul := bignum.NewComplexMultiplier().Mul
 
+	// The scaling of op2 does not change its range.
+	r0, r2, ranges := op0.Range, op2.Range, e.Ranges
+	e.Ranges = nil
+
 	switch op1 := op1.(type) {
 	case *Element:
 
 		if op0.Degree+op1.Degree > 2 {
-			return fmt.Errorf("invalid input degree: sum cannot exceed 2")
+			return invariantError("mul_then_add", "op1", "degree %d + degree %d of op0 > 2", op1.Degree, op0.Degree)
 		}
 
 		resScale := op0.Scale.Mul(op1.Scale)
@@ -348,10 +411,17 @@ func (e Estimator) MulThenAdd(op0 *Element, op1 rlwe.Operand, op2 *Element) (err
 			// Only scales up if int(ratio) >= 2
 			if ratio.Float64() >= 2.0 {
 				e.Mul(op2, &ratio.Value, op2)
+				if err = e.strictScale("mul_then_add", "op2", op2.Scale, resScale); err != nil {
+					return
+				}
 				op2.Scale = resScale
 			}
 		}
 
+		if err = e.strictScale("mul_then_add", "op2", op2.Scale, resScale); err != nil {
+			return
+		}
+
 		if op0.Degree == 1 && op1.Degree == 1 {
 			// m0 * m1
 			m00 := op0.Value[0] // (m0 + e00)
@@ -444,6 +514,9 @@ func (e Estimator) MulThenAdd(op0 *Element, op1 rlwe.Operand, op2 *Element) (err
 		} else if cmp == -1 { // opOut.Scale > op0.Scale then the scaling factor for op1 becomes the quotient between the two scales
 			scaleRLWE = op2.Scale.Div(op0.Scale)
 		} else {
+			if e.Strict {
+				return invariantError("mul_then_add", "op2", "scale 2^%.4f < scale 2^%.4f of op0", op2.Scale.Log2(), op0.Scale.Log2())
+			}
 			panic(fmt.Errorf("cannot MulThenAdd: op0.Scale > opOut.Scale is not supported"))
 		}
 
@@ -465,6 +538,11 @@ func (e Estimator) MulThenAdd(op0 *Element, op1 rlwe.Operand, op2 *Element) (err
 		var bComplex, acc bignum.Complex
 
 		scale := &e.Q[op2.Level]
+
+		if err = e.strictScale("mul_then_add", "op2", op2.Scale, op0.Scale.Mul(rlwe.NewScale(scale))); err != nil {
+			return
+		}
+
 		op2.Scale = op0.Scale.Mul(rlwe.NewScale(scale))
 
 		r := e.RoundingNoise()
@@ -483,19 +561,56 @@ func (e Estimator) MulThenAdd(op0 *Element, op1 rlwe.Operand, op2 *Element) (err
 		return fmt.Errorf("invalid op1.(type): must be *Element, complex128, float64, int, int64, uint, uint64, *big.Int, *big.Float, *bignum.Complex, []complex128, []float64, []*big.Float or []*bignum.Complex, but is %T", op1)
 	}
 
-	return
+	if r2 == nil {
+		r0 = nil
+	}
+
+	e.Ranges = ranges
+	e.rangeBinary("mul_then_add", r0, op1, op2, func(r0, r1 Range) Range {
+		return r2.Add(r0.Mul(r1))
+	})
+
+	e.CheckOverflow("mul_then_add", op2)
+	e.countMul(op2.Level, op1)
+
+	return e.strictOutput("mul_then_add", "op2", op2)
 }
 
 func (e Estimator) ScaleUp(op0 *Element, scale rlwe.Scale) (err error) {
+
+	if err = e.strictOperands("scale_up", op0); err != nil {
+		return
+	}
+
+	if err = e.strictIntegerScale("scale_up", "scale", scale); err != nil {
+		return
+	}
+
+	// The scaling does not change the range.
+	e.Ranges = nil
 	if err = e.Mul(op0, scale.Uint64(), op0); err != nil {
 		return
 	}
 	op0.Scale = op0.Scale.Mul(scale)
-	return
+	return e.strictOutput("scale_up", "op0", op0)
 }
 
 func (e Estimator) SetScale(op0 *Element, scale rlwe.Scale) (err error) {
+
+	if err = e.strictOperands("set_scale", op0); err != nil {
+		return
+	}
+
+	// The scaling does not change the range, but the rescaling adds noise.
+	ranges := e.Ranges
+	e.Ranges = nil
+
 	ratioFlo := scale.Div(op0.Scale).Value
+
+	// A non-integer ratio is applied with a multiplication by round(ratio * Q[level]) and a rescaling.
+	if e.Strict && !ratioFlo.IsInt() && op0.Level == 0 {
+		return invariantError("set_scale", "op0", "the non-integer ratio %v of the scales requires a rescaling, but op0 is at level 0", &ratioFlo)
+	}
 	if err = e.Mul(op0, &ratioFlo, op0); err != nil {
 		return
 	}
@@ -507,17 +622,32 @@ func (e Estimator) SetScale(op0 *Element, scale rlwe.Scale) (err error) {
 	}
 
 	op0.Scale = scale
-	return
+
+	if e.Ranges = ranges; !ratioFlo.IsInt() {
+		e.addRangeNoise("set_scale", op0, e.roundingStd(op0.Degree))
+	}
+
+	return e.strictOutput("set_scale", "op0", op0)
 }
 
 func (e Estimator) KeySwitch(op0 *Element, sk []*bignum.Complex) (err error) {
+
+	if err = e.strictOperands("key_switch", op0, sk); err != nil {
+		return
+	}
+
 	if op0.Degree != 1 {
-		return fmt.Errorf("degree != 1")
+		return invariantError("key_switch", "op0", "degree %d != 1", op0.Degree)
 	}
 
+	e.count(OpKeySwitch, op0.Level)
+
 	e.AddKeySwitchingNoise(op0, sk)
 
-	return
+	e.addRangeNoise("key_switch", op0, e.keySwitchingStd(op0.Level))
+	e.CheckOverflow("key_switch", op0)
+
+	return e.strictOutput("key_switch", "op0", op0)
 }
 
 func (e Estimator) RotateNew(op0 *Element, k int) (op1 *Element, err error) {
@@ -527,10 +657,23 @@ func (e Estimator) RotateNew(op0 *Element, k int) (op1 *Element, err error) {
 
 func (e Estimator) Rotate(op0 *Element, k int, op1 *Element) (err error) {
 
+	if err = e.strictOperands("rotate", op0, op1); err != nil {
+		return
+	}
+
+	if op0 != op1 {
+		if err = e.strictRotation("rotate", k); err != nil {
+			return
+		}
+	}
+
 	if op0.Degree != 1 {
-		return fmt.Errorf("degree != 1")
+		return invariantError("rotate", "op0", "degree %d != 1", op0.Degree)
 	}
 
+	e.count(OpAutomorphism, op0.Level, e.Parameters.GaloisElement(k))
+	e.requireGalois(op0.Level, e.Parameters.GaloisElement(k))
+
 	if op0 == op1 {
 		// p.Value[1]: noise of the second component (s term)
 		// p.Sk[0]: sk^1
@@ -554,9 +697,13 @@ func (e Estimator) Rotate(op0 *Element, k int, op1 *Element) (err error) {
 
 		op1.Scale = op0.Scale
 		op1.Level = op0.Level
+		op1.Range = op0.Range
 	}
 
-	return
+	e.addRangeNoise("rotate", op1, e.keySwitchingStd(op1.Level))
+	e.CheckOverflow("rotate", op1)
+
+	return e.strictOutput("rotate", "op1", op1)
 }
 
 func (e Estimator) ConjugateNew(op0 *Element) (op1 *Element, err error) {
@@ -566,10 +713,21 @@ func (e Estimator) ConjugateNew(op0 *Element) (op1 *Element, err error) {
 
 func (e Estimator) Conjugate(op0, op1 *Element) (err error) {
 
+	if err = e.strictOperands("conjugate", op0, op1); err != nil {
+		return
+	}
+
+	if e.IsConjugateInvariant() {
+		return invariantError("conjugate", "op0", "not supported when parameters.RingType() == ring.ConjugateInvariant")
+	}
+
 	if op0.Degree != 1 {
-		return fmt.Errorf("degree != 1")
+		return invariantError("conjugate", "op0", "degree %d != 1", op0.Degree)
 	}
 
+	e.count(OpAutomorphism, op0.Level, e.Parameters.GaloisElementForComplexConjugation())
+	e.requireGalois(op0.Level, e.Parameters.GaloisElementForComplexConjugation())
+
 	if op0 == op1 {
 
 		// p.Value[1]: noise of the second component (s term)
@@ -604,7 +762,17 @@ func (e Estimator) Conjugate(op0, op1 *Element) (err error) {
 		}
 	}
 
-	return
+	if r0 := op0.Range; r0 != nil {
+		r := r0.Conjugate()
+		op1.Range = &r
+	} else {
+		op1.Range = nil
+	}
+
+	e.addRangeNoise("conjugate", op1, e.keySwitchingStd(op1.Level))
+	e.CheckOverflow("conjugate", op1)
+
+	return e.strictOutput("conjugate", "op1", op1)
 }
 
 func (e Estimator) RelinearizeNew(op0 *Element) (op1 *Element, err error) {
@@ -614,8 +782,12 @@ func (e Estimator) RelinearizeNew(op0 *Element) (op1 *Element, err error) {
 
 func (e Estimator) Relinearize(op0, op1 *Element) (err error) {
 
+	if err = e.strictOperands("relinearize", op0, op1); err != nil {
+		return
+	}
+
 	if op0.Degree != 2 {
-		return fmt.Errorf("degree != 2")
+		return invariantError("relinearize", "op0", "degree %d != 2", op0.Degree)
 	}
 
 	if op0 != op1 {
@@ -628,21 +800,36 @@ func (e Estimator) Relinearize(op0, op1 *Element) (err error) {
 		}
 	}
 
+	e.count(OpRelinearize, op0.Level)
+	e.requireRelinearization(op0.Level)
+
 	// p.Value[2]: noise of the third component (s^2 term)
 	// p.Sk[1]: Sk^2
 	e.AddRelinearizationNoise(op1)
 	op1.Degree = 1
-	return
+	op1.Range = op0.Range
+
+	e.addRangeNoise("relinearize", op1, e.keySwitchingStd(op1.Level))
+	e.CheckOverflow("relinearize", op1)
+
+	return e.strictOutput("relinearize", "op1", op1)
 }
 
 // RotateHoisted applies a rotation without ModDown.
 // Returned element is scaled by P.
 func (e Estimator) RotateHoistedNew(op0 *Element, k int) (value []*bignum.Complex, err error) {
 
+	if err = e.strictOperands("rotate_hoisted", op0); err != nil {
+		return
+	}
+
 	if op0.Degree != 1 {
-		return nil, fmt.Errorf("degree != 1")
+		return nil, invariantError("rotate_hoisted", "op0", "degree %d != 1", op0.Degree)
 	}
 
+	e.count(OpHoistedKeySwitch, op0.Level, e.Parameters.GaloisElement(k))
+	e.requireGalois(op0.Level, e.Parameters.GaloisElement(k))
+
 	// Scales first term by P
 	value = make([]*bignum.Complex, len(op0.Value[0]))
 
@@ -663,6 +850,8 @@ func (e Estimator) RotateHoistedNew(op0 *Element, k int) (value []*bignum.Comple
 		value[i].Add(value[i], e0[i])
 	}
 
+	e.checkOverflowP("rotate_hoisted", op0.Level, value)
+
 	utils.RotateSliceInPlace(value, k)
 
 	return value, nil
@@ -670,23 +859,39 @@ func (e Estimator) RotateHoistedNew(op0 *Element, k int) (value []*bignum.Comple
 
 // ModDown divides by P and adds rounding noise.
 func (e Estimator) ModDown(op0, op1 *Element) {
+	e.count(OpModDown, op0.Level)
 	e.DivideAndAddRoundingNoise(op0, e.P, op1)
+	op1.Range = op0.Range
+	e.addRangeNoise("mod_down", op1, e.roundingStd(op1.Degree))
+	e.CheckOverflow("mod_down", op1)
 }
 
 // Rescale divides by Q[level] and adds rounding noise.
 // Returns an error if already at level 0.
 func (e Estimator) Rescale(op0, op1 *Element) (err error) {
+
+	if err = e.strictOperands("rescale", op0, op1); err != nil {
+		return
+	}
+
 	if op0.Level == 0 {
-		return fmt.Errorf("element already at level 0")
+		return invariantError("rescale", "op0", "already at level 0")
 	}
 
+	e.count(OpRescale, op0.Level)
+
 	Q := e.Q[op0.Level]
 
 	e.DivideAndAddRoundingNoise(op0, &Q, op1)
 
 	op1.Scale = op0.Scale.Div(rlwe.NewScale(Q))
 	op1.Level = op0.Level - 1
-	return
+	op1.Range = op0.Range
+
+	e.addRangeNoise("rescale", op1, e.roundingStd(op1.Degree))
+	e.CheckOverflow("rescale", op1)
+
+	return e.strictOutput("rescale", "op1", op1)
 }
 
 // DivideAndRound by P and adds rounding noise
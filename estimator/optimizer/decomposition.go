@@ -0,0 +1,211 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Decomposition is a candidate auxiliary modulus P of the key-switching keys, which
+// determines the number of RNS digits of the key-switching (see estimator.DecompRNS).
+type Decomposition struct {
+	// LogP are the sizes of the primes of P.
+	LogP []int
+
+	// DNum is the number of RNS digits of a key-switching at the maximum level.
+	DNum int
+
+	// LogQP is the log2 of the modulus QP and Security the bit-security of the parameters.
+	LogQP, Security float64
+
+	// Log2RelinearizationNoise and Log2RotationNoise are the log2 of the standard deviation
+	// of the error added by the relinearization of the product of two fresh ciphertexts and
+	// by the key-switching of a fresh ciphertext, at the maximum level and before the
+	// division by the scale.
+	Log2RelinearizationNoise, Log2RotationNoise float64
+
+	// Precision is the predicted precision of the worst output of the circuit.
+	Precision float64
+
+	// KeySize is the size in bytes of one evaluation key.
+	KeySize int
+
+	// NTTs is the approximate number of NTTs of size N of a key-switching
+	// at the maximum level, which dominates its cost.
+	NTTs int
+
+	// Err is non-nil if the candidate could not be instantiated or evaluated.
+	Err error
+}
+
+func (d Decomposition) String() string {
+	if d.Err != nil {
+		return fmt.Sprintf("LogP=%v: %s", d.LogP, d.Err)
+	}
+	return fmt.Sprintf("LogP=%-16s dnum=%-3d log2(QP)=%7.2f security=%6.2f relin=%6.2f rot=%6.2f prec=%6.2f key=%6.2fMB NTTs=%d",
+		fmt.Sprint(d.LogP), d.DNum, d.LogQP, d.Security, d.Log2RelinearizationNoise, d.Log2RotationNoise, d.Precision, float64(d.KeySize)/(1<<20), d.NTTs)
+}
+
+// Decompositions evaluates the circuit with its modulus Q and each auxiliary modulus P made of
+// PCount primes of LogP bits (default: 1 to the number of primes of Q), and returns the candidates
+// and the index of the recommended one, or -1 if none is. The recommended candidate is the one
+// with the smallest key size, then the fewest NTTs, among the candidates with at least MinSecurity
+// bits of security whose precision is at least Target, or within half a bit of the best precision
+// if Target is 0. The other fields of Options, but Statistic, Security, Trials, Seed and Heuristic,
+// are ignored.
+func Decompositions(c circuit.Circuit, opts Options) (candidates []Decomposition, best int, err error) {
+
+	if c.Bootstrapping != nil {
+		return nil, -1, fmt.Errorf("circuits with bootstrapping are not supported")
+	}
+
+	if err = c.Validate(); err != nil {
+		return nil, -1, fmt.Errorf("invalid circuit: %w", err)
+	}
+
+	levels := len(c.Parameters.LogQ) + len(c.Parameters.Q)
+
+	if len(opts.PCount) == 0 {
+		for i := 1; i <= levels; i++ {
+			opts.PCount = append(opts.PCount, i)
+		}
+	}
+
+	opts = opts.withDefaults()
+
+	for _, logP := range opts.LogP {
+		for _, pCount := range opts.PCount {
+
+			lit := c.Parameters
+			lit.P = nil
+			lit.LogP = make([]int, pCount)
+			for i := range lit.LogP {
+				lit.LogP[i] = logP
+			}
+
+			candidates = append(candidates, opts.decomposition(c, lit))
+		}
+	}
+
+	target := opts.Target
+
+	if target == 0 {
+		target = math.Inf(-1)
+		for _, d := range candidates {
+			if d.Err == nil && d.Security >= opts.MinSecurity {
+				target = max(target, d.Precision-0.5)
+			}
+		}
+	}
+
+	best = -1
+
+	for i, d := range candidates {
+
+		if d.Err != nil || d.Security < opts.MinSecurity || d.Precision < target {
+			continue
+		}
+
+		if best == -1 || d.KeySize < candidates[best].KeySize || (d.KeySize == candidates[best].KeySize && d.NTTs < candidates[best].NTTs) {
+			best = i
+		}
+	}
+
+	return
+}
+
+// decomposition evaluates the candidate.
+func (opts Options) decomposition(c circuit.Circuit, lit ckks.ParametersLiteral) (d Decomposition) {
+
+	d.LogP = lit.LogP
+
+	params, err := ckks.NewParametersFromLiteral(lit)
+	if err != nil {
+		d.Err = fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+		return
+	}
+
+	levelQ, levelP := params.MaxLevelQ(), params.MaxLevelP()
+
+	d.DNum = estimator.DecompRNS(levelQ, levelP)
+	d.LogQP = params.LogQP()
+	d.Security = opts.Security(params)
+
+	// dnum x 2 polynomials of N coefficients of 8 bytes modulo QP.
+	d.KeySize = d.DNum * 2 * params.N() * (levelQ + levelP + 2) * 8
+
+	// ModUp of each digit, ModDown of the two outputs.
+	d.NTTs = d.DNum*(levelQ+levelP+2) + 2*(levelQ+levelP+2)
+
+	if d.Log2RelinearizationNoise, d.Log2RotationNoise, err = opts.keySwitchingNoise(params); err != nil {
+		d.Err = err
+		return
+	}
+
+	var r *Result
+	if r, err = opts.evaluate(c, lit, params); err != nil {
+		d.Err = err
+		return
+	}
+
+	d.Precision = r.Precision
+
+	return
+}
+
+// keySwitchingNoise returns the log2 of the standard deviation of the errors
+// added by a relinearization and by a key-switching (see Decomposition).
+func (opts Options) keySwitchingNoise(params ckks.Parameters) (relin, rot float64, err error) {
+
+	est := estimator.NewEstimatorFromSeed(params, opts.Seed)
+	est.Heuristic = opts.Heuristic
+	est.Rand = estimator.NewTestRand(opts.Seed).Rand
+
+	el := est.NewElement(make([]complex128, est.MaxSlots()), 1, params.MaxLevel(), params.DefaultScale())
+	est.AddEncryptionNoisePk(el)
+
+	ks := el.CopyNew()
+	if err = est.KeySwitch(ks, est.Sk[0]); err != nil {
+		return 0, 0, fmt.Errorf("est.KeySwitch: %w", err)
+	}
+
+	rot = log2Std(est.Decrypt(el), est.Decrypt(ks)) + el.Scale.Log2()
+
+	prod, err := est.MulNew(el, el)
+	if err != nil {
+		return 0, 0, fmt.Errorf("est.MulNew: %w", err)
+	}
+
+	before := est.Decrypt(prod)
+
+	if err = est.Relinearize(prod, prod); err != nil {
+		return 0, 0, fmt.Errorf("est.Relinearize: %w", err)
+	}
+
+	relin = log2Std(before, est.Decrypt(prod)) + prod.Scale.Log2()
+
+	return
+}
+
+// log2Std returns the log2 of the standard deviation of the
+// real and imaginary parts of the differences b - a.
+func log2Std(a, b []*bignum.Complex) float64 {
+
+	var sum float64
+
+	diff := bignum.NewComplex()
+
+	for i := range a {
+		diff.Sub(b[i], a[i])
+		re, _ := diff[0].Float64()
+		im, _ := diff[1].Float64()
+		sum += re*re + im*im
+	}
+
+	return 0.5 * math.Log2(sum/float64(2*len(a)))
+}
@@ -0,0 +1,82 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestDecompositions(t *testing.T) {
+
+	c := circuit.Circuit{
+		Name:       "mul_relin",
+		Parameters: ckks.ParametersLiteral{LogN: 13, LogQ: []int{50, 40, 40}, LogDefaultScale: 40},
+		Inputs:     []circuit.Input{{Name: "x"}, {Name: "y"}},
+		Operations: []circuit.Operation{
+			{Op: circuit.MulRelin, Inputs: []string{"x", "y"}, Output: "z"},
+			{Op: circuit.Rescale, Inputs: []string{"z"}},
+		},
+		Outputs: []string{"z"},
+	}
+
+	candidates, best, err := Decompositions(c, Options{LogP: []int{50}, Seed: 1, Heuristic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(candidates) != 3 {
+		t.Fatalf("len(candidates): %d != 3", len(candidates))
+	}
+
+	for i, d := range candidates {
+
+		if d.Err != nil {
+			t.Fatal(d.Err)
+		}
+
+		if len(d.LogP) != i+1 {
+			t.Fatalf("LogP=%v: %d primes != %d", d.LogP, len(d.LogP), i+1)
+		}
+
+		// ceil(3 / #P) digits
+		if want := (3 + i) / (i + 1); d.DNum != want {
+			t.Fatalf("LogP=%v: DNum=%d != %d", d.LogP, d.DNum, want)
+		}
+
+		// A larger P decreases the noise down to the rounding noise of the ModDown
+		if i != 0 && d.Log2RelinearizationNoise > candidates[i-1].Log2RelinearizationNoise+0.05 {
+			t.Errorf("LogP=%v: the relinearization noise %.2f increases", d.LogP, d.Log2RelinearizationNoise)
+		}
+	}
+
+	// Only a single prime of P gives 128-bit security
+	if best != 0 {
+		t.Fatalf("recommended candidate: %d != 0", best)
+	}
+
+	if candidates[0].Log2RelinearizationNoise < candidates[1].Log2RelinearizationNoise+1 {
+		t.Errorf("LogP=%v: the relinearization noise %.2f does not decrease", candidates[1].LogP, candidates[1].Log2RelinearizationNoise)
+	}
+
+	var maxPrec float64
+	for _, d := range candidates {
+		maxPrec = max(maxPrec, d.Precision)
+	}
+
+	if d := candidates[best]; d.Security < 128 || d.Precision < maxPrec-0.5 {
+		t.Fatalf("recommended candidate %s: insecure or imprecise", d)
+	}
+
+	for i, d := range candidates {
+		if i != best && d.Security >= 128 && d.Precision >= maxPrec-0.5 && d.KeySize < candidates[best].KeySize {
+			t.Fatalf("candidate %s has a smaller key than the recommended one", d)
+		}
+	}
+
+	// No candidate has 256-bit security
+	if _, best, err = Decompositions(c, Options{LogP: []int{50}, MinSecurity: 256, Seed: 1, Heuristic: true}); err != nil || best != -1 {
+		t.Fatalf("MinSecurity=256: best=%d, err=%v", best, err)
+	}
+}
@@ -0,0 +1,407 @@
+// Package optimizer searches the cheapest CKKS parameters evaluating a circuit
+// (see package circuit) with a target precision and a minimum security.
+//
+// The depth of the circuit is measured once with its own parameters and the modulus
+// chain of a candidate is [LogQ0, LogDefaultScale, ..., LogDefaultScale], where
+// LogQ0 = LogDefaultScale + Q0Margin, so that every rescaling lands on the default scale.
+// For each ring degree, in increasing order, and each Hamming weight and auxiliary
+// modulus P, the smallest default scale meeting the target is found by binary search,
+// as the predicted precision increases with the scale. The cheapest candidate is the
+// one with the smallest ring degree and then with the smallest log2(QP).
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+	"github.com/tuneinsight/ckks-noise-estimator/security"
+
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Options are the target and the search space of the optimizer.
+type Options struct {
+	// Target is the minimum predicted precision, in bits, of the Statistic
+	// (L2 norm) of every output of the circuit.
+	Target float64
+
+	// Statistic is the statistic compared with the target (default: estimator.AVGLog2Prec).
+	Statistic estimator.Statistic
+
+	// MinSecurity is the minimum bit-security of the parameters (default: 128).
+	MinSecurity float64
+
+	// Security returns the bit-security of the parameters (default: security.BKZSieve.Bits,
+	// which matches the HomomorphicEncryption.org standard for dense secrets and accounts
+	// for the attacks on sparse secrets). Standard uses the table of the standard instead.
+	Security func(params ckks.Parameters) float64
+
+	// LogN are the candidate ring degrees (default: 12 to 16).
+	LogN []int
+
+	// MinLogScale and MaxLogScale bound the default scale (default: 20 and 60).
+	MinLogScale, MaxLogScale int
+
+	// Q0Margin is the difference between the sizes of the first prime and of
+	// the default scale, which must exceed the log2 of the outputs (default: 10).
+	Q0Margin int
+
+	// LogP are the candidate sizes of the primes of P and PCount their candidate numbers
+	// (default: 61 and 1 to 3).
+	LogP   []int
+	PCount []int
+
+	// H are the candidate Hamming weights of the secret (default: 192 and dense).
+	// A weight of 0 samples the secret uniformly in {-1, 0, 1}.
+	H []int
+
+	// Trials, Seed and Heuristic are the options of the evaluations of the circuit
+	// with the estimator (see circuit.Options).
+	Trials    int
+	Seed      int64
+	Heuristic bool
+}
+
+// LevelPrecision is the worst predicted precision of the
+// values of a circuit at a level of the modulus chain.
+type LevelPrecision struct {
+	Level    int
+	LogQi    int
+	Log2Prec float64
+}
+
+// Result is the cheapest parameters found by the optimizer.
+type Result struct {
+	// Parameters are the parameters of the circuit.
+	Parameters ckks.ParametersLiteral
+
+	// LogQP is the log2 of the modulus QP.
+	LogQP float64
+
+	// Security is the bit-security of the parameters.
+	Security float64
+
+	// Precision is the predicted precision of the worst output.
+	Precision float64
+
+	// Outputs are the predicted statistics of the outputs.
+	Outputs []estimator.Result `json:",omitempty"`
+
+	// Levels are the predicted precisions per level.
+	Levels []LevelPrecision
+
+	// Evaluated is the number of candidates evaluated with the estimator.
+	Evaluated int
+}
+
+// maxLogQP are the largest log2(QP) of the HomomorphicEncryption.org standard for
+// uniform ternary secrets, indexed by the security level and log2(N).
+var maxLogQP = map[float64]map[int]float64{
+	128: {10: 27, 11: 54, 12: 109, 13: 218, 14: 438, 15: 881},
+	192: {10: 19, 11: 37, 12: 75, 13: 152, 14: 305, 15: 611},
+	256: {10: 14, 11: 29, 12: 58, 13: 118, 14: 237, 15: 476},
+}
+
+// MaxLogQP returns the largest log2(QP) of the HomomorphicEncryption.org standard for the
+// ring degree 2^logN, with 10 <= logN <= 15, and the security level 128, 192 or 256.
+// It returns 0 otherwise.
+func MaxLogQP(logN int, security float64) float64 {
+	return maxLogQP[security][logN]
+}
+
+// Standard returns the largest security level of the HomomorphicEncryption.org standard,
+// among 128, 192 and 256, whose bound MaxLogQP is met by the parameters, or 0 if none is.
+// It ignores the distribution of the secret. The standard has no bounds for the ring
+// degrees larger than 2^15, whose level is instead the largest one met by the estimate
+// security.BKZSieve of the security of the parameters.
+func Standard(params ckks.Parameters) float64 {
+
+	levels := []float64{256, 192, 128}
+
+	if MaxLogQP(params.LogN(), 128) == 0 {
+
+		bits := security.BKZSieve.Bits(params)
+
+		for _, level := range levels {
+			if bits >= level {
+				return level
+			}
+		}
+
+		return 0
+	}
+
+	for _, level := range levels {
+		if params.LogQP() <= MaxLogQP(params.LogN(), level) {
+			return level
+		}
+	}
+
+	return 0
+}
+
+// Optimize returns the cheapest parameters evaluating the circuit with the target precision
+// and the minimum security. The parameters of the circuit only give its depth. Circuits
+// with bootstrapping are not supported, as their parameters depend on the residual ones.
+func Optimize(c circuit.Circuit, opts Options) (res Result, err error) {
+
+	if c.Bootstrapping != nil {
+		return res, fmt.Errorf("circuits with bootstrapping are not supported")
+	}
+
+	if err = c.Validate(); err != nil {
+		return res, fmt.Errorf("invalid circuit: %w", err)
+	}
+
+	opts = opts.withDefaults()
+
+	depth, err := measureDepth(c, opts)
+	if err != nil {
+		return
+	}
+
+	var evaluated int
+
+	for _, logN := range opts.LogN {
+
+		var best *Result
+
+		for _, h := range opts.H {
+			for _, pCount := range opts.PCount {
+				for _, logP := range opts.LogP {
+
+					var r *Result
+					if r, err = opts.search(c, logN, h, pCount, logP, depth, &evaluated); err != nil {
+						return
+					}
+
+					if r != nil && (best == nil || r.LogQP < best.LogQP) {
+						best = r
+					}
+				}
+			}
+		}
+
+		if best != nil {
+			best.Evaluated = evaluated
+			return *best, nil
+		}
+	}
+
+	return res, fmt.Errorf("no parameters meet the target precision %.2f with %.0f-bit security (%d candidates evaluated)", opts.Target, opts.MinSecurity, evaluated)
+}
+
+func (opts Options) withDefaults() Options {
+
+	if opts.Statistic == "" {
+		opts.Statistic = estimator.AVGLog2Prec
+	}
+
+	if opts.MinSecurity == 0 {
+		opts.MinSecurity = 128
+	}
+
+	if opts.Security == nil {
+		opts.Security = security.BKZSieve.Bits
+	}
+
+	if len(opts.LogN) == 0 {
+		opts.LogN = []int{12, 13, 14, 15, 16}
+	}
+
+	sort.Ints(opts.LogN)
+
+	if opts.MinLogScale == 0 {
+		opts.MinLogScale = 20
+	}
+
+	if opts.MaxLogScale == 0 {
+		opts.MaxLogScale = 60
+	}
+
+	if opts.Q0Margin == 0 {
+		opts.Q0Margin = 10
+	}
+
+	if len(opts.LogP) == 0 {
+		opts.LogP = []int{61}
+	}
+
+	if len(opts.PCount) == 0 {
+		opts.PCount = []int{1, 2, 3}
+	}
+
+	if len(opts.H) == 0 {
+		opts.H = []int{192, 0}
+	}
+
+	return opts
+}
+
+// measureDepth returns the number of levels consumed by the circuit with its own parameters.
+func measureDepth(c circuit.Circuit, opts Options) (depth int, err error) {
+
+	params, err := ckks.NewParametersFromLiteral(c.Parameters)
+	if err != nil {
+		return 0, fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+	}
+
+	minLevel := params.MaxLevel()
+
+	if _, err = circuit.Run(c, circuit.Options{
+		Seed:      opts.Seed,
+		Heuristic: opts.Heuristic,
+		Trace: func(i int, op circuit.Operation, level int, want, predicted []*bignum.Complex) {
+			minLevel = min(minLevel, level)
+		},
+	}); err != nil {
+		return 0, fmt.Errorf("circuit.Run: %w", err)
+	}
+
+	return params.MaxLevel() - minLevel, nil
+}
+
+// literal returns the candidate parameters.
+func (opts Options) literal(logN, h, pCount, logP, logScale, depth int) ckks.ParametersLiteral {
+
+	logQ := make([]int, depth+1)
+	logQ[0] = min(logScale+opts.Q0Margin, 61)
+	for i := 1; i < len(logQ); i++ {
+		logQ[i] = logScale
+	}
+
+	lit := ckks.ParametersLiteral{
+		LogN:            logN,
+		LogQ:            logQ,
+		LogP:            make([]int, pCount),
+		LogDefaultScale: logScale,
+		Xs:              ring.Ternary{P: 2.0 / 3},
+	}
+
+	for i := range lit.LogP {
+		lit.LogP[i] = logP
+	}
+
+	if h != 0 {
+		lit.Xs = ring.Ternary{H: h}
+	}
+
+	return lit
+}
+
+// secure returns the security of the parameters and true if it is at least MinSecurity.
+func (opts Options) secure(params ckks.Parameters) (float64, bool) {
+	bits := opts.Security(params)
+	return bits, bits >= opts.MinSecurity
+}
+
+// search returns the candidate with the smallest default scale meeting the target
+// for the given ring degree, Hamming weight and modulus P, or nil if there is none.
+func (opts Options) search(c circuit.Circuit, logN, h, pCount, logP, depth int, evaluated *int) (best *Result, err error) {
+
+	lo, hi := opts.MinLogScale, opts.MaxLogScale
+
+	for lo <= hi {
+
+		logScale := (lo + hi) / 2
+
+		lit := opts.literal(logN, h, pCount, logP, logScale, depth)
+
+		params, perr := ckks.NewParametersFromLiteral(lit)
+		if perr != nil {
+			// Not enough NTT-friendly primes of this size: larger scales may have some.
+			lo = logScale + 1
+			continue
+		}
+
+		security, ok := opts.secure(params)
+		if !ok {
+			// Larger scales are even less secure.
+			hi = logScale - 1
+			continue
+		}
+
+		var r *Result
+		if r, err = opts.evaluate(c, lit, params); err != nil {
+			return nil, err
+		}
+
+		*evaluated++
+
+		if r.Precision >= opts.Target {
+			r.Security = security
+			best = r
+			hi = logScale - 1
+		} else {
+			lo = logScale + 1
+		}
+	}
+
+	return
+}
+
+// evaluate returns the predicted precision of the circuit with the given parameters.
+func (opts Options) evaluate(c circuit.Circuit, lit ckks.ParametersLiteral, params ckks.Parameters) (r *Result, err error) {
+
+	c.Parameters = lit
+
+	ecd := ckks.NewEncoder(params)
+
+	levels := map[int]float64{}
+
+	outputs, err := circuit.Run(c, circuit.Options{
+		Trials:    opts.Trials,
+		Seed:      opts.Seed,
+		Heuristic: opts.Heuristic,
+		Trace: func(i int, op circuit.Operation, level int, want, predicted []*bignum.Complex) {
+
+			stats := estimator.NewStats()
+			stats.Add(ckks.GetPrecisionStats(params, ecd, nil, want, predicted, 0, false))
+			stats.Finalize()
+
+			prec, _ := stats.Get(opts.Statistic)
+
+			if p, ok := levels[level]; !ok || prec.L2 < p {
+				levels[level] = prec.L2
+			}
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("circuit.Run: %w", err)
+	}
+
+	r = &Result{
+		Parameters: lit,
+		LogQP:      params.LogQP(),
+		Precision:  math.Inf(1),
+		Outputs:    outputs,
+	}
+
+	for _, out := range outputs {
+
+		var prec ckks.Stats
+		if prec, err = out.Predicted.Get(opts.Statistic); err != nil {
+			return nil, fmt.Errorf("Get: %w", err)
+		}
+
+		r.Precision = min(r.Precision, prec.L2)
+	}
+
+	for level := params.MaxLevel(); level >= 0; level-- {
+		if prec, ok := levels[level]; ok {
+			r.Levels = append(r.Levels, LevelPrecision{
+				Level:    level,
+				LogQi:    lit.LogQ[level],
+				Log2Prec: prec,
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,154 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	"github.com/tuneinsight/ckks-noise-estimator/circuit"
+	"github.com/tuneinsight/ckks-noise-estimator/security"
+
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// testCircuit returns a circuit of depth 2 whose parameters only give the depth.
+func testCircuit() circuit.Circuit {
+	return circuit.Circuit{
+		Name:       "mul_square",
+		Parameters: ckks.ParametersLiteral{LogN: 12, LogQ: []int{50, 40, 40}, LogP: []int{61}, LogDefaultScale: 40},
+		Inputs:     []circuit.Input{{Name: "x"}, {Name: "y"}},
+		Operations: []circuit.Operation{
+			{Op: circuit.MulRelin, Inputs: []string{"x", "y"}, Output: "z"},
+			{Op: circuit.Rescale, Inputs: []string{"z"}},
+			{Op: circuit.MulRelin, Inputs: []string{"z", "z"}},
+			{Op: circuit.Rescale, Inputs: []string{"z"}},
+		},
+		Outputs: []string{"z"},
+	}
+}
+
+func TestOptimize(t *testing.T) {
+
+	target := 20.0
+
+	res, err := Optimize(testCircuit(), Options{
+		Target:    target,
+		LogN:      []int{12, 13},
+		PCount:    []int{1},
+		H:         []int{0},
+		Seed:      1,
+		Heuristic: true,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := ckks.NewParametersFromLiteral(res.Parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Parameters.LogQ) != 3 {
+		t.Fatalf("LogQ=%v: %d levels != 2", res.Parameters.LogQ, len(res.Parameters.LogQ)-1)
+	}
+
+	if bits := security.BKZSieve.Bits(params); bits < 128 || res.Security != bits {
+		t.Fatalf("security: %.2f (Result.Security=%.2f) < 128", bits, res.Security)
+	}
+
+	if res.Precision < target {
+		t.Fatalf("predicted precision: %.2f < %.2f", res.Precision, target)
+	}
+
+	// The parameters meet the target with Lattigo
+	c := testCircuit()
+	c.Parameters = res.Parameters
+
+	outputs, err := circuit.Run(c, circuit.Options{Seed: 2, Lattigo: true, Heuristic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prec, _ := outputs[0].Actual.Get(estimator.AVGLog2Prec); prec.L2 < target-0.5 {
+		t.Fatalf("actual precision: %.2f < %.2f", prec.L2, target-0.5)
+	}
+
+	// The scale is the smallest one meeting the target
+	lit := res.Parameters
+	lit.LogDefaultScale--
+	for i := range lit.LogQ[1:] {
+		lit.LogQ[i+1]--
+	}
+
+	if params, err = ckks.NewParametersFromLiteral(lit); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Target: target, Seed: 1, Heuristic: true}.withDefaults()
+
+	r, err := opts.evaluate(testCircuit(), lit, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Precision >= target {
+		t.Fatalf("LogDefaultScale=%d also meets the target: %.2f >= %.2f", lit.LogDefaultScale, r.Precision, target)
+	}
+}
+
+func TestOptimizeInsecure(t *testing.T) {
+
+	// The modulus of the precision does not fit in N=2^12 with 256-bit security
+	if _, err := Optimize(testCircuit(), Options{
+		Target:      40,
+		MinSecurity: 256,
+		LogN:        []int{12},
+		H:           []int{0},
+		Seed:        1,
+		Heuristic:   true,
+	}); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestStandard(t *testing.T) {
+
+	sixties := func(n int) (logQ []int) {
+		for i := 0; i < n; i++ {
+			logQ = append(logQ, 60)
+		}
+		return
+	}
+
+	for _, tc := range []struct {
+		logN       int
+		logQ, logP []int
+		level      float64
+	}{
+		{12, []int{30, 30}, []int{40}, 128},
+		{12, []int{20, 20}, []int{30}, 192},
+		{12, []int{50, 50}, []int{61}, 0},
+		{15, sixties(13), []int{40}, 128},
+		// Beyond the table of the standard: security.BKZSieve
+		{16, sixties(24), []int{61}, 128},
+		{16, sixties(30), []int{61}, 0},
+	} {
+
+		params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+			LogN:            tc.logN,
+			LogQ:            tc.logQ,
+			LogP:            tc.logP,
+			Xs:              ring.Ternary{P: 2.0 / 3},
+			LogDefaultScale: 30,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if level := Standard(params); level != tc.level {
+			t.Errorf("LogN=%d, log2(QP)=%.0f: %.0f != %.0f", tc.logN, params.LogQP(), level, tc.level)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Overflow is a wraparound of the modulus by the phase of an element.
+type Overflow struct {
+	// Op is the operation whose output overflows.
+	Op string
+	// Level is the level of the output.
+	Level int
+	// Coeffs is the number of coefficients of the phase larger than half the modulus.
+	Coeffs int
+	// Log2Max is the log2 of the largest coefficient of the phase.
+	Log2Max float64
+	// Log2Modulus is the log2 of the modulus, Q_level or Q_level * P.
+	Log2Modulus float64
+}
+
+func (o Overflow) String() string {
+	return fmt.Sprintf("%s: %d coefficients of the phase at level %d, of magnitude up to 2^%.2f, overflow the modulus/2 = 2^%.2f", o.Op, o.Coeffs, o.Level, o.Log2Max, o.Log2Modulus-1)
+}
+
+// OverflowCheck checks, after each operation of the estimator, that the coefficients of the
+// phase <(el0, el1, el2), (1, sk, sk^2)> of its output are smaller than half the modulus at
+// its level, Q_level/2, or Q_level*P/2 for the values scaled by P of the hoisted rotations and
+// of the linear transformations. A real ciphertext whose phase is larger wraps around: it
+// decrypts to the phase reduced modulo Q_level, which is unrelated to the message, whereas
+// the values of the estimator are never reduced.
+//
+// As the arithmetic of a ciphertext is modulo Q_level, an overflow of an intermediate value
+// which is cancelled before the next rescaling or decryption, e.g. by a subtraction, is
+// harmless, but it is recorded too.
+//
+// The check maps the phase to its coefficients at each operation, which
+// costs about as much as the operation.
+type OverflowCheck struct {
+	// Reduce, if true, simulates the wraparound: the coefficients of the phase are
+	// reduced modulo the modulus, so that the element decrypts as the real ciphertext.
+	// The reduction is at the precision of the estimator, so a reduced element only
+	// approximates the real one, but both decrypt to values unrelated to the message.
+	// Otherwise, the overflows are only recorded.
+	Reduce bool
+
+	mu        sync.Mutex
+	overflows []Overflow
+}
+
+// NewOverflowCheck returns a new OverflowCheck, which simulates the wraparound if reduce is true.
+func NewOverflowCheck(reduce bool) *OverflowCheck {
+	return &OverflowCheck{Reduce: reduce}
+}
+
+// Overflows returns the overflows recorded so far.
+func (c *OverflowCheck) Overflows() []Overflow {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Overflow{}, c.overflows...)
+}
+
+// Err returns an error describing the first overflow, or nil if there was none.
+func (c *OverflowCheck) Err() error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.overflows) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d overflows of the modulus, the first: %s", len(c.overflows), c.overflows[0])
+}
+
+func (c *OverflowCheck) record(o Overflow) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overflows = append(c.overflows, o)
+}
+
+// CheckOverflow checks the phase of el against the modulus at its level
+// (see OverflowCheck). It does nothing if e.Overflow is nil.
+func (e Estimator) CheckOverflow(op string, el *Element) {
+	if e.Overflow != nil {
+		e.checkOverflow(op, el, e.modulus(el.Level))
+	}
+}
+
+// checkOverflow checks the phase of el against the modulus Q.
+func (e Estimator) checkOverflow(op string, el *Element, Q *big.Float) {
+
+	if e.Overflow == nil {
+		return
+	}
+
+	if delta := e.reduceModulus(op, el.Level, e.phase(el), Q); delta != nil {
+		m0 := el.Value[0]
+		for i := range m0 {
+			m0[i].Add(m0[i], delta[i])
+		}
+	}
+}
+
+// checkOverflowP checks values, the phase of an element at the given
+// level scaled by P, against the modulus Q_level * P.
+func (e Estimator) checkOverflowP(op string, level int, values []*bignum.Complex) {
+
+	if e.Overflow == nil {
+		return
+	}
+
+	if delta := e.reduceModulus(op, level, values, e.modulusP(level)); delta != nil {
+		for i := range values {
+			values[i].Add(values[i], delta[i])
+		}
+	}
+}
+
+// reduceModulus records an overflow if the coefficients of phase are larger than Q/2, and returns
+// the canonical embedding of the multiple of Q that reduces them if e.Overflow.Reduce is true.
+// It returns nil otherwise.
+func (e Estimator) reduceModulus(op string, level int, phase []*bignum.Complex, Q *big.Float) (delta []*bignum.Complex) {
+
+	coeffs := e.CanonicalToRing(phase)
+
+	half := new(big.Float).Quo(Q, NewFloat(2))
+
+	o := Overflow{Op: op, Level: level, Log2Max: math.Inf(-1)}
+	o.Log2Modulus = bigLog2(Q)
+
+	abs := new(big.Float)
+	for i, c := range coeffs {
+
+		if abs.Abs(c).Cmp(half) <= 0 {
+			coeffs[i] = new(big.Float)
+			continue
+		}
+
+		o.Coeffs++
+		o.Log2Max = math.Max(o.Log2Max, bigLog2(abs))
+
+		// c - Q * round(c/Q) - c
+		k := new(big.Float).SetPrec(prec).Quo(c, Q)
+		Round(k)
+		coeffs[i] = k.Mul(k, Q).Neg(k)
+	}
+
+	if o.Coeffs == 0 {
+		return nil
+	}
+
+	e.Overflow.record(o)
+
+	if !e.Overflow.Reduce {
+		return nil
+	}
+
+	return e.RingToCanonical(coeffs)
+}
+
+// modulus returns a new big.Float equal to the modulus at the given level.
+func (e Estimator) modulus(level int) (Q *big.Float) {
+	Q = NewFloat(1)
+	for i := 0; i <= level; i++ {
+		Q.Mul(Q, &e.Q[i])
+	}
+	return
+}
+
+// modulusP returns a new big.Float equal to the modulus at the given level times P.
+func (e Estimator) modulusP(level int) (QP *big.Float) {
+	QP = e.modulus(level)
+	return QP.Mul(QP, e.P)
+}
+
+// bigLog2 returns the log2 of |x|.
+func bigLog2(x *big.Float) float64 {
+	mant := new(big.Float)
+	exp := x.MantExp(mant)
+	m, _ := mant.Float64()
+	return math.Log2(math.Abs(m)) + float64(exp)
+}
@@ -0,0 +1,78 @@
+package estimator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestOverflowCheck(t *testing.T) {
+
+	params := testParameters(t)
+
+	ecd := ckks.NewEncoder(params)
+	source := NewTestRand(1)
+
+	// |m| * 2^45 up to 2^58 > q0/2 = 2^54 at level 0
+	values := make([]complex128, params.MaxSlots())
+	for i := range values {
+		values[i] = complex(source.Float64(-0x1p13, 0x1p13), source.Float64(-0x1p13, 0x1p13))
+	}
+
+	// The encoding of Lattigo at level 0 reduces the coefficients modulo q0.
+	pt := ckks.NewPlaintext(params, 0)
+	if err := ecd.Encode(values, pt); err != nil {
+		t.Fatal(err)
+	}
+
+	want := make([]complex128, params.MaxSlots())
+	if err := ecd.Decode(pt, want); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, reduce := range []bool{true, false} {
+
+		est := NewEstimatorFromSeed(params, 1)
+		est.Overflow = NewOverflowCheck(reduce)
+
+		el := est.NewElement(values, 1, 0, pt.Scale)
+		est.CheckOverflow("test", el)
+
+		overflows := est.Overflow.Overflows()
+
+		if len(overflows) != 1 || overflows[0].Op != "test" || overflows[0].Level != 0 || overflows[0].Coeffs == 0 {
+			t.Fatalf("reduce=%t: Overflows: %v", reduce, overflows)
+		}
+
+		if est.Overflow.Err() == nil {
+			t.Fatalf("reduce=%t: no error", reduce)
+		}
+
+		have := est.Decrypt(el)
+
+		for i := range have {
+
+			// The reduced element decrypts to the wrapped values, up to the rounding of the encoding,
+			// and the element which is not reduced still decrypts to the values.
+			w := want[i]
+			if !reduce {
+				w = values[i]
+			}
+
+			if h := have[i].Complex128(); math.Abs(real(h)-real(w)) > 1e-9 || math.Abs(imag(h)-imag(w)) > 1e-9 {
+				t.Fatalf("reduce=%t, slot %d: %v != %v", reduce, i, h, w)
+			}
+		}
+	}
+
+	// The values fit at the maximum level.
+	est := NewEstimatorFromSeed(params, 1)
+	est.Overflow = NewOverflowCheck(true)
+
+	est.CheckOverflow("test", est.NewElement(values, 1, params.MaxLevel(), pt.Scale))
+
+	if err := est.Overflow.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -13,6 +13,14 @@ import (
 
 func (e Estimator) EvaluatePolynomialNew(elIn *Element, poly interface{}, targetScale rlwe.Scale) (elOut *Element, err error) {
 
+	if err = e.strictOperands("polynomial", elIn); err != nil {
+		return
+	}
+
+	// The range of the output is set from the polynomial rather than from its evaluation.
+	rIn, ranges := elIn.Range, e.Ranges
+	e.Ranges = nil
+
 	var polyVec polynomial.PolynomialVector
 	switch poly := poly.(type) {
 	case ckkspoly.Polynomial:
@@ -39,18 +47,29 @@ func (e Estimator) EvaluatePolynomialNew(elIn *Element, poly interface{}, target
 		odd, even = odd || p.IsOdd, even || p.IsEven
 	}
 
-	// Computes all the powers of two with relinearization
-	// This will recursively compute and store all powers of two up to 2^logDegree
-	if err = powerbasis.GenPower(1<<(logDegree-1), false, e); err != nil {
+	// The powers of two, then the intermediate powers, starting from the largest
+	powers := []int{1 << (logDegree - 1)}
+	for i := (1 << logSplit) - 1; i > 2; i-- {
+		if !(even || odd) || (i&1 == 0 && even) || (i&1 == 1 && odd) {
+			powers = append(powers, i)
+		}
+	}
+
+	if err = e.Checkpoint("power_basis", 0, len(powers)); err != nil {
 		return nil, err
 	}
 
-	// Computes the intermediate powers, starting from the largest, without relinearization if possible
-	for i := (1 << logSplit) - 1; i > 2; i-- {
-		if !(even || odd) || (i&1 == 0 && even) || (i&1 == 1 && odd) {
-			if err = powerbasis.GenPower(i, polyVec.Value[0].Lazy, e); err != nil {
-				return nil, err
-			}
+	for i, p := range powers {
+
+		// Computes all the powers of two with relinearization
+		// This will recursively compute and store all powers of two up to 2^logDegree
+		// and the intermediate powers without relinearization if possible
+		if err = powerbasis.GenPower(p, i != 0 && polyVec.Value[0].Lazy, e); err != nil {
+			return nil, err
+		}
+
+		if err = e.Checkpoint("power_basis", i+1, len(powers)); err != nil {
+			return nil, err
 		}
 	}
 
@@ -65,7 +84,10 @@ func (e Estimator) EvaluatePolynomialNew(elIn *Element, poly interface{}, target
 	powerbasis = nil
 	runtime.GC()
 
-	return elOut, err
+	e.Ranges = ranges
+	e.polynomialRange(rIn, polyVec.Value, elOut)
+
+	return elOut, e.strictOutput("polynomial", "elOut", elOut)
 }
 
 // BabyStep is a struct storing the result of a baby-step
@@ -82,6 +104,10 @@ func EvaluatePatersonStockmeyerPolynomialVector[T uint64 | *bignum.Complex](poly
 
 	babySteps := make([]*BabyStep, split)
 
+	if err = e.Checkpoint("baby_steps", 0, split); err != nil {
+		return nil, err
+	}
+
 	// Small steps
 	for i := range babySteps {
 
@@ -89,6 +115,10 @@ func EvaluatePatersonStockmeyerPolynomialVector[T uint64 | *bignum.Complex](poly
 		if babySteps[split-i-1], err = EvaluateBabyStep[T](i, poly, cg, pb, e); err != nil {
 			return nil, fmt.Errorf("cannot EvaluateBabyStep: %w", err)
 		}
+
+		if err = e.Checkpoint("baby_steps", i+1, split); err != nil {
+			return nil, err
+		}
 	}
 
 	// Loops as long as there is more than one sub-polynomial
@@ -123,6 +153,11 @@ func EvaluatePatersonStockmeyerPolynomialVector[T uint64 | *bignum.Complex](poly
 		}
 
 		babySteps = babySteps[:idx]
+
+		// Each giant step combines two sub-polynomials into one
+		if err = e.Checkpoint("giant_steps", split-len(babySteps), split-1); err != nil {
+			return nil, err
+		}
 	}
 
 	if babySteps[0].Value.Degree == 2 {
@@ -0,0 +1,475 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/common/polynomial"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Interval is the closed interval [Min, Max].
+type Interval struct {
+	Min, Max float64
+}
+
+// NewInterval returns the smallest interval containing the values.
+func NewInterval(values ...float64) (i Interval) {
+	i = Interval{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, v := range values {
+		i.Min = math.Min(i.Min, v)
+		i.Max = math.Max(i.Max, v)
+	}
+	return
+}
+
+func (i Interval) Add(j Interval) Interval {
+	return Interval{Min: i.Min + j.Min, Max: i.Max + j.Max}
+}
+
+func (i Interval) Sub(j Interval) Interval {
+	return Interval{Min: i.Min - j.Max, Max: i.Max - j.Min}
+}
+
+func (i Interval) Mul(j Interval) Interval {
+	return NewInterval(i.Min*j.Min, i.Min*j.Max, i.Max*j.Min, i.Max*j.Max)
+}
+
+func (i Interval) Neg() Interval {
+	return Interval{Min: -i.Max, Max: -i.Min}
+}
+
+// Widen returns [Min - r, Max + r].
+func (i Interval) Widen(r float64) Interval {
+	return Interval{Min: i.Min - r, Max: i.Max + r}
+}
+
+// Abs returns the largest absolute value of the interval.
+func (i Interval) Abs() float64 {
+	return math.Max(math.Abs(i.Min), math.Abs(i.Max))
+}
+
+// Contains returns true if j is included in i.
+func (i Interval) Contains(j Interval) bool {
+	return i.Min <= j.Min && j.Max <= i.Max
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("[%.6g, %.6g]", i.Min, i.Max)
+}
+
+// Range is the range of the slots of an element: bounds on the real and imaginary parts of
+// their messages, which are certified by interval arithmetic, and a bound on the magnitude of
+// their errors, which holds with high probability (see RangeAnalysis).
+type Range struct {
+	Real, Imag Interval
+	Noise      float64
+}
+
+// disk returns the range of the messages of magnitude at most m.
+func disk(m, noise float64) Range {
+	return Range{Real: Interval{Min: -m, Max: m}, Imag: Interval{Min: -m, Max: m}, Noise: noise}
+}
+
+// Bounds returns the bounds on the real and imaginary parts of the slots, errors included.
+func (r Range) Bounds() (real, imag Interval) {
+	return r.Real.Widen(r.Noise), r.Imag.Widen(r.Noise)
+}
+
+// Magnitude returns the bound on the magnitude of the messages.
+func (r Range) Magnitude() float64 {
+	return math.Hypot(r.Real.Abs(), r.Imag.Abs())
+}
+
+func (r Range) Add(s Range) Range {
+	return Range{Real: r.Real.Add(s.Real), Imag: r.Imag.Add(s.Imag), Noise: r.Noise + s.Noise}
+}
+
+func (r Range) Sub(s Range) Range {
+	return Range{Real: r.Real.Sub(s.Real), Imag: r.Imag.Sub(s.Imag), Noise: r.Noise + s.Noise}
+}
+
+// Mul returns the range of the products, whose error is (m0 + e0)(m1 + e1) - m0m1.
+func (r Range) Mul(s Range) Range {
+	return Range{
+		Real:  r.Real.Mul(s.Real).Sub(r.Imag.Mul(s.Imag)),
+		Imag:  r.Real.Mul(s.Imag).Add(r.Imag.Mul(s.Real)),
+		Noise: r.Magnitude()*s.Noise + s.Magnitude()*r.Noise + r.Noise*s.Noise,
+	}
+}
+
+func (r Range) Conjugate() Range {
+	r.Imag = r.Imag.Neg()
+	return r
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("real=%v imag=%v noise=%.3g", r.Real, r.Imag, r.Noise)
+}
+
+// RangeAnalysis propagates the ranges of the elements of an Estimator through its operations
+// and records a warning when a value may overflow the modulus at its level, i.e. exceed
+// Q/(2*scale), or leave the domain of a polynomial (e.g. the interval [-K, K] of EvaluateMod1New).
+//
+// The range of an element is set with Estimator.SetRange and is propagated by Add, Sub, Mul,
+// MulThenAdd, Rescale, Rotate, Conjugate, Relinearize, linear transformations and polynomial
+// evaluations, and is removed (set to nil) by an operation with an operand whose range is unknown.
+// The message bounds of polynomials and linear transformations are bounds on the magnitude:
+// sum |c_i| |T_i(x)| and sum max |diagonal| |x|. The error bounds add the propagated errors
+// of the operands and Tail standard deviations of the noise of each operation.
+//
+// The domains of the polynomials are checked against the bounds on the messages, as an
+// excursion of the order of the error outside the domain does not affect the approximation.
+//
+// The outputs of a bootstrapping (see package bootstrapping) have no range, but its
+// EvalMod step checks that the coefficients of the message plus q0 * I stay in [-K, K].
+type RangeAnalysis struct {
+	// Tail is the number of standard deviations of the noise of an operation in
+	// the bounds on the errors (default: DefaultTail, 8, which is exceeded by a
+	// slot with a probability of about 2^-49).
+	Tail float64
+
+	mu       sync.Mutex
+	warnings []string
+	seen     map[string]bool
+}
+
+// DefaultTail is the default RangeAnalysis.Tail.
+const DefaultTail = 8
+
+// NewRangeAnalysis returns a new RangeAnalysis with the default Tail.
+func NewRangeAnalysis() *RangeAnalysis {
+	return &RangeAnalysis{Tail: DefaultTail}
+}
+
+// Warnings returns the warnings recorded so far, without duplicates.
+func (a *RangeAnalysis) Warnings() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string{}, a.warnings...)
+}
+
+func (a *RangeAnalysis) warn(format string, args ...any) {
+
+	msg := fmt.Sprintf(format, args...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.seen == nil {
+		a.seen = map[string]bool{}
+	}
+
+	if !a.seen[msg] {
+		a.seen[msg] = true
+		a.warnings = append(a.warnings, msg)
+	}
+}
+
+// tail returns the Tail of the range analysis, or its default.
+func (e Estimator) tail() float64 {
+	if e.Ranges != nil && e.Ranges.Tail != 0 {
+		return e.Ranges.Tail
+	}
+	return DefaultTail
+}
+
+// SetRange sets the range of the messages of a fresh element, whose error is
+// bounded by the encoding and public-key encryption noise.
+func (e Estimator) SetRange(el *Element, real, imag Interval) {
+	std := math.Hypot(e.roundingStd(0), e.roundingStd(1))
+	e.setRange("set_range", el, &Range{Real: real, Imag: imag, Noise: e.tail() * std / el.Scale.Float64()})
+}
+
+// roundingStd returns the standard deviation of the real part of the slots of the rounding noise
+// of an element of the given degree, (e0, e1, e2) with e0 + e1 * sk + e2 * sk^2 in the ring.
+func (e Estimator) roundingStd(degree int) float64 {
+
+	h := float64(e.H)
+
+	v := 1.0
+	if degree >= 1 {
+		v += h
+	}
+	if degree >= 2 {
+		v += h * h
+	}
+
+	return e.CanonicalExpansion() * math.Sqrt(v/12)
+}
+
+// keySwitchingStd returns the standard deviation of the real part of the slots
+// of the noise of a key-switching at the given level (see KeySwitchingNoise).
+func (e Estimator) keySwitchingStd(level int) float64 {
+
+	sum := new(big.Float)
+	for _, qalpha := range e.Decomposition(level, e.LevelP) {
+		sum.Add(sum, new(big.Float).Mul(qalpha, qalpha))
+	}
+
+	sum.Quo(sum, new(big.Float).Mul(e.P, e.P))
+
+	v, _ := sum.Float64()
+	v *= e.Sigma * e.Sigma * float64(2*e.MaxSlots()) / 12
+
+	return math.Hypot(e.CanonicalExpansion()*math.Sqrt(v), e.roundingStd(1))
+}
+
+// log2Modulus returns the log2 of the modulus at the given level.
+func (e Estimator) log2Modulus(level int) (logQ float64) {
+	for i := 0; i <= level; i++ {
+		qi, _ := e.Q[i].Float64()
+		logQ += math.Log2(qi)
+	}
+	return
+}
+
+// setRange sets the range of el, and records a warning if its
+// values may overflow the modulus at its level.
+func (e Estimator) setRange(op string, el *Element, r *Range) {
+
+	el.Range = r
+
+	if e.Ranges == nil || r == nil {
+		return
+	}
+
+	real, imag := r.Bounds()
+
+	logQ := e.log2Modulus(el.Level)
+
+	if logMax := math.Log2(math.Hypot(real.Abs(), imag.Abs())) + el.Scale.Log2(); logMax+1 >= logQ {
+		e.Ranges.warn("%s: the values at level %d, of range %v, may overflow Q/(2*scale) = 2^%.2f", op, el.Level, r, logQ-1-el.Scale.Log2())
+	}
+}
+
+// addRangeNoise adds Tail standard deviations of a noise of standard
+// deviation std, before the division by the scale, to the range of el.
+func (e Estimator) addRangeNoise(op string, el *Element, std float64) {
+
+	if e.Ranges == nil || el.Range == nil {
+		return
+	}
+
+	r := *el.Range
+	r.Noise += e.tail() * std / el.Scale.Float64()
+
+	e.setRange(op, el, &r)
+}
+
+// ReplaceScale replaces the scale of el by scale without changing its values,
+// which divides its messages by scale/el.Scale, and updates its range.
+func (e Estimator) ReplaceScale(el *Element, scale rlwe.Scale) {
+
+	f := el.Scale.Div(scale).Float64()
+
+	el.Scale = scale
+
+	if e.Ranges != nil && el.Range != nil {
+		r := el.Range.Mul(Range{Real: Interval{Min: f, Max: f}})
+		e.setRange("scale", el, &r)
+	}
+}
+
+// operandRange returns the range of an element or of constants.
+func operandRange(op rlwe.Operand) (r Range, ok bool) {
+
+	switch op := op.(type) {
+	case *Element:
+		if op.Range == nil {
+			return r, false
+		}
+		return *op.Range, true
+
+	case complex128, float64, int, int64, uint, uint64, *big.Int, *big.Float, *bignum.Complex:
+		c := bignum.ToComplex(op, prec)
+		re, _ := c[0].Float64()
+		im, _ := c[1].Float64()
+		return Range{Real: Interval{Min: re, Max: re}, Imag: Interval{Min: im, Max: im}}, true
+
+	case []*bignum.Complex:
+
+		var re, im []float64
+		for _, c := range op {
+			if c != nil {
+				x, _ := c[0].Float64()
+				y, _ := c[1].Float64()
+				re, im = append(re, x), append(im, y)
+			}
+		}
+
+		return Range{Real: NewInterval(re...), Imag: NewInterval(im...)}, len(re) != 0
+	}
+
+	return r, false
+}
+
+// rangeBinary sets the range of op2 to f applied to the range r0
+// of the first operand and to the range of op1, if both are known.
+func (e Estimator) rangeBinary(op string, r0 *Range, op1 rlwe.Operand, op2 *Element, f func(r0, r1 Range) Range) {
+
+	if e.Ranges == nil {
+		return
+	}
+
+	r1, ok := operandRange(op1)
+	if r0 == nil || !ok {
+		op2.Range = nil
+		return
+	}
+
+	r := f(*r0, r1)
+
+	e.setRange(op, op2, &r)
+}
+
+// polynomialRange sets the range of the evaluation elOut of the polynomials on an input of range rIn, and records a
+// warning if the messages of the input may leave their domain, which is [-1, 1] after the change of basis for the
+// Chebyshev basis. The error is the input error times the Lipschitz constant of the polynomials
+// plus an approximation of the noise of the evaluation.
+func (e Estimator) polynomialRange(rIn *Range, polys []polynomial.Polynomial, elOut *Element) {
+
+	if e.Ranges == nil {
+		return
+	}
+
+	if rIn == nil {
+		elOut.Range = nil
+		return
+	}
+
+	in := *rIn
+	real, imag := in.Bounds()
+
+	var bound, lipschitz float64
+	var degree int
+	realCoeffs := true
+
+	for _, p := range polys {
+
+		var u, uNoise float64
+
+		switch p.Basis {
+		case bignum.Chebyshev:
+
+			if !(Interval{Min: -1, Max: 1}).Contains(in.Real) || in.Imag.Abs() > 1e-9 {
+				a, _ := p.A.Float64()
+				b, _ := p.B.Float64()
+				x := in.Real.Mul(Interval{Min: (b - a) / 2, Max: (b - a) / 2}).Add(Interval{Min: (a + b) / 2, Max: (a + b) / 2})
+				e.Ranges.warn("polynomial: the input, of real part in %v (imaginary part in %v after the change of basis), may leave the interval [%.6g, %.6g] of the polynomial", x, in.Imag, a, b)
+			}
+
+			u = math.Max(in.Magnitude(), 1)
+			uNoise = math.Max(math.Hypot(real.Abs(), imag.Abs()), 1)
+
+		default:
+			u = in.Magnitude()
+			uNoise = math.Hypot(real.Abs(), imag.Abs())
+		}
+
+		var sum, slope float64
+
+		for i, c := range p.Coeffs {
+
+			if c == nil {
+				continue
+			}
+
+			re, _ := c[0].Float64()
+			im, _ := c[1].Float64()
+			realCoeffs = realCoeffs && im == 0
+
+			abs := math.Hypot(re, im)
+
+			t, _ := basisBound(p.Basis, i, u)
+			sum += abs * t
+
+			_, dt := basisBound(p.Basis, i, uNoise)
+			slope += abs * dt
+		}
+
+		bound = math.Max(bound, sum)
+		lipschitz = math.Max(lipschitz, slope)
+		degree = max(degree, p.Degree())
+	}
+
+	std := math.Sqrt(float64(degree+1)) * (e.keySwitchingStd(elOut.Level) + e.roundingStd(1))
+
+	r := disk(bound, lipschitz*in.Noise+e.tail()*std/elOut.Scale.Float64())
+
+	if realCoeffs && in.Imag == (Interval{}) {
+		r.Imag = Interval{}
+	}
+
+	e.setRange("polynomial", elOut, &r)
+}
+
+// basisBound returns bounds on |P_i(x)| and |P_i'(x)| for |x| <= u, where P_i is the i-th
+// polynomial of the basis: x^i, or T_i for which u >= 1 and the bounds are T_i(u) and i U_{i-1}(u).
+func basisBound(basis bignum.Basis, i int, u float64) (t, dt float64) {
+
+	if basis != bignum.Chebyshev {
+		if i == 0 {
+			return 1, 0
+		}
+		return math.Pow(u, float64(i)), float64(i) * math.Pow(u, float64(i-1))
+	}
+
+	// T_0 = 1, T_1 = u, T_{k+1} = 2u T_k - T_{k-1}
+	// U_0 = 1, U_1 = 2u, U_{k+1} = 2u U_k - U_{k-1}
+	t0, t1 := 1.0, u
+	u0, u1 := 0.0, 1.0
+
+	if i == 0 {
+		return t0, 0
+	}
+
+	for k := 1; k < i; k++ {
+		t0, t1 = t1, 2*u*t1-t0
+		u0, u1 = u1, 2*u*u1-u0
+	}
+
+	return t1, float64(i) * u1
+}
+
+// linearTransformationRange sets the range of the evaluation elOut
+// of the linear transformation on an input of range rIn.
+func (e Estimator) linearTransformationRange(rIn *Range, lt LinearTransformation, elOut *Element) {
+
+	if e.Ranges == nil {
+		return
+	}
+
+	if rIn == nil {
+		elOut.Range = nil
+		return
+	}
+
+	in := *rIn
+
+	// Sum of the largest magnitude of each diagonal
+	var sum float64
+	for _, diag := range lt.Value {
+		var m float64
+		for _, c := range diag {
+			if c != nil {
+				re, _ := c[0].Float64()
+				im, _ := c[1].Float64()
+				m = math.Max(m, math.Hypot(re, im))
+			}
+		}
+		sum += m
+	}
+
+	n := math.Sqrt(float64(len(lt.Value)))
+
+	// Encoding noise of the diagonals and key-switching noise of the rotations
+	std := n*in.Magnitude()*e.roundingStd(0)/lt.Scale.Float64() + (n*e.keySwitchingStd(elOut.Level)+e.roundingStd(1))/elOut.Scale.Float64()
+
+	r := disk(sum*in.Magnitude(), sum*in.Noise+e.tail()*std)
+
+	e.setRange("linear_transformation", elOut, &r)
+}
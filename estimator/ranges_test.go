@@ -0,0 +1,163 @@
+package estimator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+func TestInterval(t *testing.T) {
+
+	i, j := Interval{Min: -1, Max: 2}, Interval{Min: 3, Max: 4}
+
+	for _, tc := range []struct {
+		name       string
+		have, want Interval
+	}{
+		{"Add", i.Add(j), Interval{Min: 2, Max: 6}},
+		{"Sub", i.Sub(j), Interval{Min: -5, Max: -1}},
+		{"Mul", i.Mul(j), Interval{Min: -4, Max: 8}},
+		{"Neg", i.Neg(), Interval{Min: -2, Max: 1}},
+		{"Widen", i.Widen(0.5), Interval{Min: -1.5, Max: 2.5}},
+		{"NewInterval", NewInterval(3, -1, 2), Interval{Min: -1, Max: 3}},
+	} {
+		if tc.have != tc.want {
+			t.Errorf("%s: %v != %v", tc.name, tc.have, tc.want)
+		}
+	}
+}
+
+// checkRange checks that the range of el is known and contains its decrypted values.
+func checkRange(t *testing.T, est Estimator, el *Element) {
+
+	t.Helper()
+
+	if el.Range == nil {
+		t.Fatal("unknown range")
+	}
+
+	re, im := el.Range.Bounds()
+
+	for i, v := range est.Decrypt(el) {
+		if c := v.Complex128(); real(c) < re.Min || real(c) > re.Max || imag(c) < im.Min || imag(c) > im.Max {
+			t.Fatalf("slot %d: %v not in %v", i, c, el.Range)
+		}
+	}
+}
+
+func TestRangeAnalysis(t *testing.T) {
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            10,
+		LogQ:            []int{55, 45, 45, 45},
+		LogP:            []int{61},
+		LogDefaultScale: 45,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	est := NewEstimatorFromSeed(params, 1)
+	est.Ranges = NewRangeAnalysis()
+
+	source := NewTestRand(1)
+
+	// newElement returns a fresh element of real values in [min, max] and of range [min, max].
+	newElement := func(min, max float64) *Element {
+
+		values := make([]float64, est.MaxSlots())
+		for i := range values {
+			values[i] = source.Float64(min, max)
+		}
+
+		el := est.NewElement(values, 1, params.MaxLevel(), params.DefaultScale())
+		est.AddEncryptionNoisePk(el)
+		est.SetRange(el, Interval{Min: min, Max: max}, Interval{})
+
+		return el
+	}
+
+	x := newElement(0.5, 1)
+	y := newElement(-2, -1)
+
+	t.Run("Mul", func(t *testing.T) {
+
+		el, err := est.MulRelinNew(x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = est.Rescale(el, el); err != nil {
+			t.Fatal(err)
+		}
+
+		if have, want := el.Range.Real, (Interval{Min: -2, Max: -0.5}); have != want {
+			t.Fatalf("real part: %v != %v", have, want)
+		}
+
+		if have := el.Range.Imag; have != (Interval{}) {
+			t.Fatalf("imaginary part: %v != [0, 0]", have)
+		}
+
+		checkRange(t, est, el)
+	})
+
+	t.Run("Polynomial", func(t *testing.T) {
+
+		// 1 + 2y + 3y^2, whose magnitude is at most 1 + 2*2 + 3*4 = 17 for |y| <= 2
+		poly := bignum.NewPolynomial(bignum.Monomial, []float64{1, 2, 3}, nil)
+
+		el, err := est.EvaluatePolynomialNew(y, poly, y.Scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if have, want := el.Range.Real, (Interval{Min: -17, Max: 17}); have != want {
+			t.Fatalf("real part: %v != %v", have, want)
+		}
+
+		if have := el.Range.Imag; have != (Interval{}) {
+			t.Fatalf("imaginary part: %v != [0, 0]", have)
+		}
+
+		checkRange(t, est, el)
+	})
+
+	if warnings := est.Ranges.Warnings(); len(warnings) != 0 {
+		t.Fatalf("Warnings: %v", warnings)
+	}
+
+	t.Run("Domain", func(t *testing.T) {
+
+		est := est
+		est.Ranges = NewRangeAnalysis()
+
+		// y in [-2, -1] is outside [-1, 1]
+		poly := bignum.NewPolynomial(bignum.Chebyshev, []float64{0, 1}, [2]float64{-1, 1})
+
+		if _, err := est.EvaluatePolynomialNew(y, poly, y.Scale); err != nil {
+			t.Fatal(err)
+		}
+
+		if warnings := est.Ranges.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "may leave the interval") {
+			t.Fatalf("Warnings: %v", warnings)
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+
+		est := est
+		est.Ranges = NewRangeAnalysis()
+
+		// 2^20 * 2^45 > q0 / 2 at level 0
+		el := x.CopyNew()
+		el.Level = 0
+		est.SetRange(el, Interval{Min: -1 << 20, Max: 1 << 20}, Interval{})
+
+		if warnings := est.Ranges.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "may overflow") {
+			t.Fatalf("Warnings: %v", warnings)
+		}
+	})
+}
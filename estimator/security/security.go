@@ -0,0 +1,394 @@
+// Package security estimates the bit-security of the (R)LWE instances of CKKS parameters,
+// offline, with the core-SVP methodology.
+//
+// The cost of an attack is the cost of one call to a BKZ oracle of block size beta
+// (see CostModel). Three attacks are estimated:
+//
+//   - primal: uSVP on the embedding lattice with the secret rescaled to the size of
+//     the error [ADPS16], with the optimal number of samples;
+//   - dual: short vectors of the scaled dual lattice distinguishing the samples from
+//     uniform, the short vectors of a sieving call being reused [Alb17];
+//   - hybrid: for sparse ternary secrets, guessing k coordinates of the secret equal
+//     to zero and running the best of the primal and dual attacks on the remaining
+//     n-k coordinates, repeated 1/p times where p is the probability of the guess [Alb17].
+//
+// The estimates follow the ones of the lattice-estimator without its refinements
+// (exact probabilities of success, small block sizes, hybrid meet-in-the-middle),
+// and should be confirmed with it for deployed parameters.
+//
+// [ADPS16]: https://eprint.iacr.org/2015/1092
+// [Alb17]: https://eprint.iacr.org/2017/047
+package security
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// CostModel is the log2 cost of a call to a BKZ oracle.
+type CostModel int
+
+const (
+	// CoreSVP is 0.292 * beta, the classical cost of sieving in dimension beta.
+	CoreSVP = CostModel(iota)
+	// QuantumCoreSVP is 0.265 * beta, the quantum cost of sieving in dimension beta.
+	QuantumCoreSVP
+	// BKZSieve is 0.292 * beta + 16.4 + log2(8d), the model of the HomomorphicEncryption.org standard.
+	BKZSieve
+)
+
+// Cost returns the log2 cost of a call to BKZ with block size beta in dimension d.
+func (m CostModel) Cost(beta, d int) float64 {
+	switch m {
+	case QuantumCoreSVP:
+		return 0.265 * float64(beta)
+	case BKZSieve:
+		return 0.292*float64(beta) + 16.4 + math.Log2(8*float64(d))
+	default:
+		return 0.292 * float64(beta)
+	}
+}
+
+// sieveLog2Vectors is the log2 number of short vectors output by a sieving call.
+const sieveLog2Vectors = 0.2075
+
+// minBeta is the smallest block size considered, below which the root-Hermite
+// factor of BKZ is not well approximated.
+const minBeta = 40
+
+// LWE is an LWE instance of dimension N and modulus 2^LogQ.
+type LWE struct {
+	N    int
+	LogQ float64
+
+	// Sigma is the standard deviation of the error.
+	Sigma float64
+
+	// H is the Hamming weight of a sparse ternary secret, or 0 if
+	// the coefficients of the secret have standard deviation SecretStd.
+	H         int
+	SecretStd float64
+}
+
+// NewLWE returns the LWE instance of the secret key of the parameters,
+// whose modulus is QP, the largest modulus of an evaluation key.
+func NewLWE(params rlwe.ParameterProvider) (lwe LWE, err error) {
+
+	p := params.GetRLWEParameters()
+
+	lwe = LWE{
+		N:    p.N(),
+		LogQ: p.LogQP(),
+	}
+
+	switch xe := p.Xe().(type) {
+	case ring.DiscreteGaussian:
+		lwe.Sigma = xe.Sigma
+	default:
+		return lwe, fmt.Errorf("invalid Xe: %T is not ring.DiscreteGaussian", xe)
+	}
+
+	switch xs := p.Xs().(type) {
+	case ring.Ternary:
+		if xs.H != 0 {
+			lwe.H = xs.H
+		} else {
+			lwe.SecretStd = math.Sqrt(xs.P)
+		}
+	case ring.DiscreteGaussian:
+		lwe.SecretStd = xs.Sigma
+	default:
+		return lwe, fmt.Errorf("invalid Xs: %T is not ring.Ternary or ring.DiscreteGaussian", xs)
+	}
+
+	return
+}
+
+// secretStd returns the standard deviation of the coefficients of the secret.
+func (lwe LWE) secretStd() float64 {
+	if lwe.H != 0 {
+		return math.Sqrt(float64(lwe.H) / float64(lwe.N))
+	}
+	return lwe.SecretStd
+}
+
+// Attack is the estimated cost of an attack.
+type Attack struct {
+	Name string
+
+	// Bits is the log2 cost of the attack.
+	Bits float64
+
+	// Beta is the block size of BKZ and Dim the dimension of the lattice.
+	Beta, Dim int
+
+	// Zeros is the number of coordinates of the secret guessed to be zero.
+	Zeros int
+}
+
+func (a Attack) String() string {
+	s := fmt.Sprintf("%-7s %7.2f bits (beta=%d, d=%d", a.Name, a.Bits, a.Beta, a.Dim)
+	if a.Zeros != 0 {
+		s += fmt.Sprintf(", zeros=%d", a.Zeros)
+	}
+	return s + ")"
+}
+
+// Estimate is the estimated cost of the attacks on an LWE instance.
+type Estimate struct {
+	LWE     LWE
+	Attacks []Attack
+}
+
+// Bits returns the log2 cost of the cheapest attack.
+func (e Estimate) Bits() float64 {
+	bits := math.Inf(1)
+	for _, a := range e.Attacks {
+		bits = min(bits, a.Bits)
+	}
+	return bits
+}
+
+func (e Estimate) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "LWE(n=%d, log2(q)=%.2f, sigma=%.2f", e.LWE.N, e.LWE.LogQ, e.LWE.Sigma)
+	if e.LWE.H != 0 {
+		fmt.Fprintf(&sb, ", h=%d", e.LWE.H)
+	} else {
+		fmt.Fprintf(&sb, ", std(s)=%.3f", e.LWE.SecretStd)
+	}
+	fmt.Fprintf(&sb, "): %.2f bits\n", e.Bits())
+	for _, a := range e.Attacks {
+		fmt.Fprintf(&sb, "  %s\n", a)
+	}
+	return sb.String()
+}
+
+// Estimate returns the estimated costs of the attacks on the instance.
+func (lwe LWE) Estimate(model CostModel) (e Estimate) {
+
+	e.LWE = lwe
+	e.Attacks = []Attack{lwe.primal(model), lwe.dual(model)}
+
+	if lwe.H != 0 && lwe.H < lwe.N {
+		e.Attacks = append(e.Attacks, lwe.hybrid(model))
+	}
+
+	return
+}
+
+// Parameters returns the estimated costs of the attacks on the secret key of the parameters.
+func Parameters(params rlwe.ParameterProvider, model CostModel) (e Estimate, err error) {
+
+	lwe, err := NewLWE(params)
+	if err != nil {
+		return e, fmt.Errorf("NewLWE: %w", err)
+	}
+
+	return lwe.Estimate(model), nil
+}
+
+// Bits returns the bit-security of the parameters in the cost model, or 0 if it cannot be
+// estimated. Its method value, e.g. security.BKZSieve.Bits, can be used as the Security
+// of optimizer.Options.
+func (m CostModel) Bits(params ckks.Parameters) float64 {
+
+	e, err := Parameters(params, m)
+	if err != nil {
+		return 0
+	}
+
+	return e.Bits()
+}
+
+// BootstrappingEstimate is the estimated cost of the attacks
+// on the secrets used by a bootstrapping.
+type BootstrappingEstimate struct {
+	Residual      Estimate
+	Bootstrapping Estimate
+
+	// Ephemeral is the estimate of the sparse ephemeral secret, whose evaluation keys
+	// are generated modulo Q0 * P0 of the bootstrapping parameters (nil if none).
+	Ephemeral *Estimate
+}
+
+// Bits returns the log2 cost of the cheapest attack on one of the secrets.
+func (e BootstrappingEstimate) Bits() (bits float64) {
+
+	bits = min(e.Residual.Bits(), e.Bootstrapping.Bits())
+
+	if e.Ephemeral != nil {
+		bits = min(bits, e.Ephemeral.Bits())
+	}
+
+	return
+}
+
+// Bootstrapping returns the estimated costs of the attacks on the secrets used by the bootstrapping.
+func Bootstrapping(params bootstrapping.Parameters, model CostModel) (e BootstrappingEstimate, err error) {
+
+	if e.Residual, err = Parameters(params.ResidualParameters, model); err != nil {
+		return e, fmt.Errorf("ResidualParameters: %w", err)
+	}
+
+	btp := params.BootstrappingParameters
+
+	if e.Bootstrapping, err = Parameters(btp, model); err != nil {
+		return e, fmt.Errorf("BootstrappingParameters: %w", err)
+	}
+
+	if h := params.EphemeralSecretWeight; h != 0 {
+
+		lwe := e.Bootstrapping.LWE
+		lwe.LogQ = math.Log2(float64(btp.Q()[0])) + math.Log2(float64(btp.P()[0]))
+		lwe.H, lwe.SecretStd = h, 0
+
+		ephemeral := lwe.Estimate(model)
+		e.Ephemeral = &ephemeral
+	}
+
+	return
+}
+
+// log2Delta returns the log2 of the root-Hermite factor of BKZ with block size beta.
+func log2Delta(beta int) float64 {
+	b := float64(beta)
+	return (math.Log2(b/(2*math.Pi*math.E)) + math.Log2(math.Pi*b)/b) / (2 * (b - 1))
+}
+
+// primal returns the cost of the uSVP attack on the embedding lattice of dimension
+// d = m + n + 1, which succeeds if sigma * sqrt(beta) <= delta^(2beta-d) * vol^(1/d).
+func (lwe LWE) primal(model CostModel) Attack {
+
+	n := float64(lwe.N)
+
+	// The coordinates of the secret are scaled by nu to match the size of the error.
+	log2Nu := math.Log2(lwe.Sigma / lwe.secretStd())
+
+	// log2(vol) = m * log2(q) + n * log2(nu)
+	a, b := lwe.LogQ, n*log2Nu
+
+	for beta := minBeta; beta <= 2*lwe.N; beta++ {
+
+		log2d := log2Delta(beta)
+
+		// Number of samples maximizing delta^(-d) * vol^(1/d).
+		c := n + 1
+		m := math.Sqrt((a*c-b)/log2d) - c
+		m = math.Round(min(max(m, 1), 2*n))
+
+		d := m + c
+		if float64(beta) > d {
+			continue
+		}
+
+		if math.Log2(lwe.Sigma)+0.5*math.Log2(float64(beta)) <= (2*float64(beta)-d)*log2d+(a*m+b)/d {
+			return Attack{Name: "primal", Bits: model.Cost(beta, int(d)), Beta: beta, Dim: int(d)}
+		}
+	}
+
+	return Attack{Name: "primal", Bits: math.Inf(1)}
+}
+
+// dual returns the cost of the dual attack: a vector of length l of the scaled dual lattice
+// of dimension d = m + n gives a sample of standard deviation sigma * l which distinguishes
+// the instance from uniform with advantage eps = exp(-2 pi^2 (sigma * l / q)^2), and about
+// 1/eps^2 of them are needed, of which a sieving call outputs 2^(0.2075 beta).
+//
+// The scaled dual lattice has the volume of the embedding lattice of the primal attack, and
+// with the vectors of the sieving call the attack succeeds once sigma * l is about q, which is
+// the condition of the primal attack up to constants smaller than a bit. The two attacks thus
+// have the same cost up to one block size, and the same block size for large n [ADPS16, §6].
+func (lwe LWE) dual(model CostModel) (best Attack) {
+
+	best = Attack{Name: "dual", Bits: math.Inf(1)}
+
+	n := float64(lwe.N)
+
+	// log2(det) of the lattice whose last n coordinates are scaled down by sigma / std(s).
+	log2Det := n * (lwe.LogQ - math.Log2(lwe.Sigma/lwe.secretStd()))
+
+	for beta := minBeta; beta <= 2*lwe.N; beta++ {
+
+		log2d := log2Delta(beta)
+
+		// Dimension minimizing d * log2(delta) + log2(det) / d.
+		d := math.Round(min(max(math.Sqrt(log2Det/log2d), n+1), 3*n))
+
+		log2l := d*log2d + log2Det/d
+
+		// log2(1/eps)
+		log2Adv := 2 * math.Pi * math.Pi * math.Exp2(2*(log2l+math.Log2(lwe.Sigma)-lwe.LogQ)) * math.Log2E
+
+		bits := model.Cost(beta, int(d)) + max(0, 2*log2Adv-sieveLog2Vectors*float64(beta))
+
+		if bits < best.Bits {
+			best.Bits, best.Beta, best.Dim = bits, beta, int(d)
+		}
+
+		// The cost of BKZ alone exceeds the best attack.
+		if model.Cost(beta, int(d)) > best.Bits {
+			break
+		}
+	}
+
+	return
+}
+
+// hybrid returns the cost of the cheapest of the primal and dual attacks on the instance
+// with k coordinates of the sparse secret guessed to be zero, times the inverse of the
+// probability C(n-h, k) / C(n, k) of the guess.
+func (lwe LWE) hybrid(model CostModel) (best Attack) {
+
+	best = Attack{Name: "hybrid", Bits: math.Inf(1)}
+
+	// cost returns the cost of the attack with k zeros.
+	cost := func(k int) Attack {
+
+		reduced := lwe
+		reduced.N = lwe.N - k
+
+		a := reduced.primal(model)
+		if dual := reduced.dual(model); dual.Bits < a.Bits {
+			a = dual
+		}
+
+		a.Name, a.Zeros = "hybrid", k
+		a.Bits -= log2Binomial(lwe.N-lwe.H, k) - log2Binomial(lwe.N, k)
+
+		return a
+	}
+
+	// Coarse search over the number of zeros, then refinement around the best one.
+	maxZeros := lwe.N - lwe.H - 1
+	step := max(1, maxZeros/64)
+
+	for k := step; k <= maxZeros; k += step {
+		if a := cost(k); a.Bits < best.Bits {
+			best = a
+		}
+	}
+
+	fine := max(1, step/16)
+	for k := max(fine, best.Zeros-step); k <= min(maxZeros, best.Zeros+step); k += fine {
+		if a := cost(k); a.Bits < best.Bits {
+			best = a
+		}
+	}
+
+	return
+}
+
+// log2Binomial returns log2(C(n, k)).
+func log2Binomial(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return (a - b - c) / math.Ln2
+}
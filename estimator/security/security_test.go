@@ -0,0 +1,111 @@
+package security
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+// ternaryStd is the standard deviation of a uniform ternary coefficient.
+var ternaryStd = math.Sqrt(2.0 / 3)
+
+func TestEstimateHEStandard(t *testing.T) {
+
+	// Largest log2(QP) of a uniform ternary secret for 128, 192 and 256 bits
+	// of security against classical attacks (HomomorphicEncryption.org standard, 2018).
+	for logN, logQ := range map[int][3]float64{
+		10: {27, 19, 14},
+		11: {54, 37, 29},
+		12: {109, 75, 58},
+		13: {218, 152, 118},
+		14: {438, 305, 237},
+		15: {881, 611, 476},
+	} {
+		for i, target := range []float64{128, 192, 256} {
+
+			lwe := LWE{N: 1 << logN, LogQ: logQ[i], Sigma: 3.19, SecretStd: ternaryStd}
+
+			if bits := lwe.Estimate(BKZSieve).Bits(); bits < target-2 || bits > target+10 {
+				t.Errorf("LogN=%d, log2(QP)=%.0f: %.2f bits not in [%.0f, %.0f]", logN, logQ[i], bits, target-2, target+10)
+			}
+		}
+	}
+}
+
+func TestEstimatePrimalDual(t *testing.T) {
+
+	for _, lwe := range []LWE{
+		{N: 1 << 10, LogQ: 27, Sigma: 3.19, SecretStd: ternaryStd},
+		{N: 1 << 15, LogQ: 881, Sigma: 3.19, SecretStd: ternaryStd},
+		{N: 1 << 16, LogQ: 1555, Sigma: 3.19, SecretStd: ternaryStd},
+	} {
+
+		primal, dual := lwe.primal(BKZSieve), lwe.dual(BKZSieve)
+
+		// Same cost up to one block size (see dual)
+		if math.Abs(float64(primal.Beta-dual.Beta)) > 1 || math.Abs(primal.Bits-dual.Bits) > 1 {
+			t.Errorf("N=%d: primal %s and dual %s differ by more than one block size", lwe.N, primal, dual)
+		}
+	}
+
+	// On small instances, the dual attack is cheaper by one block size
+	lwe := LWE{N: 1 << 10, LogQ: 27, Sigma: 3.19, SecretStd: ternaryStd}
+	if primal, dual := lwe.primal(BKZSieve), lwe.dual(BKZSieve); dual.Beta >= primal.Beta {
+		t.Errorf("N=%d: dual %s is not cheaper than primal %s", lwe.N, dual, primal)
+	}
+}
+
+func TestEstimateMonotone(t *testing.T) {
+
+	lwe := LWE{N: 1 << 14, LogQ: 438, Sigma: 3.19, SecretStd: ternaryStd}
+
+	bits := lwe.Estimate(CoreSVP).Bits()
+
+	larger := lwe
+	larger.LogQ += 40
+	if b := larger.Estimate(CoreSVP).Bits(); b >= bits {
+		t.Errorf("log2(QP)+40: %.2f >= %.2f bits", b, bits)
+	}
+
+	if b := lwe.Estimate(QuantumCoreSVP).Bits(); b >= bits {
+		t.Errorf("QuantumCoreSVP: %.2f >= %.2f bits", b, bits)
+	}
+
+	sparse := lwe
+	sparse.H, sparse.SecretStd = 192, 0
+
+	e := sparse.Estimate(CoreSVP)
+	if len(e.Attacks) != 3 || e.Attacks[2].Zeros == 0 {
+		t.Fatalf("sparse secret: no hybrid attack: %s", e)
+	}
+
+	if b := e.Bits(); b >= bits {
+		t.Errorf("sparse secret: %.2f >= %.2f bits", b, bits)
+	}
+}
+
+func TestParameters(t *testing.T) {
+
+	// LogN=15 and log2(QP)=881 with the default ternary secret of Lattigo
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            15,
+		LogQ:            []int{60, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40, 40},
+		LogP:            []int{61},
+		Xs:              ring.Ternary{P: 2.0 / 3},
+		LogDefaultScale: 40,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logQP := params.LogQP(); math.Abs(logQP-881) > 1 {
+		t.Fatalf("log2(QP): %f != 881", logQP)
+	}
+
+	if bits := BKZSieve.Bits(params); math.Abs(bits-128) > 2 {
+		t.Errorf("BKZSieve.Bits: %.2f != 128", bits)
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"math"
 
 	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
 )
 
 type Stats struct {
 	ckks.PrecisionStats
 	N float64
+
+	// Slots are the streaming summaries of the per-slot log2 precision
+	// of the real part, the imaginary part and the L2 norm, fed by AddSlots.
+	Slots [3]Histogram
 }
 
 func NewStats() Stats {
@@ -20,6 +25,9 @@ func NewStats() Stats {
 	s.MINLog2Err.Real = 1e10
 	s.MINLog2Err.Imag = 1e10
 	s.MINLog2Err.L2 = 1e10
+	for i := range s.Slots {
+		s.Slots[i] = NewHistogram(DefaultBinsPerUnit)
+	}
 	return s
 }
 
@@ -96,8 +104,120 @@ func (s *Stats) Add(a ckks.PrecisionStats) {
 	s.N++
 }
 
+// AddSlots adds the per-slot log2 precision of have with respect to want to the streaming
+// summaries. Errors equal to zero are accounted as a precision of log2Scale.
+func (s *Stats) AddSlots(want, have []*bignum.Complex, log2Scale float64) {
+
+	s.Log2Scale = log2Scale
+
+	diff := bignum.NewComplex()
+
+	for i := range want {
+
+		diff.Sub(have[i], want[i])
+
+		re, _ := diff[0].Float64()
+		im, _ := diff[1].Float64()
+
+		for j, err := range []float64{math.Abs(re), math.Abs(im), math.Hypot(re, im)} {
+			if err == 0 {
+				s.Slots[j].Add(log2Scale)
+			} else {
+				s.Slots[j].Add(-math.Log2(err))
+			}
+		}
+	}
+}
+
+// Merge adds the runs and slots of other, which must not be finalized.
+func (s *Stats) Merge(other Stats) (err error) {
+
+	for _, pair := range [][2]*ckks.Stats{
+		{&s.MINLog2Prec, &other.MINLog2Prec},
+		{&s.MINLog2Err, &other.MINLog2Err},
+	} {
+		pair[0].Real = min(pair[0].Real, pair[1].Real)
+		pair[0].Imag = min(pair[0].Imag, pair[1].Imag)
+		pair[0].L2 = min(pair[0].L2, pair[1].L2)
+	}
+
+	for _, pair := range [][2]*ckks.Stats{
+		{&s.MAXLog2Prec, &other.MAXLog2Prec},
+		{&s.MAXLog2Err, &other.MAXLog2Err},
+	} {
+		pair[0].Real = max(pair[0].Real, pair[1].Real)
+		pair[0].Imag = max(pair[0].Imag, pair[1].Imag)
+		pair[0].L2 = max(pair[0].L2, pair[1].L2)
+	}
+
+	for _, pair := range [][2]*ckks.Stats{
+		{&s.AVGLog2Prec, &other.AVGLog2Prec},
+		{&s.MEDLog2Prec, &other.MEDLog2Prec},
+		{&s.STDLog2Prec, &other.STDLog2Prec},
+		{&s.AVGLog2Err, &other.AVGLog2Err},
+		{&s.MEDLog2Err, &other.MEDLog2Err},
+		{&s.STDLog2Err, &other.STDLog2Err},
+	} {
+		pair[0].Real += pair[1].Real
+		pair[0].Imag += pair[1].Imag
+		pair[0].L2 += pair[1].L2
+	}
+
+	s.N += other.N
+
+	if other.Log2Scale != 0 {
+		s.Log2Scale = other.Log2Scale
+	}
+
+	for i := range s.Slots {
+		if err = s.Slots[i].Merge(other.Slots[i]); err != nil {
+			return fmt.Errorf("s.Slots[%d].Merge: %w", i, err)
+		}
+	}
+
+	return
+}
+
+// Quantile returns the q-quantile of the per-slot log2 precision added with AddSlots.
+func (s Stats) Quantile(q float64) ckks.Stats {
+	return ckks.Stats{
+		Real: s.Slots[0].Quantile(q),
+		Imag: s.Slots[1].Quantile(q),
+		L2:   s.Slots[2].Quantile(q),
+	}
+}
+
+// Finalize averages the statistics of the runs added with Add. If slots were added with AddSlots,
+// the MIN, MAX, AVG, MED and STD are instead those of the pooled per-slot distribution.
 func (s *Stats) Finalize() {
 
+	if s.N != 0 {
+		s.finalizeRuns()
+	}
+
+	if s.Slots[2].Count == 0 {
+		return
+	}
+
+	for _, f := range []struct {
+		prec, err *ckks.Stats
+		get       func(h Histogram) float64
+	}{
+		{&s.MINLog2Prec, &s.MAXLog2Err, func(h Histogram) float64 { return h.Min }},
+		{&s.MAXLog2Prec, &s.MINLog2Err, func(h Histogram) float64 { return h.Max }},
+		{&s.AVGLog2Prec, &s.AVGLog2Err, Histogram.Mean},
+		{&s.MEDLog2Prec, &s.MEDLog2Err, Histogram.Median},
+	} {
+		f.prec.Real, f.prec.Imag, f.prec.L2 = f.get(s.Slots[0]), f.get(s.Slots[1]), f.get(s.Slots[2])
+		f.err.Real, f.err.Imag, f.err.L2 = s.Log2Scale-f.prec.Real, s.Log2Scale-f.prec.Imag, s.Log2Scale-f.prec.L2
+	}
+
+	s.STDLog2Prec.Real, s.STDLog2Prec.Imag, s.STDLog2Prec.L2 = s.Slots[0].Std(), s.Slots[1].Std(), s.Slots[2].Std()
+	s.STDLog2Err = s.STDLog2Prec
+}
+
+func (s *Stats) finalizeRuns() {
+
 	s.AVGLog2Prec.Real /= s.N
 	s.AVGLog2Prec.Imag /= s.N
 	s.AVGLog2Prec.L2 /= s.N
@@ -0,0 +1,196 @@
+package estimator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// ErrInvariant is wrapped by the errors of the operations on inconsistent
+// operands, which are all checked in strict mode (see Estimator.Strict).
+var ErrInvariant = errors.New("invariant violation")
+
+// invariantError returns an error naming the operation and the operand, which wraps ErrInvariant.
+func invariantError(op, operand, format string, args ...any) error {
+	return fmt.Errorf("%s: %s: %w: %s", op, operand, ErrInvariant, fmt.Sprintf(format, args...))
+}
+
+// checkElement returns an error if el is not a valid element of the estimator:
+// its degree, level and scale must be in range and its values of degree at
+// most el.Degree must be MaxSlots non-nil slots.
+func (e Estimator) checkElement(op, operand string, el *Element) (err error) {
+
+	if el == nil {
+		return invariantError(op, operand, "nil element")
+	}
+
+	if el.Degree < 0 || el.Degree > 2 {
+		return invariantError(op, operand, "degree %d not in [0, 2]", el.Degree)
+	}
+
+	if el.Level < 0 || el.Level > e.MaxLevel() {
+		return invariantError(op, operand, "level %d not in [0, %d]", el.Level, e.MaxLevel())
+	}
+
+	if el.Scale.Value.Sign() <= 0 || el.Scale.Value.IsInf() {
+		return invariantError(op, operand, "scale %v is not positive and finite", &el.Scale.Value)
+	}
+
+	for i := 0; i < el.Degree+1; i++ {
+
+		if len(el.Value[i]) != e.MaxSlots() {
+			return invariantError(op, operand, "len(Value[%d])=%d != MaxSlots=%d", i, len(el.Value[i]), e.MaxSlots())
+		}
+
+		for j, v := range el.Value[i] {
+			if v == nil || v[0] == nil || v[1] == nil {
+				return invariantError(op, operand, "Value[%d][%d] is nil", i, j)
+			}
+		}
+	}
+
+	return
+}
+
+// strictOperands returns, in strict mode, an error if one of the operands, named
+// op0, op1, ... in the order of the arguments, is invalid. The elements are
+// checked with checkElement and the vectors must not exceed MaxSlots.
+func (e Estimator) strictOperands(op string, operands ...rlwe.Operand) (err error) {
+
+	if !e.Strict {
+		return
+	}
+
+	for i, x := range operands {
+
+		operand := fmt.Sprintf("op%d", i)
+
+		switch x := x.(type) {
+		case *Element:
+			err = e.checkElement(op, operand, x)
+		case []*bignum.Complex:
+			if len(x) > e.MaxSlots() {
+				err = invariantError(op, operand, "len=%d > MaxSlots=%d", len(x), e.MaxSlots())
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// strictOutput returns, in strict mode, an error if the output of the operation is invalid.
+func (e Estimator) strictOutput(op, operand string, el *Element) (err error) {
+	if e.Strict {
+		return e.checkElement(op, operand, el)
+	}
+	return
+}
+
+// MaxScaleMismatch is the largest relative difference between the scale of a summand of
+// an addition, after its multiplication by the integer part of the ratio of the scales
+// of the operands, and the scale of the output, which is accepted in strict mode.
+const MaxScaleMismatch = 0x1p-20
+
+// matchedScales returns the scales of op0 and op1 after the addition multiplies the
+// operand of smaller scale by the integer part of the ratio of the scales, if at least 2.
+func matchedScales(op0, op1 rlwe.Scale) (s0, s1 rlwe.Scale) {
+
+	s0, s1 = op0, op1
+
+	switch op0.Cmp(op1) {
+	case -1:
+		if ratio := op1.Div(op0); ratio.Float64() >= 2 {
+			s0 = op0.Mul(rlwe.NewScale(ratio.BigInt()))
+		}
+	case 1:
+		if ratio := op0.Div(op1); ratio.Float64() >= 2 {
+			s1 = op1.Mul(rlwe.NewScale(ratio.BigInt()))
+		}
+	}
+
+	return
+}
+
+// strictScale returns, in strict mode, an error if the scale s of a summand of an
+// addition differs from the scale of the output by more than MaxScaleMismatch.
+func (e Estimator) strictScale(op, operand string, s, scale rlwe.Scale) (err error) {
+
+	if !e.Strict {
+		return
+	}
+
+	if mismatch := math.Abs(s.Div(scale).Float64() - 1); mismatch > MaxScaleMismatch {
+		return invariantError(op, operand, "scale 2^%.4f differs from the scale 2^%.4f of the output by a relative 2^%.2f > MaxScaleMismatch", s.Log2(), scale.Log2(), math.Log2(mismatch))
+	}
+
+	return
+}
+
+// strictIntegerScale returns, in strict mode, an error if scale is not a positive integer.
+func (e Estimator) strictIntegerScale(op, operand string, scale rlwe.Scale) (err error) {
+
+	if !e.Strict {
+		return
+	}
+
+	if scale.Value.Sign() <= 0 || !scale.Value.IsInt() {
+		return invariantError(op, operand, "scale %v is not a positive integer", &scale.Value)
+	}
+
+	return
+}
+
+// strictRotation returns, in strict mode, an error if k is not in [0, MaxSlots),
+// as required by an out-of-place rotation.
+func (e Estimator) strictRotation(op string, k int) (err error) {
+
+	if !e.Strict {
+		return
+	}
+
+	if k < 0 || k >= e.MaxSlots() {
+		return invariantError(op, "k", "%d not in [0, %d)", k, e.MaxSlots())
+	}
+
+	return
+}
+
+// NewElementStrict is as NewElement, but returns an error instead of panicking
+// if v is invalid, and also checks the degree, level and scale.
+func (e Estimator) NewElementStrict(v interface{}, degree, level int, scale rlwe.Scale) (el *Element, err error) {
+
+	var n int
+	switch v := v.(type) {
+	case []*bignum.Complex:
+		n = len(v)
+	case []complex128:
+		n = len(v)
+	case []*big.Float:
+		n = len(v)
+	case []float64:
+		n = len(v)
+	case nil:
+	default:
+		return nil, invariantError("new_element", "v", "invalid type %T: must be []*bignum.Complex, []complex128, []*big.Float or []float64", v)
+	}
+
+	if n > e.MaxSlots() {
+		return nil, invariantError("new_element", "v", "len=%d > MaxSlots=%d", n, e.MaxSlots())
+	}
+
+	el = e.NewElement(v, degree, level, scale)
+
+	if err = e.checkElement("new_element", "el", el); err != nil {
+		return nil, err
+	}
+
+	return
+}
@@ -0,0 +1,74 @@
+package estimator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+)
+
+func TestStrict(t *testing.T) {
+
+	params := testParameters(t)
+
+	paramsCI, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:            10,
+		LogQ:            []int{55, 45},
+		LogP:            []int{61},
+		LogDefaultScale: 45,
+		RingType:        ring.ConjugateInvariant,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	est := NewEstimatorFromSeed(params, 1)
+	est.Strict = true
+
+	estCI := NewEstimatorFromSeed(paramsCI, 1)
+
+	scale := params.DefaultScale()
+
+	for _, tc := range []struct {
+		name string
+		op   string
+		f    func() error
+	}{
+		{"ConjugateInvariantConjugate", "conjugate", func() error {
+			el := estCI.NewElement(nil, 1, 1, scale)
+			return estCI.Conjugate(el, el)
+		}},
+		{"RotateDegree2", "rotate", func() error {
+			el := est.NewElement(nil, 2, 1, scale)
+			return est.Rotate(el, 1, el)
+		}},
+		{"RescaleLevel0", "rescale", func() error {
+			el := est.NewElement(nil, 1, 0, scale)
+			return est.Rescale(el, el)
+		}},
+		{"NewElementLength", "new_element", func() error {
+			_, err := est.NewElementStrict(make([]complex128, params.MaxSlots()+1), 1, 1, scale)
+			return err
+		}},
+		{"AddLevel", "add", func() error {
+			el := est.NewElement(nil, 1, 1, scale)
+			el.Level = params.MaxLevel() + 1
+			return est.Add(el, el, el)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+
+			err := tc.f()
+
+			if !errors.Is(err, ErrInvariant) {
+				t.Fatalf("error %v does not wrap ErrInvariant", err)
+			}
+
+			if !strings.HasPrefix(err.Error(), tc.op+": ") {
+				t.Fatalf("error %q does not name the operation %q", err, tc.op)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package estimator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// TailModel is the model fitted on the tail of the distribution of the slot errors.
+type TailModel int
+
+const (
+	// GaussianTail fits a Gaussian tail on the largest slot errors. It suits the errors of
+	// linear operations (encryption, additions, multiplications, rescaling, key-switching),
+	// which are sums of many independent noises.
+	GaussianTail = TailModel(iota)
+
+	// ParetoTail fits a generalized Pareto distribution on the largest slot errors (peaks
+	// over threshold). It suits the errors of non-linear operations (polynomial evaluations,
+	// EvalMod, bootstrapping) whose tail is not Gaussian.
+	ParetoTail
+)
+
+func (m TailModel) String() string {
+	switch m {
+	case GaussianTail:
+		return "gaussian"
+	case ParetoTail:
+		return "pareto"
+	default:
+		return fmt.Sprintf("TailModel(%d)", int(m))
+	}
+}
+
+// DefaultMaxTailErrors is the default number of slot errors kept by a TailStats.
+const DefaultMaxTailErrors = 1 << 20
+
+// TailStats collects the slot errors of many runs and estimates
+// the probability that a slot error exceeds a bound.
+type TailStats struct {
+	Model TailModel
+
+	// Seed is the seed of the reservoir sampling of the errors and of the
+	// bootstrap of the confidence intervals, which are thus reproducible.
+	Seed int64
+
+	// MaxErrors is the number of errors kept (default: DefaultMaxTailErrors). Once
+	// more errors are added, Errors is a uniform sample of the added errors.
+	MaxErrors int
+
+	// Count is the number of added errors.
+	Count int
+
+	Errors []float64
+}
+
+// FailureProbability is the probability that the error of a slot exceeds 2^Log2Bound,
+// with its confidence interval [2^Log2PLow, 2^Log2PHigh].
+type FailureProbability struct {
+	Log2Bound  float64
+	Log2P      float64
+	Log2PLow   float64
+	Log2PHigh  float64
+	Observed   int
+	Samples    int
+	Confidence float64
+}
+
+// NewTailStats returns a new TailStats fitting the given model.
+func NewTailStats(model TailModel) TailStats {
+	return TailStats{Model: model}
+}
+
+// Add adds the slot errors |have - want| of a run.
+func (t *TailStats) Add(want, have []*bignum.Complex) {
+
+	maxErrors := t.MaxErrors
+	if maxErrors < 1 {
+		maxErrors = DefaultMaxTailErrors
+	}
+
+	diff := bignum.NewComplex().SetPrec(prec)
+	for i := range want {
+
+		diff.Sub(have[i], want[i])
+
+		t.Count++
+
+		if len(t.Errors) < maxErrors {
+			t.Errors = append(t.Errors, absComplex(diff))
+			continue
+		}
+
+		// Reservoir sampling: the error replaces a kept
+		// error with probability maxErrors / Count.
+		if j := uint64(TrialSeed(t.Seed, t.Count)) % uint64(t.Count); j < uint64(maxErrors) {
+			t.Errors[j] = absComplex(diff)
+		}
+	}
+}
+
+// FailureProbability returns the probability that the error of a slot exceeds 2^log2Bound
+// with a confidence interval at the given confidence level (e.g. 0.95). Observed and Samples
+// are counted on the kept errors.
+func (t TailStats) FailureProbability(log2Bound, confidence float64) (f FailureProbability, err error) {
+
+	if len(t.Errors) == 0 {
+		return f, fmt.Errorf("no errors")
+	}
+
+	if confidence <= 0 || confidence >= 1 {
+		return f, fmt.Errorf("invalid confidence: %f not in (0, 1)", confidence)
+	}
+
+	bound := math.Exp2(log2Bound)
+
+	f.Log2Bound = log2Bound
+	f.Samples = len(t.Errors)
+	f.Confidence = confidence
+
+	for _, e := range t.Errors {
+		if e > bound {
+			f.Observed++
+		}
+	}
+
+	switch t.Model {
+	case GaussianTail:
+		f.Log2P, f.Log2PLow, f.Log2PHigh = t.gaussianTail(bound, confidence)
+	case ParetoTail:
+		f.Log2P, f.Log2PLow, f.Log2PHigh = t.paretoTail(bound, confidence)
+	default:
+		return f, fmt.Errorf("invalid tail model: %d", t.Model)
+	}
+
+	return
+}
+
+// gaussianTail fits the Gaussian tail P(|e| > b) = exp(a - lambda * b^2) on the exceedances
+// over the 95th percentile, which also captures mixtures of Gaussians of different variances.
+func (t TailStats) gaussianTail(bound, confidence float64) (log2P, log2PLow, log2PHigh float64) {
+	return t.fitTail(bound, confidence, log2GaussianTail)
+}
+
+// paretoTail fits a generalized Pareto distribution on the exceedances
+// over the 95th percentile with probability weighted moments.
+func (t TailStats) paretoTail(bound, confidence float64) (log2P, log2PLow, log2PHigh float64) {
+	return t.fitTail(bound, confidence, log2ParetoTail)
+}
+
+// fitTail returns log2(P(|e| > bound)) extrapolated by log2Tail from the exceedances over the 95th percentile.
+// If the bound is below the threshold, the empirical probability is returned with its Wilson score interval.
+// The confidence interval of the extrapolation is given by a bootstrap over the exceedances, seeded with Seed.
+// The probabilities are at most 1.
+func (t TailStats) fitTail(bound, confidence float64, log2Tail func(z, u float64, y []float64) float64) (log2P, log2PLow, log2PHigh float64) {
+
+	x := make([]float64, len(t.Errors))
+	copy(x, t.Errors)
+	sort.Float64s(x)
+
+	n := len(x)
+
+	m := min(n, max(30, n/20))
+
+	var u float64
+	if m < n {
+		u = x[n-m-1]
+	}
+
+	alpha := 1 - confidence
+
+	if bound <= u {
+		var count int
+		for _, e := range x {
+			if e > bound {
+				count++
+			}
+		}
+		return wilson(count, n, alpha)
+	}
+
+	// Exceedances
+	y := make([]float64, m)
+	for i := range y {
+		y[i] = x[n-m+i] - u
+	}
+
+	log2Rate := math.Log2(float64(m) / float64(n))
+
+	log2P = min(log2Rate+log2Tail(bound-u, u, y), 0)
+
+	r := rand.New(rand.NewSource(t.Seed))
+
+	B := 200
+	samples := make([]float64, B)
+	resampled := make([]float64, m)
+	for b := range samples {
+		for i := range resampled {
+			resampled[i] = y[r.Intn(m)]
+		}
+		sort.Float64s(resampled)
+		samples[b] = min(log2Rate+log2Tail(bound-u, u, resampled), 0)
+	}
+
+	sort.Float64s(samples)
+
+	return log2P, samples[int(alpha/2*float64(B-1))], samples[int((1-alpha/2)*float64(B-1))]
+}
+
+// log2GaussianTail returns log2(P(Y > z)) for the Gaussian tail P(Y > y) = exp(a - lambda * (y+u)^2)
+// fitted by least squares on the empirical log survival function of the sorted exceedances y over u.
+func log2GaussianTail(z, u float64, y []float64) float64 {
+
+	m := float64(len(y))
+
+	var sx, sy, sxx, sxy float64
+	for i := range y {
+		xi := (y[i] + u) * (y[i] + u)
+		yi := math.Log((m - float64(i) - 0.5) / m)
+		sx += xi
+		sy += yi
+		sxx += xi * xi
+		sxy += xi * yi
+	}
+
+	slope := (m*sxy - sx*sy) / (m*sxx - sx*sx)
+	intercept := (sy - slope*sx) / m
+
+	return (intercept + slope*(z+u)*(z+u)) / math.Ln2
+}
+
+// log2ParetoTail returns log2(P(Y > z)) for the generalized Pareto
+// distribution fitted on the sorted exceedances y over u.
+func log2ParetoTail(z, u float64, y []float64) float64 {
+
+	xi, beta := fitPareto(y)
+
+	if math.Abs(xi) < 1e-9 {
+		return -z / (beta * math.Ln2)
+	}
+
+	w := 1 + xi*z/beta
+
+	// Beyond the end-point of the distribution
+	if w <= 0 {
+		return math.Inf(-1)
+	}
+
+	return -math.Log2(w) / xi
+}
+
+// fitPareto returns the shape xi and the scale beta of the generalized Pareto distribution
+// P(Y > y) = (1 + xi * y / beta)^(-1/xi) fitted with the probability weighted moments of
+// Hosking and Wallis on the sorted exceedances y.
+func fitPareto(y []float64) (xi, beta float64) {
+
+	m := float64(len(y))
+
+	var a0, a1 float64
+	for i := range y {
+		a0 += y[i]
+		a1 += (1 - (float64(i)+0.65)/m) * y[i]
+	}
+
+	a0 /= m
+	a1 /= m
+
+	xi = 2 - a0/(a0-2*a1)
+	beta = 2 * a0 * a1 / (a0 - 2*a1)
+
+	return
+}
+
+// wilson returns the log2 of the empirical probability count/n
+// and of its Wilson score interval at level 1-alpha.
+func wilson(count, n int, alpha float64) (log2P, log2PLow, log2PHigh float64) {
+
+	z := normalQuantile(1 - alpha/2)
+
+	p := float64(count) / float64(n)
+	nf := float64(n)
+
+	center := (p + z*z/(2*nf)) / (1 + z*z/nf)
+	half := z / (1 + z*z/nf) * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf))
+
+	return math.Log2(p), math.Log2(max(center-half, 0)), math.Log2(min(center+half, 1))
+}
+
+// normalQuantile returns the p-quantile of the standard normal distribution.
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// Log2Erfc returns log2(erfc(x)), using the asymptotic
+// erfc(x) ~ exp(-x^2)/(x sqrt(pi)) once erfc(x) underflows.
+func Log2Erfc(x float64) float64 {
+	if v := math.Erfc(x); v > 0 {
+		return math.Log2(v)
+	}
+	return (-x*x - math.Log(x*math.Sqrt(math.Pi))) / math.Ln2
+}
@@ -0,0 +1,198 @@
+package estimator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// testTailStats returns a TailStats of the model to which the errors are added, by runs of 1024.
+func testTailStats(model TailModel, errors []float64) (t TailStats) {
+
+	t = NewTailStats(model)
+
+	for i := 0; i < len(errors); i += 1024 {
+
+		n := min(1024, len(errors)-i)
+
+		want := make([]*bignum.Complex, n)
+		have := make([]*bignum.Complex, n)
+
+		for j := range want {
+			want[j] = bignum.ToComplex(0, prec)
+			have[j] = bignum.ToComplex(errors[i+j], prec)
+		}
+
+		t.Add(want, have)
+	}
+
+	return
+}
+
+func TestTailStatsKnownAnswer(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	n := 1 << 17
+
+	t.Run("Gaussian", func(t *testing.T) {
+
+		errors := make([]float64, n)
+		for i := range errors {
+			errors[i] = r.NormFloat64()
+		}
+
+		tail := testTailStats(GaussianTail, errors)
+
+		// P(|e| > b) = erfc(b/sqrt(2)) for e ~ N(0, 1)
+		for _, tc := range []struct {
+			log2Bound, tolerance float64
+		}{
+			{0, 0.05},
+			{2, 0.5},
+			{2.5, 2},
+		} {
+
+			f, err := tail.FailureProbability(tc.log2Bound, 0.95)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Log2Erfc(math.Exp2(tc.log2Bound) / math.Sqrt2)
+
+			if math.Abs(f.Log2P-want) > tc.tolerance {
+				t.Errorf("log2(bound)=%.1f: log2(P)=%.2f != %.2f", tc.log2Bound, f.Log2P, want)
+			}
+
+			if f.Log2PLow > f.Log2P || f.Log2P > f.Log2PHigh {
+				t.Errorf("log2(bound)=%.1f: log2(P)=%.2f not in [%.2f, %.2f]", tc.log2Bound, f.Log2P, f.Log2PLow, f.Log2PHigh)
+			}
+		}
+	})
+
+	t.Run("Pareto", func(t *testing.T) {
+
+		errors := make([]float64, n)
+		for i := range errors {
+			errors[i] = r.ExpFloat64()
+		}
+
+		tail := testTailStats(ParetoTail, errors)
+
+		// P(e > b) = exp(-b) for e ~ Exp(1)
+		for _, b := range []float64{4, 8, 16} {
+
+			f, err := tail.FailureProbability(math.Log2(b), 0.95)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := -b / math.Ln2; math.Abs(f.Log2P-want) > 0.1*math.Abs(want)+0.5 {
+				t.Errorf("bound=%.0f: log2(P)=%.2f != %.2f", b, f.Log2P, want)
+			}
+		}
+	})
+}
+
+func TestTailStatsReproducible(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	errors := make([]float64, 1<<14)
+	for i := range errors {
+		errors[i] = r.NormFloat64()
+	}
+
+	for _, model := range []TailModel{GaussianTail, ParetoTail} {
+
+		f0, err := testTailStats(model, errors).FailureProbability(2, 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f1, err := testTailStats(model, errors).FailureProbability(2, 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if f0 != f1 {
+			t.Errorf("%s: %v != %v", model, f0, f1)
+		}
+	}
+}
+
+func TestTailStatsProbability(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	// Heavy-tailed errors
+	errors := make([]float64, 1<<14)
+	for i := range errors {
+		errors[i] = math.Abs(r.NormFloat64() / r.NormFloat64())
+	}
+
+	for _, model := range []TailModel{GaussianTail, ParetoTail} {
+
+		tail := testTailStats(model, errors)
+
+		for log2Bound := -4.0; log2Bound <= 16; log2Bound++ {
+
+			f, err := tail.FailureProbability(log2Bound, 0.95)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if f.Log2P > 0 || f.Log2PLow > 0 || f.Log2PHigh > 0 {
+				t.Errorf("%s log2(bound)=%.0f: log2(P)=%.2f [%.2f, %.2f] > 0", model, log2Bound, f.Log2P, f.Log2PLow, f.Log2PHigh)
+			}
+		}
+	}
+
+	// A fitted tail greater than 1 is clamped
+	tail := testTailStats(GaussianTail, errors)
+	if log2P, log2PLow, log2PHigh := tail.fitTail(1<<10, 0.95, func(z, u float64, y []float64) float64 { return 16 }); log2P != 0 || log2PLow != 0 || log2PHigh != 0 {
+		t.Errorf("clamped fit: log2(P)=%.2f [%.2f, %.2f] != 0", log2P, log2PLow, log2PHigh)
+	}
+}
+
+func TestTailStatsReservoir(t *testing.T) {
+
+	// The errors of the i-th run are equal to i
+	errors := make([]float64, 64*1024)
+	for i := range errors {
+		errors[i] = float64(i / 1024)
+	}
+
+	tail := NewTailStats(GaussianTail)
+	tail.MaxErrors = 4096
+
+	for i := 0; i < len(errors); i += 1024 {
+
+		want := make([]*bignum.Complex, 1024)
+		have := make([]*bignum.Complex, 1024)
+
+		for j := range want {
+			want[j] = bignum.ToComplex(0, prec)
+			have[j] = bignum.ToComplex(errors[i+j], prec)
+		}
+
+		tail.Add(want, have)
+	}
+
+	if tail.Count != len(errors) || len(tail.Errors) != tail.MaxErrors {
+		t.Fatalf("Count=%d, len(Errors)=%d", tail.Count, len(tail.Errors))
+	}
+
+	// The kept errors are uniform over the runs, of mean 31.5 and std 18.5
+	var mean float64
+	for _, e := range tail.Errors {
+		mean += e
+	}
+	mean /= float64(len(tail.Errors))
+
+	if math.Abs(mean-31.5) > 1.5 {
+		t.Fatalf("mean of the kept errors: %f != 31.5", mean)
+	}
+}
@@ -33,6 +33,13 @@ func (e Estimator) NewTestVector(ecd *ckks.Encoder, key rlwe.EncryptionKey, a, b
 	values[0][0].SetFloat64(1)
 	values[0][1].SetFloat64(0)
 
+	// The imaginary part is discarded for ring.ConjugateInvariant
+	if e.IsConjugateInvariant() {
+		for i := range values {
+			values[i][1].SetFloat64(0)
+		}
+	}
+
 	el = e.NewElement(values, 1, params.MaxLevel(), params.DefaultScale())
 	e.AddEncodingNoise(el)
 
@@ -109,6 +116,13 @@ func (e Estimator) NewTestVectorFromSeed(ecd *hefloat.Encoder, key rlwe.Encrypti
 	values[0][0].SetFloat64(1)
 	values[0][1].SetFloat64(0)
 
+	// The imaginary part is discarded for ring.ConjugateInvariant
+	if e.IsConjugateInvariant() {
+		for i := range values {
+			values[i][1].SetFloat64(0)
+		}
+	}
+
 	el = e.NewElement(values, 1, params.MaxLevel(), params.DefaultScale())
 	e.AddEncodingNoise(el)
 
@@ -0,0 +1,609 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+	bootEst "github.com/tuneinsight/ckks-noise-estimator/bootstrapping"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/lintrans"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/mod1"
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/polynomial"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/ring"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// smallParameters returns parameters of degree 2^12 with the given moduli.
+func smallParameters(logQ []int, logP []int, logScale int) ckks.ParametersLiteral {
+	return ckks.ParametersLiteral{
+		LogN:            12,
+		LogQ:            logQ,
+		LogP:            logP,
+		LogDefaultScale: logScale,
+	}
+}
+
+// Cases are the primitives compared with Lattigo.
+var Cases = []Case{
+	{
+		Name:       "encode",
+		Parameters: smallParameters([]int{55}, []int{60}, 45),
+		Setup:      setupEncode,
+	},
+	{
+		Name:       "enc_sk",
+		Parameters: smallParameters([]int{55}, []int{60}, 45),
+		Setup:      setupEncrypt(false),
+	},
+	{
+		Name:       "enc_pk",
+		Parameters: smallParameters([]int{55}, []int{60}, 45),
+		Setup:      setupEncrypt(true),
+	},
+	{
+		Name:       "add",
+		Parameters: smallParameters([]int{55}, []int{60}, 45),
+		Setup:      setupBinary(false, false),
+	},
+	{
+		Name:       "mul_pt",
+		Parameters: smallParameters([]int{55, 45}, []int{60}, 45),
+		Setup:      setupBinary(true, false),
+	},
+	{
+		Name:       "mul_ct",
+		Parameters: smallParameters([]int{55, 45}, []int{60}, 45),
+		Setup:      setupBinary(true, true),
+	},
+	{
+		Name:       "relinearize",
+		Parameters: smallParameters([]int{55, 45}, []int{60}, 45),
+		Setup:      setupMulRelin(false),
+	},
+	{
+		Name:       "rescale",
+		Parameters: smallParameters([]int{55, 45}, []int{60}, 45),
+		Setup:      setupMulRelin(true),
+	},
+	{
+		Name:       "rotate",
+		Parameters: smallParameters([]int{55}, []int{60}, 45),
+		Setup:      setupRotate(1),
+	},
+	{
+		Name:       "conjugate",
+		Parameters: smallParameters([]int{55}, []int{60}, 45),
+		Setup:      setupRotate(0),
+	},
+	{
+		Name:       "linear_transformation",
+		Parameters: smallParameters([]int{55, 45}, []int{60}, 45),
+		Setup:      setupLinearTransformation,
+	},
+	{
+		Name:       "polynomial",
+		Parameters: smallParameters([]int{55, 45, 45, 45, 45, 45, 45, 45, 45}, []int{60, 60}, 45),
+		Min:        -8,
+		Max:        8,
+		Real:       true,
+		Setup:      setupPolynomial,
+	},
+	{
+		Name: "mod1",
+		Parameters: ckks.ParametersLiteral{
+			LogN:            12,
+			LogQ:            []int{55, 60, 60, 60, 60, 60, 60, 60, 60, 53},
+			LogP:            []int{61, 61, 61, 61},
+			LogDefaultScale: 45,
+			Xs:              ring.Ternary{H: 192},
+		},
+		Setup: setupMod1,
+	},
+	{
+		Name:          "bootstrapping",
+		Parameters:    smallParameters([]int{55, 45}, []int{61, 61, 61}, 45),
+		Bootstrapping: &bootstrapping.ParametersLiteral{LogP: []int{61, 61, 61, 61}},
+		Setup:         setupBootstrapping,
+	},
+}
+
+func setupEncode(env *Env) (Trial, error) {
+	return func() ([]Outcome, error) {
+		values, el, pt, _ := env.NewTestVector(nil)
+		return []Outcome{{values, env.Estimator.Decrypt(el), pt}}, nil
+	}, nil
+}
+
+func setupEncrypt(publicKey bool) func(env *Env) (Trial, error) {
+	return func(env *Env) (Trial, error) {
+
+		var key rlwe.EncryptionKey = env.SecretKey
+		if publicKey {
+			key = env.PublicKey
+		}
+
+		return func() ([]Outcome, error) {
+			values, el, _, ct := env.NewTestVector(key)
+			return []Outcome{{values, env.Estimator.Decrypt(el), ct}}, nil
+		}, nil
+	}
+}
+
+// setupBinary returns the setup of the addition of two ciphertexts or of the multiplication,
+// without relinearization, of a ciphertext with a plaintext or a ciphertext.
+func setupBinary(mul, ciphertext bool) func(env *Env) (Trial, error) {
+	return func(env *Env) (Trial, error) {
+
+		eval := ckks.NewEvaluator(env.Parameters, nil)
+		est := env.Estimator
+
+		mulCmplx := bignum.NewComplexMultiplier().Mul
+
+		return func() ([]Outcome, error) {
+
+			values0, el0, _, ct0 := env.NewTestVector(env.PublicKey)
+
+			var key rlwe.EncryptionKey
+			if ciphertext || !mul {
+				key = env.PublicKey
+			}
+
+			values1, el1, pt1, ct1 := env.NewTestVector(key)
+
+			var op1 rlwe.Operand = pt1
+			if key != nil {
+				op1 = ct1
+			}
+
+			for j := range values0 {
+				if mul {
+					mulCmplx(values0[j], values1[j], values0[j])
+				} else {
+					values0[j].Add(values0[j], values1[j])
+				}
+			}
+
+			if mul {
+
+				if err := eval.Mul(ct0, op1, ct0); err != nil {
+					return nil, fmt.Errorf("eval.Mul: %w", err)
+				}
+
+				if err := est.Mul(el0, el1, el0); err != nil {
+					return nil, fmt.Errorf("est.Mul: %w", err)
+				}
+
+			} else {
+
+				if err := eval.Add(ct0, op1, ct0); err != nil {
+					return nil, fmt.Errorf("eval.Add: %w", err)
+				}
+
+				if err := est.Add(el0, el1, el0); err != nil {
+					return nil, fmt.Errorf("est.Add: %w", err)
+				}
+			}
+
+			return []Outcome{{values0, est.Decrypt(el0), ct0}}, nil
+		}, nil
+	}
+}
+
+func setupMulRelin(rescale bool) func(env *Env) (Trial, error) {
+	return func(env *Env) (Trial, error) {
+
+		evk := rlwe.NewMemEvaluationKeySet(env.KeyGenerator.GenRelinearizationKeyNew(env.SecretKey))
+		eval := ckks.NewEvaluator(env.Parameters, evk)
+		est := env.Estimator
+
+		mulCmplx := bignum.NewComplexMultiplier().Mul
+
+		return func() ([]Outcome, error) {
+
+			values0, el0, _, ct0 := env.NewTestVector(env.PublicKey)
+			values1, el1, _, ct1 := env.NewTestVector(env.PublicKey)
+
+			for j := range values0 {
+				mulCmplx(values0[j], values1[j], values0[j])
+			}
+
+			if err := eval.MulRelin(ct0, ct1, ct0); err != nil {
+				return nil, fmt.Errorf("eval.MulRelin: %w", err)
+			}
+
+			if err := est.MulRelin(el0, el1, el0); err != nil {
+				return nil, fmt.Errorf("est.MulRelin: %w", err)
+			}
+
+			if rescale {
+
+				if err := eval.Rescale(ct0, ct0); err != nil {
+					return nil, fmt.Errorf("eval.Rescale: %w", err)
+				}
+
+				if err := est.Rescale(el0, el0); err != nil {
+					return nil, fmt.Errorf("est.Rescale: %w", err)
+				}
+			}
+
+			return []Outcome{{values0, est.Decrypt(el0), ct0}}, nil
+		}, nil
+	}
+}
+
+// setupRotate returns the setup of the rotation by k slots, or of the conjugation if k = 0.
+func setupRotate(k int) func(env *Env) (Trial, error) {
+	return func(env *Env) (Trial, error) {
+
+		params := env.Parameters
+
+		galEl := params.GaloisElementForComplexConjugation()
+		if k != 0 {
+			galEl = params.GaloisElement(k)
+		}
+
+		evk := rlwe.NewMemEvaluationKeySet(nil, env.KeyGenerator.GenGaloisKeyNew(galEl, env.SecretKey))
+		eval := ckks.NewEvaluator(params, evk)
+		est := env.Estimator
+
+		return func() ([]Outcome, error) {
+
+			values, el, _, ct := env.NewTestVector(env.PublicKey)
+
+			var err error
+
+			if k == 0 {
+
+				for i := range values {
+					values[i][1].Neg(values[i][1])
+				}
+
+				if err = eval.Conjugate(ct, ct); err != nil {
+					return nil, fmt.Errorf("eval.Conjugate: %w", err)
+				}
+
+				if el, err = est.ConjugateNew(el); err != nil {
+					return nil, fmt.Errorf("est.ConjugateNew: %w", err)
+				}
+
+			} else {
+
+				utils.RotateSliceInPlace(values, k)
+
+				if err = eval.Rotate(ct, k, ct); err != nil {
+					return nil, fmt.Errorf("eval.Rotate: %w", err)
+				}
+
+				if el, err = est.RotateNew(el, k); err != nil {
+					return nil, fmt.Errorf("est.RotateNew: %w", err)
+				}
+			}
+
+			return []Outcome{{values, est.Decrypt(el), ct}}, nil
+		}, nil
+	}
+}
+
+// setupLinearTransformation returns the setup of a linear transformation with four random
+// diagonals, evaluated with the baby-step giant-step algorithm and followed by a rescaling.
+func setupLinearTransformation(env *Env) (Trial, error) {
+
+	params := env.Parameters
+	est := env.Estimator
+	slots := params.MaxSlots()
+
+	diags := lintrans.Diagonals[*bignum.Complex]{}
+	for _, k := range []int{0, 1, 2, slots - 1} {
+		diag := make([]*bignum.Complex, slots)
+		for i := range diag {
+			diag[i] = bignum.ToComplex(complex(env.Source.Float64(-1, 1), env.Source.Float64(-1, 1)), 128)
+		}
+		diags[k] = diag
+	}
+
+	ltParams := lintrans.Parameters{
+		DiagonalsIndexList:        diags.DiagonalsIndexList(),
+		LevelQ:                    params.MaxLevel(),
+		LevelP:                    params.MaxLevelP(),
+		Scale:                     rlwe.NewScale(params.Q()[params.MaxLevel()]),
+		LogDimensions:             params.LogMaxDimensions(),
+		LogBabyStepGiantStepRatio: 1,
+	}
+
+	lt := lintrans.NewTransformation(params, ltParams)
+	if err := lintrans.Encode(env.Encoder, diags, lt); err != nil {
+		return nil, fmt.Errorf("lintrans.Encode: %w", err)
+	}
+
+	evk := rlwe.NewMemEvaluationKeySet(nil, env.KeyGenerator.GenGaloisKeysNew(lt.GaloisElements(params), env.SecretKey)...)
+	eval := ckks.NewEvaluator(params, evk)
+	ltEval := lintrans.NewEvaluator(eval)
+
+	ltEst := estimator.LinearTransformation{
+		LogSlots:                 params.LogMaxSlots(),
+		LogBabyStepGianStepRatio: ltParams.LogBabyStepGiantStepRatio,
+		Scale:                    ltParams.Scale,
+		Value:                    diags,
+	}
+
+	mulCmplx := bignum.NewComplexMultiplier().Mul
+
+	add := func(a, b, c []*bignum.Complex) {
+		for i := range c {
+			if a[i] != nil && b[i] != nil {
+				c[i].Add(a[i], b[i])
+			}
+		}
+	}
+
+	muladd := func(a, b, c []*bignum.Complex) {
+		tmp := bignum.NewComplex().SetPrec(128)
+		for i := range c {
+			if a[i] != nil && b[i] != nil {
+				mulCmplx(a[i], b[i], tmp)
+				c[i].Add(c[i], tmp)
+			}
+		}
+	}
+
+	newVec := func(size int) (vec []*bignum.Complex) {
+		vec = make([]*bignum.Complex, size)
+		for i := range vec {
+			vec[i] = bignum.NewComplex().SetPrec(128)
+		}
+		return
+	}
+
+	return func() ([]Outcome, error) {
+
+		values, el, _, ct := env.NewTestVector(env.PublicKey)
+
+		values = diags.Evaluate(values, newVec, add, muladd)
+
+		ct, err := ltEval.EvaluateNew(ct, lt)
+		if err != nil {
+			return nil, fmt.Errorf("ltEval.EvaluateNew: %w", err)
+		}
+
+		if err = eval.Rescale(ct, ct); err != nil {
+			return nil, fmt.Errorf("eval.Rescale: %w", err)
+		}
+
+		if el, err = est.EvaluateLinearTransformationNew(el, ltEst); err != nil {
+			return nil, fmt.Errorf("est.EvaluateLinearTransformationNew: %w", err)
+		}
+
+		if err = est.Rescale(el, el); err != nil {
+			return nil, fmt.Errorf("est.Rescale: %w", err)
+		}
+
+		return []Outcome{{values, est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// setupPolynomial returns the setup of the evaluation of a degree 63
+// Chebyshev approximation of the sigmoid in [-8, 8].
+func setupPolynomial(env *Env) (Trial, error) {
+
+	params := env.Parameters
+	est := env.Estimator
+
+	evk := rlwe.NewMemEvaluationKeySet(env.KeyGenerator.GenRelinearizationKeyNew(env.SecretKey))
+	eval := ckks.NewEvaluator(params, evk)
+	polyEval := polynomial.NewEvaluator(params, eval)
+
+	sigmoid := func(x *big.Float) (y *big.Float) {
+		xF64, _ := x.Float64()
+		return new(big.Float).SetPrec(x.Prec()).SetFloat64(1 / (math.Exp(-xF64) + 1))
+	}
+
+	poly := polynomial.NewPolynomial(bignum.ChebyshevApproximation(sigmoid, bignum.Interval{
+		A:     *bignum.NewFloat(-8, 128),
+		B:     *bignum.NewFloat(8, 128),
+		Nodes: 64,
+	}))
+
+	scalar, constant := poly.ChangeOfBasis()
+
+	return func() ([]Outcome, error) {
+
+		values, el, _, ct := env.NewTestVector(env.PublicKey)
+
+		for i := range values {
+			values[i] = poly.Evaluate(values[i])
+		}
+
+		if err := est.Mul(el, scalar, el); err != nil {
+			return nil, fmt.Errorf("est.Mul: %w", err)
+		}
+
+		if err := est.Add(el, constant, el); err != nil {
+			return nil, fmt.Errorf("est.Add: %w", err)
+		}
+
+		if err := est.Rescale(el, el); err != nil {
+			return nil, fmt.Errorf("est.Rescale: %w", err)
+		}
+
+		if err := eval.Mul(ct, scalar, ct); err != nil {
+			return nil, fmt.Errorf("eval.Mul: %w", err)
+		}
+
+		if err := eval.Add(ct, constant, ct); err != nil {
+			return nil, fmt.Errorf("eval.Add: %w", err)
+		}
+
+		if err := eval.Rescale(ct, ct); err != nil {
+			return nil, fmt.Errorf("eval.Rescale: %w", err)
+		}
+
+		el, err := est.EvaluatePolynomialNew(el, poly, el.Scale)
+		if err != nil {
+			return nil, fmt.Errorf("est.EvaluatePolynomialNew: %w", err)
+		}
+
+		if ct, err = polyEval.Evaluate(ct, poly, ct.Scale); err != nil {
+			return nil, fmt.Errorf("polyEval.Evaluate: %w", err)
+		}
+
+		return []Outcome{{values, est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+// setupMod1 returns the setup of the homomorphic modular reduction of the bootstrapping
+// on inputs I * Q + m with I in [-K+1, K-1] and m in [-1, 1].
+func setupMod1(env *Env) (Trial, error) {
+
+	params := env.Parameters
+	est := env.Estimator
+
+	evk := rlwe.NewMemEvaluationKeySet(env.KeyGenerator.GenRelinearizationKeyNew(env.SecretKey))
+	eval := ckks.NewEvaluator(params, evk)
+
+	evm, err := mod1.NewParametersFromLiteral(params, mod1.ParametersLiteral{
+		LevelQ:          params.MaxLevel() - 1,
+		Mod1Type:        mod1.CosDiscrete,
+		LogMessageRatio: 8,
+		K:               12,
+		Mod1Degree:      30,
+		DoubleAngle:     3,
+		LogScale:        60,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("mod1.NewParametersFromLiteral: %w", err)
+	}
+
+	mod1Eval := mod1.NewEvaluator(eval, polynomial.NewEvaluator(params, eval), evm)
+
+	K := evm.K - 1
+	Q := evm.QDiff * evm.MessageRatio()
+
+	return func() ([]Outcome, error) {
+
+		values := make([]*bignum.Complex, params.MaxSlots())
+		for i := range values {
+			values[i] = bignum.ToComplex(math.Round(env.Source.Float64(-K, K))*Q+env.Source.Float64(-1, 1), 64)
+		}
+
+		el := est.NewElement(values, 1, est.MaxLevel(), est.DefaultScale())
+		est.AddEncodingNoise(el)
+		est.AddEncryptionNoisePk(el)
+
+		pt := ckks.NewPlaintext(params, params.MaxLevel())
+		if err := env.Encoder.Encode(values, pt); err != nil {
+			return nil, fmt.Errorf("ecd.Encode: %w", err)
+		}
+
+		ct, err := rlwe.NewEncryptor(params, env.PublicKey).EncryptNew(pt)
+		if err != nil {
+			return nil, fmt.Errorf("enc.EncryptNew: %w", err)
+		}
+
+		// Scales the message to Delta = Q/MessageRatio and then to Sine/MessageRatio
+		for _, target := range []rlwe.Scale{
+			rlwe.NewScale(math.Exp2(math.Round(math.Log2(float64(params.Q()[0]) / evm.MessageRatio())))),
+			evm.ScalingFactor().Div(rlwe.NewScale(evm.MessageRatio())),
+		} {
+
+			scale := rlwe.NewScale(math.Round(target.Div(ct.Scale).Float64()))
+
+			if err = eval.ScaleUp(ct, scale, ct); err != nil {
+				return nil, fmt.Errorf("eval.ScaleUp: %w", err)
+			}
+
+			if err = est.ScaleUp(el, scale); err != nil {
+				return nil, fmt.Errorf("est.ScaleUp: %w", err)
+			}
+		}
+
+		// Normalization
+		if err = eval.Mul(ct, 1/(evm.K*evm.QDiff), ct); err != nil {
+			return nil, fmt.Errorf("eval.Mul: %w", err)
+		}
+
+		if err = est.Mul(el, 1/(evm.K*evm.QDiff), el); err != nil {
+			return nil, fmt.Errorf("est.Mul: %w", err)
+		}
+
+		if err = eval.Rescale(ct, ct); err != nil {
+			return nil, fmt.Errorf("eval.Rescale: %w", err)
+		}
+
+		if err = est.Rescale(el, el); err != nil {
+			return nil, fmt.Errorf("est.Rescale: %w", err)
+		}
+
+		if ct, err = mod1Eval.EvaluateNew(ct); err != nil {
+			return nil, fmt.Errorf("mod1Eval.EvaluateNew: %w", err)
+		}
+
+		if el, err = est.EvaluateMod1New(el, evm); err != nil {
+			return nil, fmt.Errorf("est.EvaluateMod1New: %w", err)
+		}
+
+		ratio := new(big.Float).SetPrec(256).SetFloat64(evm.MessageRatio() * evm.QDiff / (2 * math.Pi))
+		for j := range values {
+			values[j][0].Quo(values[j][0], ratio)
+			values[j][0] = bignum.Sin(values[j][0])
+			values[j][0].Mul(values[j][0], ratio)
+		}
+
+		return []Outcome{{values, est.Decrypt(el), ct}}, nil
+	}, nil
+}
+
+func setupBootstrapping(env *Env) (Trial, error) {
+
+	params := env.Parameters
+
+	btpLit := *env.Bootstrapping
+	btpLit.LogN = utils.Pointy(params.LogN())
+
+	if btpLit.Xs == nil {
+		btpLit.Xs = params.Xs()
+	}
+
+	btpParams, err := bootstrapping.NewParametersFromLiteral(params, btpLit)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping.NewParametersFromLiteral: %w", err)
+	}
+
+	evk, _, err := btpParams.GenEvaluationKeys(env.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("btpParams.GenEvaluationKeys: %w", err)
+	}
+
+	eval, err := bootstrapping.NewEvaluator(btpParams, evk)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping.NewEvaluator: %w", err)
+	}
+
+	evalEst := bootEst.NewEvaluatorFromSeed(btpParams, *env.Estimator.SecretSeed)
+	evalEst.ResidualParameters.Rand = env.Source.Rand
+	evalEst.BootstrappingParameters.Rand = env.Source.Rand
+
+	est := evalEst.ResidualParameters
+
+	return func() ([]Outcome, error) {
+
+		values, el, _, ct := est.NewTestVectorFromSeed(env.Encoder, env.PublicKey, env.A, env.B, env.Source)
+
+		ct, err := eval.Bootstrap(ct)
+		if err != nil {
+			return nil, fmt.Errorf("eval.Bootstrap: %w", err)
+		}
+
+		if el, err = evalEst.Bootstrap(el); err != nil {
+			return nil, fmt.Errorf("evalEst.Bootstrap: %w", err)
+		}
+
+		return []Outcome{{values, est.Decrypt(el), ct}}, nil
+	}, nil
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"math"
+	"sort"
+)
+
+// VarianceRatio tests whether the samples a and b have the same variance. It returns the
+// difference log2(std(a)) - log2(std(b)) and the two-sided p-value of the test, which uses
+// the asymptotic normality of the log of the sample variances, corrected by their kurtosis
+// so that it does not assume normal samples.
+func VarianceRatio(a, b []float64) (log2StdRatio, p float64) {
+
+	va, ka := moments(a)
+	vb, kb := moments(b)
+
+	logRatio := math.Log(va / vb)
+
+	// var(log(s^2)) ~ (kurtosis - 1) / n
+	se := math.Sqrt((ka-1)/float64(len(a)) + (kb-1)/float64(len(b)))
+
+	return logRatio / (2 * math.Ln2), math.Erfc(math.Abs(logRatio) / (se * math.Sqrt2))
+}
+
+// KolmogorovSmirnov returns the statistic D = sup |Fa(x) - Fb(x)| of the two-sample
+// Kolmogorov-Smirnov test of the samples a and b and its asymptotic p-value.
+func KolmogorovSmirnov(a, b []float64) (d, p float64) {
+
+	a = append([]float64{}, a...)
+	b = append([]float64{}, b...)
+
+	sort.Float64s(a)
+	sort.Float64s(b)
+
+	na, nb := float64(len(a)), float64(len(b))
+
+	var i, j int
+	for i < len(a) && j < len(b) {
+
+		x := min(a[i], b[j])
+
+		for i < len(a) && a[i] == x {
+			i++
+		}
+
+		for j < len(b) && b[j] == x {
+			j++
+		}
+
+		d = max(d, math.Abs(float64(i)/na-float64(j)/nb))
+	}
+
+	n := math.Sqrt(na * nb / (na + nb))
+
+	return d, kolmogorovQ((n + 0.12 + 0.11/n) * d)
+}
+
+// kolmogorovQ returns the survival function Q(x) = 2 sum_{k>0} (-1)^(k-1) exp(-2 k^2 x^2)
+// of the Kolmogorov distribution.
+func kolmogorovQ(x float64) (q float64) {
+
+	if x < 0.2 {
+		return 1
+	}
+
+	sign := 2.0
+	for k := 1; k <= 100; k++ {
+
+		term := sign * math.Exp(-2*float64(k*k)*x*x)
+		q += term
+
+		if math.Abs(term) < 1e-16 {
+			break
+		}
+
+		sign = -sign
+	}
+
+	return min(max(q, 0), 1)
+}
+
+// moments returns the variance and the kurtosis of the samples.
+func moments(x []float64) (variance, kurtosis float64) {
+
+	n := float64(len(x))
+
+	var mean float64
+	for _, v := range x {
+		mean += v
+	}
+	mean /= n
+
+	var m2, m4 float64
+	for _, v := range x {
+		d := (v - mean) * (v - mean)
+		m2 += d
+		m4 += d * d
+	}
+
+	m2 /= n
+	m4 /= n
+
+	return m2 * n / (n - 1), m4 / (m2 * m2)
+}
+
+func std(x []float64) float64 {
+	variance, _ := moments(x)
+	return math.Sqrt(variance)
+}
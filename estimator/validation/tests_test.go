@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func normalSamples(r *rand.Rand, n int, mean, std float64) (x []float64) {
+	x = make([]float64, n)
+	for i := range x {
+		x[i] = mean + std*r.NormFloat64()
+	}
+	return
+}
+
+func TestVarianceRatio(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	n := 1 << 14
+
+	t.Run("Same", func(t *testing.T) {
+
+		log2StdRatio, p := VarianceRatio(normalSamples(r, n, 0, 3), normalSamples(r, n, 0, 3))
+
+		if math.Abs(log2StdRatio) > 0.05 || p < 1e-3 {
+			t.Fatalf("log2StdRatio=%f, p=%f", log2StdRatio, p)
+		}
+	})
+
+	t.Run("Scaled", func(t *testing.T) {
+
+		log2StdRatio, p := VarianceRatio(normalSamples(r, n, 0, 6), normalSamples(r, n, 0, 3))
+
+		if math.Abs(log2StdRatio-1) > 0.05 || p > 1e-9 {
+			t.Fatalf("log2StdRatio=%f, p=%g", log2StdRatio, p)
+		}
+	})
+}
+
+func TestKolmogorovSmirnov(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	n := 1 << 12
+
+	t.Run("Identical", func(t *testing.T) {
+
+		a := normalSamples(r, n, 0, 1)
+
+		if d, p := KolmogorovSmirnov(a, a); d != 0 || p < 0.99 {
+			t.Fatalf("D=%f, p=%f", d, p)
+		}
+	})
+
+	t.Run("Same", func(t *testing.T) {
+
+		d, p := KolmogorovSmirnov(normalSamples(r, n, 0, 1), normalSamples(r, n, 0, 1))
+
+		if d > 0.05 || p < 1e-3 {
+			t.Fatalf("D=%f, p=%f", d, p)
+		}
+	})
+
+	t.Run("Shifted", func(t *testing.T) {
+
+		d, p := KolmogorovSmirnov(normalSamples(r, n, 0.5, 1), normalSamples(r, n, 0, 1))
+
+		// sup |Phi(x - 0.5) - Phi(x)| = 2 Phi(0.25) - 1 ~ 0.197
+		if math.Abs(d-0.197) > 0.05 || p > 1e-9 {
+			t.Fatalf("D=%f, p=%g", d, p)
+		}
+	})
+}
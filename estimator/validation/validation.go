@@ -0,0 +1,289 @@
+// Package validation compares the error distributions predicted by the estimator with the ones
+// of Lattigo, primitive by primitive, over small parameter sets.
+//
+// Each Case evaluates a primitive on the same inputs with the estimator and with Lattigo.
+// The errors of the slots (real and imaginary parts) of both sides are compared with a
+// variance-ratio test and a two-sample Kolmogorov-Smirnov test. A case fails if one of the
+// tests rejects at the significance level Alpha and the effect (difference of the log2
+// standard deviations or KS statistic) exceeds its tolerance: with thousands of samples
+// per case, the tests alone would reject differences too small to matter.
+//
+// The program experiments/validation runs all the cases and exits with a non-zero
+// status if one of them fails.
+package validation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/ckks-noise-estimator"
+
+	"github.com/tuneinsight/lattigo/v6/circuits/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v6/core/rlwe"
+	"github.com/tuneinsight/lattigo/v6/schemes/ckks"
+	"github.com/tuneinsight/lattigo/v6/utils/bignum"
+)
+
+// Options are the options of the validation of a case.
+type Options struct {
+	// Trials is the number of trials of the case (default: 1).
+	Trials int
+
+	// Seed is the seed of the inputs and of the secrets and noise of the estimator.
+	Seed int64
+
+	// Alpha is the significance level of the tests (default: 1e-3).
+	Alpha float64
+
+	// Log2StdTolerance is the largest accepted difference between the log2
+	// standard deviations of the predicted and actual errors (default: 0.5).
+	Log2StdTolerance float64
+
+	// KSTolerance is the largest accepted Kolmogorov-Smirnov statistic (default: 0.1).
+	KSTolerance float64
+}
+
+// Env is the environment of the trials of a case.
+type Env struct {
+	Parameters    ckks.Parameters
+	Bootstrapping *bootstrapping.ParametersLiteral
+	Encoder       *ckks.Encoder
+	KeyGenerator  *rlwe.KeyGenerator
+	SecretKey     *rlwe.SecretKey
+	PublicKey     *rlwe.PublicKey
+	Decryptor     *rlwe.Decryptor
+	Estimator     estimator.Estimator
+	Source        estimator.TestRand
+
+	// A and B are the bounds of the inputs.
+	A, B complex128
+}
+
+// NewTestVector returns a new test vector with values sampled from the source,
+// encrypted with key (or only encoded if key is nil).
+func (env *Env) NewTestVector(key rlwe.EncryptionKey) (values []*bignum.Complex, el *estimator.Element, pt *rlwe.Plaintext, ct *rlwe.Ciphertext) {
+	return env.Estimator.NewTestVectorFromSeed(env.Encoder, key, env.A, env.B, env.Source)
+}
+
+// Outcome is an output of a trial: the expected values, the values predicted
+// by the estimator and the plaintext or ciphertext computed by Lattigo.
+type Outcome struct {
+	Want      []*bignum.Complex
+	Predicted []*bignum.Complex
+	Actual    interface{}
+}
+
+// Trial runs one trial of a case.
+type Trial func() ([]Outcome, error)
+
+// Case is a primitive evaluated with the estimator and with Lattigo.
+type Case struct {
+	Name string
+
+	// Parameters are the parameters of the case and Bootstrapping
+	// the parameters of the bootstrapping, if any.
+	Parameters    ckks.ParametersLiteral
+	Bootstrapping *bootstrapping.ParametersLiteral
+
+	// Min and Max are the bounds of the real and imaginary parts
+	// of the inputs (default: -1 and 1), which are real if Real is true.
+	Min, Max float64
+	Real     bool
+
+	// Setup returns the trial of the case.
+	Setup func(env *Env) (Trial, error)
+}
+
+// Report is the result of the validation of a case.
+type Report struct {
+	Case string
+
+	// Samples is the number of errors of each side.
+	Samples int
+
+	// Log2StdPredicted and Log2StdActual are the log2 standard
+	// deviations of the predicted and actual errors.
+	Log2StdPredicted, Log2StdActual float64
+
+	// VarianceP is the p-value of the variance-ratio test.
+	VarianceP float64
+
+	// KS and KSP are the statistic and the p-value of the Kolmogorov-Smirnov test.
+	KS, KSP float64
+
+	Pass bool
+}
+
+func (r Report) String() string {
+	status := "PASS"
+	if !r.Pass {
+		status = "FAIL"
+	}
+	return fmt.Sprintf("%s %-22s samples=%-6d log2(std) predicted=%7.2f actual=%7.2f (p=%.2g) KS=%.3f (p=%.2g)",
+		status, r.Case, r.Samples, r.Log2StdPredicted, r.Log2StdActual, r.VarianceP, r.KS, r.KSP)
+}
+
+// Run validates the case.
+func Run(c Case, opts Options) (r Report, err error) {
+
+	if opts.Trials < 1 {
+		opts.Trials = 1
+	}
+
+	if opts.Alpha == 0 {
+		opts.Alpha = 1e-3
+	}
+
+	if opts.Log2StdTolerance == 0 {
+		opts.Log2StdTolerance = 0.5
+	}
+
+	if opts.KSTolerance == 0 {
+		opts.KSTolerance = 0.1
+	}
+
+	params, err := ckks.NewParametersFromLiteral(c.Parameters)
+	if err != nil {
+		return r, fmt.Errorf("ckks.NewParametersFromLiteral: %w", err)
+	}
+
+	env := newEnv(params, c, opts)
+
+	trial, err := c.Setup(env)
+	if err != nil {
+		return r, fmt.Errorf("%s: setup: %w", c.Name, err)
+	}
+
+	var predicted, actual []float64
+
+	for i := 0; i < opts.Trials; i++ {
+
+		var outcomes []Outcome
+		if outcomes, err = trial(); err != nil {
+			return r, fmt.Errorf("%s: trial %d: %w", c.Name, i, err)
+		}
+
+		for _, o := range outcomes {
+
+			var have []*bignum.Complex
+			if have, err = env.decode(o.Actual); err != nil {
+				return r, fmt.Errorf("%s: trial %d: %w", c.Name, i, err)
+			}
+
+			predicted = env.appendErrors(predicted, o.Want, o.Predicted)
+			actual = env.appendErrors(actual, o.Want, have)
+		}
+	}
+
+	r.Case = c.Name
+	r.Samples = len(predicted)
+
+	var ratio float64
+	ratio, r.VarianceP = VarianceRatio(predicted, actual)
+	r.Log2StdActual = math.Log2(std(actual))
+	r.Log2StdPredicted = r.Log2StdActual + ratio
+
+	r.KS, r.KSP = KolmogorovSmirnov(predicted, actual)
+
+	r.Pass = !(r.VarianceP < opts.Alpha && math.Abs(ratio) > opts.Log2StdTolerance) &&
+		!(r.KSP < opts.Alpha && r.KS > opts.KSTolerance)
+
+	return
+}
+
+// RunAll validates the cases and returns their reports.
+func RunAll(cases []Case, opts Options) (reports []Report, err error) {
+
+	for _, c := range cases {
+
+		var r Report
+		if r, err = Run(c, opts); err != nil {
+			return
+		}
+
+		reports = append(reports, r)
+	}
+
+	return
+}
+
+func newEnv(params ckks.Parameters, c Case, opts Options) *Env {
+
+	source := estimator.NewTestRand(opts.Seed)
+
+	est := estimator.NewEstimatorFromSeed(params, opts.Seed)
+	est.Rand = source.Rand
+
+	kgen := rlwe.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPairNew()
+
+	lo, hi := c.Min, c.Max
+	if lo == 0 && hi == 0 {
+		lo, hi = -1, 1
+	}
+
+	a, b := complex(lo, lo), complex(hi, hi)
+	if c.Real {
+		a, b = complex(lo, 0), complex(hi, 0)
+	}
+
+	return &Env{
+		Parameters:    params,
+		Bootstrapping: c.Bootstrapping,
+		Encoder:       ckks.NewEncoder(params),
+		KeyGenerator:  kgen,
+		SecretKey:     sk,
+		PublicKey:     pk,
+		Decryptor:     rlwe.NewDecryptor(params, sk),
+		Estimator:     est,
+		Source:        source,
+		A:             a,
+		B:             b,
+	}
+}
+
+// decode returns the values of the plaintext or ciphertext computed by Lattigo.
+func (env *Env) decode(actual interface{}) (values []*bignum.Complex, err error) {
+
+	var pt *rlwe.Plaintext
+
+	switch actual := actual.(type) {
+	case *rlwe.Plaintext:
+		pt = actual
+	case *rlwe.Ciphertext:
+		pt = env.Decryptor.DecryptNew(actual)
+	case []*bignum.Complex:
+		return actual, nil
+	default:
+		return nil, fmt.Errorf("invalid actual.(type): %T", actual)
+	}
+
+	values = make([]*bignum.Complex, pt.Slots())
+	if err = env.Encoder.Decode(pt, values); err != nil {
+		return nil, fmt.Errorf("ecd.Decode: %w", err)
+	}
+
+	return
+}
+
+// appendErrors appends the real and imaginary parts of have - want to errs.
+// The imaginary parts are omitted for ring.ConjugateInvariant.
+func (env *Env) appendErrors(errs []float64, want, have []*bignum.Complex) []float64 {
+
+	diff := bignum.NewComplex()
+
+	for i := range want {
+
+		diff.Sub(have[i], want[i])
+
+		re, _ := diff[0].Float64()
+		errs = append(errs, re)
+
+		if !env.Estimator.IsConjugateInvariant() {
+			im, _ := diff[1].Float64()
+			errs = append(errs, im)
+		}
+	}
+
+	return errs
+}
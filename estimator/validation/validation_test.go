@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"testing"
+)
+
+func TestCases(t *testing.T) {
+	for _, c := range Cases {
+		t.Run(c.Name, func(t *testing.T) {
+
+			if testing.Short() && c.Bootstrapping != nil {
+				t.Skip("skipping the bootstrapping in short mode")
+			}
+
+			r, err := Run(c, Options{Seed: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !r.Pass {
+				t.Error(r.String())
+			}
+		})
+	}
+}